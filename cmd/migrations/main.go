@@ -2,7 +2,6 @@ package main
 
 import (
 	"flea-market/infra"
-	"flea-market/models"
 	"log"
 )
 
@@ -10,7 +9,7 @@ func main() {
 	infra.Initializer()
 	db := infra.SetupDB()
 
-	if err := db.AutoMigrate(&models.User{}, &models.Item{}); err != nil {
-		log.Fatalln("Failed to migrate database")
+	if err := infra.Migrate(db); err != nil {
+		log.Fatalf("Failed to migrate database: %v", err)
 	}
 }
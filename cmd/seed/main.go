@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"flea-market/infra"
+	"flea-market/internal/seed"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"time"
+)
+
+// Usage: go run cmd/seed/main.go [-count N] [-rand-seed N] [fixture files...]
+// Without files and -count, fixtures/dev.yaml is loaded.
+func main() {
+	count := flag.Int("count", 0, "number of random listings to generate")
+	randSeed := flag.Uint64("rand-seed", uint64(time.Now().UnixNano()), "seed of the random listings, to generate the same ones again")
+	flag.Parse()
+
+	files := flag.Args()
+	if len(files) == 0 && *count == 0 {
+		files = []string{"fixtures/dev.yaml"}
+	}
+
+	infra.Initializer()
+	db := infra.SetupDB()
+	seeder := seed.NewSeeder(db)
+	ctx := context.Background()
+
+	if len(files) > 0 {
+		fixtures, err := seed.ReadFiles(files...)
+		if err != nil {
+			log.Fatalf("Failed to read fixtures: %v", err)
+		}
+		if _, err := seeder.Load(ctx, *fixtures); err != nil {
+			log.Fatalf("Failed to load fixtures: %v", err)
+		}
+		fmt.Printf("Loaded %d users, %d items and %d offers from %v\n", len(fixtures.Users), len(fixtures.Items), len(fixtures.Offers), files)
+	}
+
+	if *count > 0 {
+		if err := seeder.Generate(ctx, *count, rand.New(rand.NewPCG(*randSeed, 0))); err != nil {
+			log.Fatalf("Failed to generate listings: %v", err)
+		}
+		fmt.Printf("Generated %d listings with -rand-seed %d\n", *count, *randSeed)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"flea-market/infra"
+	"flea-market/models"
+	"fmt"
+	"log"
+
+	"gorm.io/gorm"
+)
+
+type categorySeed struct {
+	Name     string
+	Slug     string
+	Children []categorySeed
+}
+
+var categoryTree = []categorySeed{
+	{Name: "ファッション", Slug: "fashion", Children: []categorySeed{
+		{Name: "レディース", Slug: "fashion-women"},
+		{Name: "メンズ", Slug: "fashion-men"},
+		{Name: "キッズ", Slug: "fashion-kids"},
+	}},
+	{Name: "家電・スマホ・カメラ", Slug: "electronics", Children: []categorySeed{
+		{Name: "スマートフォン", Slug: "electronics-smartphones"},
+		{Name: "PC・タブレット", Slug: "electronics-computers"},
+		{Name: "カメラ", Slug: "electronics-cameras"},
+	}},
+	{Name: "本・音楽・ゲーム", Slug: "media", Children: []categorySeed{
+		{Name: "本", Slug: "media-books"},
+		{Name: "CD・DVD", Slug: "media-music"},
+		{Name: "ゲーム", Slug: "media-games"},
+	}},
+	{Name: "インテリア・住まい", Slug: "home", Children: []categorySeed{
+		{Name: "家具", Slug: "home-furniture"},
+		{Name: "キッチン", Slug: "home-kitchen"},
+	}},
+	{Name: "その他", Slug: "others"},
+}
+
+// Categories are matched by slug, so running this twice doesn't create duplicates.
+func main() {
+	infra.Initializer()
+	db := infra.SetupDB()
+
+	if err := db.Transaction(func(tx *gorm.DB) error {
+		return seed(tx, categoryTree, nil)
+	}); err != nil {
+		log.Fatalf("Failed to seed categories: %v", err)
+	}
+	fmt.Println("Seeding categories done")
+}
+
+func seed(tx *gorm.DB, seeds []categorySeed, parentId *uint) error {
+	for _, s := range seeds {
+		category := models.Category{Slug: s.Slug}
+		if err := tx.Where(models.Category{Slug: s.Slug}).
+			Assign(models.Category{Name: s.Name, ParentID: parentId}).
+			FirstOrCreate(&category).Error; err != nil {
+			return fmt.Errorf("category %s: %w", s.Slug, err)
+		}
+		if err := seed(tx, s.Children, &category.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package main
+
+import (
+	"flea-market/internal/app"
+	"log"
+)
+
+// Runs the job workers without the API. The number of workers is set by JOB_WORKERS.
+func main() {
+	worker := app.NewWorker()
+	if err := worker.Run(); err != nil {
+		log.Fatalf("Running job workers failed: %v", err)
+	}
+}
@@ -32,7 +32,7 @@ func (c *APICallController) GetAllPosts(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"data": data})
+	respondVersionedList(ctx, http.StatusOK, gin.H{"data": data}, *data)
 
 }
 
@@ -51,6 +51,6 @@ func (c *APICallController) GetUserAndPosts(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"data": data})
+	respondVersioned(ctx, http.StatusOK, gin.H{"data": data}, data)
 
 }
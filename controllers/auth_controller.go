@@ -52,7 +52,7 @@ func (c *AuthController) Login(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"token": token})
+	respondVersioned(ctx, http.StatusOK, gin.H{"token": token}, dto.TokenResponse{Token: *token})
 }
 
 func NewAuthController(service IAuthService) *AuthController {
@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ICategoryService interface {
+	FindTree(ctx context.Context) (*[]dto.CategoryTreeNode, error)
+}
+
+type CategoryController struct {
+	service ICategoryService
+}
+
+func NewCategoryController(service ICategoryService) *CategoryController {
+	return &CategoryController{service: service}
+}
+
+func (c *CategoryController) FindTree(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	tree, err := c.service.FindTree(reqCtx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondVersionedList(ctx, http.StatusOK, gin.H{"data": tree}, *tree)
+}
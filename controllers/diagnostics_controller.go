@@ -0,0 +1,33 @@
+package controllers
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IDiagnosticsService interface {
+	DBPoolStats(ctx context.Context) ([]dto.DBPoolStatsResponse, error)
+}
+
+type DiagnosticsController struct {
+	service IDiagnosticsService
+}
+
+func NewDiagnosticsController(service IDiagnosticsService) *DiagnosticsController {
+	return &DiagnosticsController{service: service}
+}
+
+func (c *DiagnosticsController) DBPoolStats(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	stats, err := c.service.DBPoolStats(reqCtx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondList(ctx, http.StatusOK, stats)
+}
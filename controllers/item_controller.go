@@ -6,6 +6,7 @@ import (
 	"flea-market/dto"
 	"flea-market/models"
 	"flea-market/utils"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -13,11 +14,17 @@ import (
 )
 
 type IItemService interface {
-	FindAll(ctx context.Context) (*[]models.Item, error)
+	FindAll(ctx context.Context, query dto.ItemQuery) (*[]models.Item, error)
 	FindById(ctx context.Context, itemId uint, userId uint) (*models.Item, error)
+	Search(ctx context.Context, query dto.ItemSearchQuery) (*[]models.ItemSearchResult, error)
 	Create(ctx context.Context, createItemInput dto.CreateItemInput, userId uint) (*models.Item, error)
 	Update(ctx context.Context, itemId uint, updateItemInput dto.UpdateItemInput, userId uint) (*models.Item, error)
 	Delete(ctx context.Context, itemId uint, userId uint) error
+	CreateBulk(ctx context.Context, inputs []dto.CreateItemInput, userId uint, atomic bool) []models.ItemBulkResult
+	UpdateBulk(ctx context.Context, entries []dto.BulkUpdateItemEntry, userId uint, atomic bool) []models.ItemBulkResult
+	DeleteBulk(ctx context.Context, itemIds []uint, userId uint, atomic bool) []models.ItemBulkResult
+	Export(ctx context.Context, userId uint, fn func(items []models.Item) error) error
+	ImportCSV(ctx context.Context, r io.Reader, userId uint, dryRun bool) (*dto.ItemImportReport, error)
 }
 
 type ItemController struct {
@@ -30,13 +37,38 @@ func NewItemController(service IItemService) *ItemController {
 
 func (c *ItemController) FindAll(ctx *gin.Context) {
 	reqCtx := utils.GinToGoContext(ctx)
-	items, err := c.service.FindAll(reqCtx)
+
+	var query dto.ItemQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Query is invalid", err))
+		return
+	}
+
+	items, err := c.service.FindAll(reqCtx, query)
 	if err != nil {
 		_ = ctx.Error(err)
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"data": items})
+	respondVersionedList(ctx, http.StatusOK, gin.H{"data": dto.NewLegacyItemResponses(*items)}, dto.NewItemResponses(*items))
+}
+
+func (c *ItemController) Search(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+
+	var query dto.ItemSearchQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Query is invalid", err))
+		return
+	}
+
+	results, err := c.service.Search(reqCtx, query)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondVersionedList(ctx, http.StatusOK, gin.H{"data": dto.NewLegacyItemSearchResultResponses(*results)}, dto.NewItemSearchResultResponses(*results))
 }
 
 func (c *ItemController) FindById(ctx *gin.Context) {
@@ -59,7 +91,7 @@ func (c *ItemController) FindById(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"data": item})
+	respondVersioned(ctx, http.StatusOK, gin.H{"data": dto.NewLegacyItemResponse(*item)}, dto.NewItemResponse(*item))
 }
 
 func (c *ItemController) Create(ctx *gin.Context) {
@@ -82,7 +114,7 @@ func (c *ItemController) Create(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusCreated, gin.H{"data": newItem})
+	respondVersioned(ctx, http.StatusCreated, gin.H{"data": dto.NewLegacyItemResponse(*newItem)}, dto.NewItemResponse(*newItem))
 
 }
 
@@ -112,7 +144,7 @@ func (c *ItemController) Update(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{"data": updatedItem})
+	respondVersioned(ctx, http.StatusOK, gin.H{"data": dto.NewLegacyItemResponse(*updatedItem)}, dto.NewItemResponse(*updatedItem))
 
 }
 
@@ -153,3 +185,88 @@ func getUserId(ctx *gin.Context) (*uint, error) {
 
 	return &userId, nil
 }
+
+func (c *ItemController) CreateBulk(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	var query dto.BulkQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Query is invalid", err))
+		return
+	}
+	var input dto.BulkCreateItemsInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Input data is invalid", err))
+		return
+	}
+
+	results := c.service.CreateBulk(reqCtx, input.Items, *userId, query.Atomic)
+	respondBulk(ctx, results, http.StatusCreated)
+}
+
+func (c *ItemController) UpdateBulk(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	var query dto.BulkQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Query is invalid", err))
+		return
+	}
+	var input dto.BulkUpdateItemsInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Input data is invalid", err))
+		return
+	}
+
+	results := c.service.UpdateBulk(reqCtx, input.Items, *userId, query.Atomic)
+	respondBulk(ctx, results, http.StatusOK)
+}
+
+func (c *ItemController) DeleteBulk(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	var query dto.BulkQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Query is invalid", err))
+		return
+	}
+	var input dto.BulkDeleteItemsInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Input data is invalid", err))
+		return
+	}
+
+	results := c.service.DeleteBulk(reqCtx, input.IDs, *userId, query.Atomic)
+	respondBulk(ctx, results, http.StatusOK)
+}
+
+// respondBulk responds 207 Multi-Status. Failed entries are logged here, because they don't go through APIErrorHandler.
+func respondBulk(ctx *gin.Context, results []models.ItemBulkResult, successStatus int) {
+	ip, reqID, methodPath := utils.GetGinLogContext(ctx)
+	for _, result := range results {
+		if result.Err == nil {
+			continue
+		}
+		if apiErr, ok := result.Err.(*utils.APIError); ok {
+			utils.Logger(apiErr.MessageCode, methodPath, reqID, ip, result.Err.Error())
+		} else {
+			utils.Logger(utils.UnknownError, methodPath, reqID, ip, result.Err.Error())
+		}
+	}
+	respondList(ctx, http.StatusMultiStatus, dto.NewBulkItemResultResponses(results, successStatus))
+}
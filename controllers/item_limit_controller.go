@@ -0,0 +1,100 @@
+package controllers
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IItemLimitService interface {
+	Status(ctx context.Context, userId uint) (*models.ItemLimitStatus, error)
+	UpdateOverride(ctx context.Context, userId uint, input dto.UpdateUserLimitsInput) (*models.ItemLimitStatus, error)
+	DeleteOverride(ctx context.Context, userId uint) error
+}
+
+type ItemLimitController struct {
+	service IItemLimitService
+}
+
+func NewItemLimitController(service IItemLimitService) *ItemLimitController {
+	return &ItemLimitController{service: service}
+}
+
+func (c *ItemLimitController) FindMine(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	status, err := c.service.Status(reqCtx, *userId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondData(ctx, http.StatusOK, dto.NewItemLimitStatusResponse(*status))
+}
+
+// FindByUser, Update and Delete are for admins.
+func (c *ItemLimitController) FindByUser(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	status, err := c.service.Status(reqCtx, uint(userId))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondData(ctx, http.StatusOK, dto.NewItemLimitStatusResponse(*status))
+}
+
+func (c *ItemLimitController) Update(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	var input dto.UpdateUserLimitsInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Input data is invalid", err))
+		return
+	}
+
+	status, err := c.service.UpdateOverride(reqCtx, uint(userId), input)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondData(ctx, http.StatusOK, dto.NewItemLimitStatusResponse(*status))
+}
+
+func (c *ItemLimitController) Delete(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	if err := c.service.DeleteOverride(reqCtx, uint(userId)); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flea-market/dto"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// An upload larger than this is rejected before it's parsed.
+const maxImportFileSize = 1 << 20
+
+// Export streams the user's items batch by batch.
+// Once the first batch is written, an error can't be responded anymore, so it's only logged and the response is cut short.
+func (c *ItemController) Export(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	var query dto.ItemExportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Query is invalid", err))
+		return
+	}
+
+	var writer itemExportWriter
+	if query.Format == "jsonl" {
+		writer = &jsonlItemWriter{encoder: json.NewEncoder(ctx.Writer)}
+	} else {
+		writer = &csvItemWriter{writer: csv.NewWriter(ctx.Writer)}
+	}
+
+	started := false
+	start := func() error {
+		started = true
+		ctx.Header("Content-Type", writer.contentType())
+		ctx.Header("Content-Disposition", `attachment; filename="items.`+writer.extension()+`"`)
+		ctx.Status(http.StatusOK)
+		return writer.begin()
+	}
+
+	err = c.service.Export(reqCtx, *userId, func(items []models.Item) error {
+		if !started {
+			if err := start(); err != nil {
+				return err
+			}
+		}
+		for _, item := range items {
+			if err := writer.write(item); err != nil {
+				return err
+			}
+		}
+		if err := writer.flush(); err != nil {
+			return err
+		}
+		ctx.Writer.Flush()
+		return nil
+	})
+	if err == nil && !started {
+		// the user has no items
+		err = start()
+		if err == nil {
+			err = writer.flush()
+		}
+	}
+	if err != nil {
+		if !started {
+			_ = ctx.Error(err)
+			return
+		}
+		ip, reqID, methodPath := utils.GetGinLogContext(ctx)
+		utils.Logger(utils.ItemExportAborted, methodPath, reqID, ip, *userId, err.Error())
+	}
+}
+
+// Import creates items from an uploaded CSV file. Nothing is created when any row is invalid.
+// It responds 201 when the items are created, 200 for a dry run without errors and 422 with the errors otherwise.
+func (c *ItemController) Import(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	var query dto.ItemImportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Query is invalid", err))
+		return
+	}
+
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxImportFileSize)
+	file, _, err := ctx.Request.FormFile("file")
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get CSV file from form field \"file\"", err))
+		return
+	}
+	defer file.Close()
+
+	report, err := c.service.ImportCSV(reqCtx, file, *userId, query.DryRun)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	status := http.StatusCreated
+	switch {
+	case len(report.Errors) > 0:
+		status = http.StatusUnprocessableEntity
+	case report.DryRun:
+		status = http.StatusOK
+	}
+
+	if query.Report == "csv" {
+		ctx.Header("Content-Disposition", `attachment; filename="import-errors.csv"`)
+		ctx.Data(status, "text/csv; charset=utf-8", importErrorsCSV(report.Errors))
+		return
+	}
+	respondData(ctx, status, report)
+}
+
+func importErrorsCSV(errors []dto.ItemImportError) []byte {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	_ = writer.Write([]string{"row", "field", "reason"})
+	for _, e := range errors {
+		_ = writer.Write([]string{strconv.Itoa(e.Row), e.Field, e.Reason})
+	}
+	writer.Flush()
+	return buf.Bytes()
+}
+
+type itemExportWriter interface {
+	contentType() string
+	extension() string
+	begin() error
+	write(item models.Item) error
+	flush() error
+}
+
+type csvItemWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvItemWriter) contentType() string { return "text/csv; charset=utf-8" }
+func (w *csvItemWriter) extension() string   { return "csv" }
+func (w *csvItemWriter) begin() error        { return w.writer.Write(dto.ItemExportColumns) }
+func (w *csvItemWriter) write(item models.Item) error {
+	return w.writer.Write(dto.NewItemCSVRecord(item))
+}
+func (w *csvItemWriter) flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// one dto.ItemResponse per line
+type jsonlItemWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *jsonlItemWriter) contentType() string { return "application/x-ndjson" }
+func (w *jsonlItemWriter) extension() string   { return "jsonl" }
+func (w *jsonlItemWriter) begin() error        { return nil }
+func (w *jsonlItemWriter) write(item models.Item) error {
+	return w.encoder.Encode(dto.NewItemResponse(item))
+}
+func (w *jsonlItemWriter) flush() error { return nil }
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IModerationService interface {
+	Report(ctx context.Context, itemId uint, reporterId uint, input dto.ReportItemInput) (*models.ItemReport, error)
+	FindQueue(ctx context.Context, query dto.ModerationQueueQuery) (*[]models.ModerationQueueEntry, error)
+	Decide(ctx context.Context, itemId uint, input dto.ModerationDecisionInput) (*models.Item, error)
+}
+
+type ModerationController struct {
+	service IModerationService
+}
+
+func NewModerationController(service IModerationService) *ModerationController {
+	return &ModerationController{service: service}
+}
+
+func (c *ModerationController) Report(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	itemId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	var input dto.ReportItemInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Input data is invalid", err))
+		return
+	}
+
+	report, err := c.service.Report(reqCtx, uint(itemId), *userId, input)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondData(ctx, http.StatusCreated, dto.NewItemReportResponse(*report))
+}
+
+// FindQueue and Decide are for admins.
+func (c *ModerationController) FindQueue(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+
+	var query dto.ModerationQueueQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Query is invalid", err))
+		return
+	}
+
+	entries, err := c.service.FindQueue(reqCtx, query)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondList(ctx, http.StatusOK, dto.NewModerationQueueResponses(*entries))
+}
+
+func (c *ModerationController) Decide(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+
+	itemId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	var input dto.ModerationDecisionInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Input data is invalid", err))
+		return
+	}
+
+	item, err := c.service.Decide(reqCtx, uint(itemId), input)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondData(ctx, http.StatusOK, dto.NewItemResponse(*item))
+}
@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"context"
+	"flea-market/models"
+	"flea-market/utils"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-contrib/sse"
+	"github.com/gin-gonic/gin"
+)
+
+type INotificationService interface {
+	Subscribe(userId uint) (<-chan models.Notification, func())
+	FindMissed(ctx context.Context, userId uint, lastEventId uint) (*[]models.Notification, error)
+}
+
+// Proxies and load balancers tend to close idle connections, so send a comment line periodically.
+const defaultHeartbeatInterval = 15 * time.Second
+
+type NotificationController struct {
+	service           INotificationService
+	heartbeatInterval time.Duration
+}
+
+func NewNotificationController(service INotificationService) *NotificationController {
+	return &NotificationController{service: service, heartbeatInterval: defaultHeartbeatInterval}
+}
+
+func (c *NotificationController) Stream(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	// EventSource sends Last-Event-ID automatically when it reconnects.
+	var lastEventId uint
+	if header := ctx.GetHeader("Last-Event-ID"); header != "" {
+		id, err := strconv.ParseUint(header, 10, 64)
+		if err != nil {
+			_ = ctx.Error(utils.NewBadRequestError("Last-Event-ID is invalid", err))
+			return
+		}
+		lastEventId = uint(id)
+	}
+
+	// Subscribe before reading missed notifications, otherwise notifications created in between are lost.
+	// Duplicates are skipped by comparing ids instead.
+	events, unsubscribe := c.service.Subscribe(*userId)
+	defer unsubscribe()
+
+	missed, err := c.service.FindMissed(reqCtx, *userId, lastEventId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ip, reqID, methodPath := utils.GetGinLogContext(ctx)
+	utils.Logger(utils.NotificationStreamStart, methodPath, reqID, ip, *userId)
+
+	ctx.Header("Content-Type", "text/event-stream")
+	ctx.Header("Cache-Control", "no-cache")
+	ctx.Header("Connection", "keep-alive")
+	// nginx buffers responses by default
+	ctx.Header("X-Accel-Buffering", "no")
+	ctx.Status(http.StatusOK)
+
+	for _, notification := range *missed {
+		if err := writeNotification(ctx.Writer, notification); err != nil {
+			utils.Logger(utils.NotificationStreamEnd, methodPath, reqID, ip, *userId, err.Error())
+			return
+		}
+		lastEventId = notification.ID
+	}
+	ctx.Writer.Flush()
+
+	heartbeat := time.NewTicker(c.heartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Request.Context().Done():
+			utils.Logger(utils.NotificationStreamEnd, methodPath, reqID, ip, *userId, "client disconnected")
+			return
+		case notification, ok := <-events:
+			// closed by the hub, when the server is shutting down or the client is too slow.
+			if !ok {
+				utils.Logger(utils.NotificationStreamEnd, methodPath, reqID, ip, *userId, "closed by server")
+				return
+			}
+			if notification.ID <= lastEventId {
+				continue
+			}
+			if err := writeNotification(ctx.Writer, notification); err != nil {
+				utils.Logger(utils.NotificationStreamEnd, methodPath, reqID, ip, *userId, err.Error())
+				return
+			}
+			lastEventId = notification.ID
+			ctx.Writer.Flush()
+		case <-heartbeat.C:
+			if _, err := io.WriteString(ctx.Writer, ": heartbeat\n\n"); err != nil {
+				utils.Logger(utils.NotificationStreamEnd, methodPath, reqID, ip, *userId, err.Error())
+				return
+			}
+			ctx.Writer.Flush()
+		}
+	}
+}
+
+func writeNotification(w io.Writer, notification models.Notification) error {
+	return sse.Encode(w, sse.Event{
+		Id:    strconv.FormatUint(uint64(notification.ID), 10),
+		Event: string(notification.Type),
+		Data:  notification.Payload,
+	})
+}
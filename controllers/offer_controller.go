@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IOfferService interface {
+	Create(ctx context.Context, itemId uint, input dto.CreateOfferInput, buyerId uint) (*models.Offer, error)
+	FindByItem(ctx context.Context, itemId uint, userId uint) (*[]models.Offer, error)
+	Accept(ctx context.Context, offerId uint, userId uint) (*models.Offer, error)
+	Decline(ctx context.Context, offerId uint, userId uint) (*models.Offer, error)
+	Counter(ctx context.Context, offerId uint, input dto.CounterOfferInput, userId uint) (*models.Offer, error)
+	Withdraw(ctx context.Context, offerId uint, userId uint) (*models.Offer, error)
+}
+
+type OfferController struct {
+	service IOfferService
+}
+
+func NewOfferController(service IOfferService) *OfferController {
+	return &OfferController{service: service}
+}
+
+func (c *OfferController) Create(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	itemId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	var input dto.CreateOfferInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Input data is invalid", err))
+		return
+	}
+
+	offer, err := c.service.Create(reqCtx, uint(itemId), input, *userId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondVersioned(ctx, http.StatusCreated, gin.H{"data": dto.NewLegacyOfferResponse(*offer)}, dto.NewOfferResponse(*offer))
+}
+
+func (c *OfferController) FindByItem(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	itemId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	offers, err := c.service.FindByItem(reqCtx, uint(itemId), *userId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondVersionedList(ctx, http.StatusOK, gin.H{"data": dto.NewLegacyOfferResponses(*offers)}, dto.NewOfferResponses(*offers))
+}
+
+func (c *OfferController) Accept(ctx *gin.Context) {
+	c.respond(ctx, c.service.Accept)
+}
+
+func (c *OfferController) Decline(ctx *gin.Context) {
+	c.respond(ctx, c.service.Decline)
+}
+
+func (c *OfferController) Withdraw(ctx *gin.Context) {
+	c.respond(ctx, c.service.Withdraw)
+}
+
+func (c *OfferController) Counter(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	offerId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	var input dto.CounterOfferInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Input data is invalid", err))
+		return
+	}
+
+	counterOffer, err := c.service.Counter(reqCtx, uint(offerId), input, *userId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondVersioned(ctx, http.StatusCreated, gin.H{"data": dto.NewLegacyOfferResponse(*counterOffer)}, dto.NewOfferResponse(*counterOffer))
+}
+
+// respond handles status changes which need no request body.
+func (c *OfferController) respond(ctx *gin.Context, action func(ctx context.Context, offerId uint, userId uint) (*models.Offer, error)) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	offerId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	offer, err := action(reqCtx, uint(offerId), *userId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondVersioned(ctx, http.StatusOK, gin.H{"data": dto.NewLegacyOfferResponse(*offer)}, dto.NewOfferResponse(*offer))
+}
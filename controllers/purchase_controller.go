@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IPurchaseService interface {
+	Purchase(ctx context.Context, itemId uint, buyerId uint) (*models.Purchase, error)
+}
+
+type PurchaseController struct {
+	service IPurchaseService
+}
+
+func NewPurchaseController(service IPurchaseService) *PurchaseController {
+	return &PurchaseController{service: service}
+}
+
+func (c *PurchaseController) Purchase(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	itemId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	purchase, err := c.service.Purchase(reqCtx, uint(itemId), *userId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondVersioned(ctx, http.StatusCreated, gin.H{"data": dto.NewLegacyPurchaseResponse(*purchase)}, dto.NewPurchaseResponse(*purchase))
+}
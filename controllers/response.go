@@ -0,0 +1,60 @@
+package controllers
+
+import (
+	"flea-market/dto"
+	"flea-market/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// v2Key is set by V2, which wraps the handlers of the v2 route overrides.
+const v2Key = "v2"
+
+// V2 makes handler respond in the {data, meta} envelope. It's for the v2 route overrides of the routes
+// which responded with the models before the envelope, so that v1 and the legacy aliases keep their bodies.
+func V2(handler gin.HandlerFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(v2Key, true)
+		handler(ctx)
+	}
+}
+
+// respondVersioned writes data in the envelope in v2, and v1Body, the body of v1 as it was, otherwise.
+// Neither must be a model. v1Body has the legacy DTOs, so that the columns added since v2 aren't sent to v1.
+func respondVersioned(ctx *gin.Context, status int, v1Body any, data any) {
+	if ctx.GetBool(v2Key) {
+		respondData(ctx, status, data)
+		return
+	}
+	ctx.JSON(status, v1Body)
+}
+
+func respondVersionedList[T any](ctx *gin.Context, status int, v1Body any, list []T) {
+	if ctx.GetBool(v2Key) {
+		respondList(ctx, status, list)
+		return
+	}
+	ctx.JSON(status, v1Body)
+}
+
+// respondData writes data in the {data, meta} envelope. data must be a response DTO, not a model.
+func respondData(ctx *gin.Context, status int, data any) {
+	ctx.JSON(status, dto.Response{Data: data, Meta: newMeta(ctx)})
+}
+
+func respondList[T any](ctx *gin.Context, status int, list []T) {
+	if list == nil {
+		list = []T{}
+	}
+	meta := newMeta(ctx)
+	count := len(list)
+	meta.Count = &count
+	ctx.JSON(status, dto.Response{Data: list, Meta: meta})
+}
+
+func newMeta(ctx *gin.Context) dto.Meta {
+	// set by LoggerMiddleware, so that clients can tell the id when they report a problem
+	reqID, _ := utils.GetGinContext(ctx, utils.ContextReqID)
+	id, _ := reqID.(string)
+	return dto.Meta{RequestID: id}
+}
@@ -0,0 +1,102 @@
+package controllers
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IReviewService interface {
+	Create(ctx context.Context, purchaseId uint, input dto.CreateReviewInput, userId uint) (*models.Review, error)
+	Update(ctx context.Context, reviewId uint, input dto.UpdateReviewInput, userId uint) (*models.Review, error)
+	FindByReviewee(ctx context.Context, userId uint) (*[]models.Review, error)
+}
+
+type ReviewController struct {
+	service IReviewService
+}
+
+func NewReviewController(service IReviewService) *ReviewController {
+	return &ReviewController{service: service}
+}
+
+func (c *ReviewController) Create(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	purchaseId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	var input dto.CreateReviewInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Input data is invalid", err))
+		return
+	}
+
+	review, err := c.service.Create(reqCtx, uint(purchaseId), input, *userId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondVersioned(ctx, http.StatusCreated, gin.H{"data": dto.NewLegacyReviewResponse(*review)}, dto.NewReviewResponse(*review))
+}
+
+func (c *ReviewController) Update(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	reviewId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	var input dto.UpdateReviewInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Input data is invalid", err))
+		return
+	}
+
+	review, err := c.service.Update(reqCtx, uint(reviewId), input, *userId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondVersioned(ctx, http.StatusOK, gin.H{"data": dto.NewLegacyReviewResponse(*review)}, dto.NewReviewResponse(*review))
+}
+
+func (c *ReviewController) FindByUser(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+
+	userId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	reviews, err := c.service.FindByReviewee(reqCtx, uint(userId))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondVersionedList(ctx, http.StatusOK, gin.H{"data": dto.NewLegacyReviewResponses(*reviews)}, dto.NewReviewResponses(*reviews))
+}
@@ -0,0 +1,43 @@
+package controllers
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IUserService interface {
+	FindById(ctx context.Context, userId uint) (*models.User, error)
+}
+
+type UserController struct {
+	service IUserService
+}
+
+func NewUserController(service IUserService) *UserController {
+	return &UserController{service: service}
+}
+
+func (c *UserController) FindProfile(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+
+	userId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	user, err := c.service.FindById(reqCtx, uint(userId))
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	profile := dto.NewUserPublicResponse(*user)
+	respondVersioned(ctx, http.StatusOK, gin.H{"data": profile}, profile)
+}
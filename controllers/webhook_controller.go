@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type IWebhookService interface {
+	Create(ctx context.Context, input dto.CreateWebhookInput, userId uint) (*models.Webhook, error)
+	FindByUser(ctx context.Context, userId uint) (*[]models.Webhook, error)
+	Delete(ctx context.Context, webhookId uint, userId uint) error
+	FindDeliveries(ctx context.Context, webhookId uint, userId uint) (*[]models.WebhookDelivery, error)
+	Test(ctx context.Context, webhookId uint, userId uint) (*models.WebhookDelivery, error)
+}
+
+type WebhookController struct {
+	service IWebhookService
+}
+
+func NewWebhookController(service IWebhookService) *WebhookController {
+	return &WebhookController{service: service}
+}
+
+func (c *WebhookController) Create(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	var input dto.CreateWebhookInput
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("Input data is invalid", err))
+		return
+	}
+
+	webhook, err := c.service.Create(reqCtx, input, *userId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondData(ctx, http.StatusCreated, dto.NewCreatedWebhookResponse(*webhook))
+}
+
+func (c *WebhookController) FindByUser(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	webhooks, err := c.service.FindByUser(reqCtx, *userId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondList(ctx, http.StatusOK, dto.NewWebhookResponses(*webhooks))
+}
+
+func (c *WebhookController) Delete(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	webhookId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	if err := c.service.Delete(reqCtx, uint(webhookId), *userId); err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	ctx.Status(http.StatusOK)
+}
+
+func (c *WebhookController) FindDeliveries(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	webhookId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	deliveries, err := c.service.FindDeliveries(reqCtx, uint(webhookId), *userId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondList(ctx, http.StatusOK, dto.NewWebhookDeliveryResponses(*deliveries))
+}
+
+// Test responds 200 with the delivery log even when the webhook failed, so that the user can see why.
+func (c *WebhookController) Test(ctx *gin.Context) {
+	reqCtx := utils.GinToGoContext(ctx)
+	userId, err := getUserId(ctx)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	webhookId, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		_ = ctx.Error(utils.NewBadRequestError("can't get id from path", err))
+		return
+	}
+
+	delivery, err := c.service.Test(reqCtx, uint(webhookId), *userId)
+	if err != nil {
+		_ = ctx.Error(err)
+		return
+	}
+
+	respondData(ctx, http.StatusOK, dto.NewWebhookDeliveryResponse(*delivery))
+}
@@ -9,3 +9,7 @@ type LoginInput struct {
 	Email    string `json:"email" binding:"required,email"`
 	Password string `json:"password" binding:"required,min=8"`
 }
+
+type TokenResponse struct {
+	Token string `json:"token"`
+}
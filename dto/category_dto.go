@@ -0,0 +1,28 @@
+package dto
+
+import "flea-market/models"
+
+// ItemCount includes the items in descendant categories.
+type CategoryTreeNode struct {
+	ID        uint               `json:"id"`
+	Name      string             `json:"name"`
+	Slug      string             `json:"slug"`
+	ItemCount int64              `json:"itemCount"`
+	Children  []CategoryTreeNode `json:"children"`
+}
+
+type CategoryResponse struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Slug     string `json:"slug"`
+	ParentID *uint  `json:"parentId"`
+}
+
+func NewCategoryResponse(category models.Category) CategoryResponse {
+	return CategoryResponse{
+		ID:       category.ID,
+		Name:     category.Name,
+		Slug:     category.Slug,
+		ParentID: category.ParentID,
+	}
+}
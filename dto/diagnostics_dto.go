@@ -0,0 +1,17 @@
+package dto
+
+// DBPoolStatsResponse is the connection pool of the primary or a replica.
+// The counters from waitCount on are totals since the process started.
+type DBPoolStatsResponse struct {
+	Name               string `json:"name"`
+	Healthy            bool   `json:"healthy"`
+	MaxOpenConnections int    `json:"maxOpenConnections"`
+	OpenConnections    int    `json:"openConnections"`
+	InUse              int    `json:"inUse"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"waitCount"`
+	WaitDurationMs     int64  `json:"waitDurationMs"`
+	MaxIdleClosed      int64  `json:"maxIdleClosed"`
+	MaxIdleTimeClosed  int64  `json:"maxIdleTimeClosed"`
+	MaxLifetimeClosed  int64  `json:"maxLifetimeClosed"`
+}
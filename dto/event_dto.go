@@ -0,0 +1,8 @@
+package dto
+
+// payloads of the outbox events. item.created and item.updated carry ItemResponse.
+
+type ItemDeletedEvent struct {
+	ID     uint `json:"id"`
+	UserID uint `json:"userId"`
+}
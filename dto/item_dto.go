@@ -1,14 +1,190 @@
 package dto
 
+import (
+	"flea-market/internal/money"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"time"
+)
+
+// Price is in the minor unit of Currency, which is an ISO 4217 code and JPY when omitted.
+// The limits of a price depend on the currency, so they're checked by the service.
 type CreateItemInput struct {
-	Name        string `json:"name" binding:"required,min=2"`
-	Price       uint   `json:"price" binding:"required,min=1,max=999999"`
-	Description string `json:"description"`
+	Name        string   `json:"name" binding:"required,min=2"`
+	Price       uint     `json:"price" binding:"required,min=1"`
+	Currency    string   `json:"currency" binding:"omitempty,oneof=JPY USD EUR GBP KRW"`
+	Description string   `json:"description"`
+	CategoryID  *uint    `json:"categoryId" binding:"omitnil,min=1"`
+	Condition   string   `json:"condition" binding:"omitempty,oneof=new like-new good fair poor"`
+	Tags        []string `json:"tags" binding:"omitempty,max=10,dive,min=1,max=30"`
 }
 
 type UpdateItemInput struct {
-	Name        *string `json:"name" binding:"omitnil,min=2"`
-	Price       *uint   `json:"price" binding:"omitnil,min=1,max=999999"`
-	Description *string `json:"description"`
-	SoldOut     *bool   `json:"soldOut"`
+	Name        *string   `json:"name" binding:"omitnil,min=2"`
+	Price       *uint     `json:"price" binding:"omitnil,min=1"`
+	Currency    *string   `json:"currency" binding:"omitnil,oneof=JPY USD EUR GBP KRW"`
+	Description *string   `json:"description"`
+	SoldOut     *bool     `json:"soldOut"`
+	CategoryID  *uint     `json:"categoryId" binding:"omitnil,min=1"`
+	Condition   *string   `json:"condition" binding:"omitnil,oneof=new like-new good fair poor"`
+	Tags        *[]string `json:"tags" binding:"omitnil,max=10,dive,min=1,max=30"`
+}
+
+// ItemQuery is the query string of GET /items.
+// category matches the category and all of its descendants.
+type ItemQuery struct {
+	CategoryID *uint  `form:"category" binding:"omitnil,min=1"`
+	Tag        string `form:"tag"`
+	// adds the prices converted into it to the items
+	Currency string `form:"currency" binding:"omitempty,oneof=JPY USD EUR GBP KRW"`
+}
+
+// ItemSearchQuery is the query string of GET /items/search.
+type ItemSearchQuery struct {
+	Q     string `form:"q" binding:"required,max=100"`
+	Limit int    `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// BulkQuery is the query string of the bulk endpoints.
+// When Atomic is true, nothing is written unless every entry succeeds. Otherwise each entry is written independently.
+type BulkQuery struct {
+	Atomic bool `form:"atomic"`
+}
+
+// Entries are validated one by one, so that an invalid entry doesn't reject the whole request.
+type BulkCreateItemsInput struct {
+	Items []CreateItemInput `json:"items" binding:"required,min=1,max=50"`
+}
+
+type BulkUpdateItemEntry struct {
+	ID      uint  `json:"id" binding:"required,min=1"`
+	Price   *uint `json:"price" binding:"omitnil,min=1"`
+	SoldOut *bool `json:"soldOut"`
+}
+
+type BulkUpdateItemsInput struct {
+	Items []BulkUpdateItemEntry `json:"items" binding:"required,min=1,max=50"`
+}
+
+type BulkDeleteItemsInput struct {
+	IDs []uint `json:"ids" binding:"required,min=1,max=50"`
+}
+
+type ItemResponse struct {
+	ID             uint              `json:"id"`
+	Name           string            `json:"name"`
+	Price          uint              `json:"price"`
+	Currency       string            `json:"currency"`
+	FormattedPrice string            `json:"formattedPrice"`
+	Description    string            `json:"description"`
+	SoldOut        bool              `json:"soldOut"`
+	UserID         uint              `json:"userId"`
+	CategoryID     *uint             `json:"categoryId"`
+	Category       *CategoryResponse `json:"category,omitempty"`
+	Condition      string            `json:"condition"`
+	Tags           []string          `json:"tags"`
+	CreatedAt      time.Time         `json:"createdAt"`
+	UpdatedAt      time.Time         `json:"updatedAt"`
+	// only approved items are listed, so the others are seen only by the seller and admins
+	ModerationStatus string `json:"moderationStatus"`
+	ModerationReason string `json:"moderationReason,omitempty"`
+	// only when a currency is asked by GET /items?currency=
+	DisplayPrice *PriceResponse `json:"displayPrice,omitempty"`
+}
+
+// PriceResponse is an amount in the minor unit of the currency.
+type PriceResponse struct {
+	Amount    uint64 `json:"amount"`
+	Currency  string `json:"currency"`
+	Formatted string `json:"formatted"`
+}
+
+func NewPriceResponse(price money.Money) PriceResponse {
+	return PriceResponse{Amount: price.Amount, Currency: string(price.Currency), Formatted: price.String()}
+}
+
+type ItemSearchResultResponse struct {
+	ItemResponse
+	Rank float64 `json:"rank"`
+	// HTML-escaped, and matched words are wrapped with <mark></mark>
+	Headline string `json:"headline"`
+}
+
+func NewItemResponse(item models.Item) ItemResponse {
+	response := ItemResponse{
+		ID:             item.ID,
+		Name:           item.Name,
+		Price:          item.Price,
+		Currency:       string(item.Money().Currency),
+		FormattedPrice: item.Money().String(),
+		Description:    item.Description,
+		SoldOut:        item.SoldOut,
+		UserID:         item.UserID,
+		CategoryID:     item.CategoryID,
+		Condition:      string(item.Condition),
+		Tags:           mapSlice(item.Tags, func(tag models.Tag) string { return tag.Name }),
+		CreatedAt:      item.CreatedAt,
+		UpdatedAt:      item.UpdatedAt,
+
+		ModerationStatus: string(item.ModerationStatus),
+		ModerationReason: item.ModerationReason,
+	}
+	if item.Category != nil {
+		category := NewCategoryResponse(*item.Category)
+		response.Category = &category
+	}
+	if item.DisplayPrice != nil {
+		price := NewPriceResponse(*item.DisplayPrice)
+		response.DisplayPrice = &price
+	}
+	return response
+}
+
+func NewItemResponses(items []models.Item) []ItemResponse {
+	return mapSlice(items, NewItemResponse)
+}
+
+func NewItemSearchResultResponses(results []models.ItemSearchResult) []ItemSearchResultResponse {
+	return mapSlice(results, func(result models.ItemSearchResult) ItemSearchResultResponse {
+		return ItemSearchResultResponse{
+			ItemResponse: NewItemResponse(result.Item),
+			Rank:         result.Rank,
+			Headline:     result.Headline,
+		}
+	})
+}
+
+// BulkItemResultResponse is the result of an entry. Status and Error are the ones it would have as a single request.
+type BulkItemResultResponse struct {
+	Index  int           `json:"index"`
+	ID     *uint         `json:"id,omitempty"`
+	Status int           `json:"status"`
+	Error  string        `json:"error,omitempty"`
+	Item   *ItemResponse `json:"item,omitempty"`
+}
+
+// NewBulkItemResultResponses maps results to responses. successStatus is used for entries without an error.
+func NewBulkItemResultResponses(results []models.ItemBulkResult, successStatus int) []BulkItemResultResponse {
+	responses := make([]BulkItemResultResponse, 0, len(results))
+	for i, result := range results {
+		response := BulkItemResultResponse{Index: i, Status: successStatus}
+		if result.ID != 0 {
+			id := result.ID
+			response.ID = &id
+		}
+		if result.Err != nil {
+			response.Status = http.StatusInternalServerError
+			response.Error = string(utils.UnknownError)
+			if apiErr, ok := result.Err.(*utils.APIError); ok {
+				response.Status = apiErr.StatusCode
+				response.Error = string(apiErr.MessageCode)
+			}
+		} else if result.Item != nil {
+			item := NewItemResponse(*result.Item)
+			response.Item = &item
+		}
+		responses = append(responses, response)
+	}
+	return responses
 }
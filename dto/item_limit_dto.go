@@ -0,0 +1,48 @@
+package dto
+
+import "flea-market/models"
+
+// UpdateUserLimitsInput replaces the override of a user's limits. An omitted limit is taken from the plan.
+// Plan changes the plan of the user when it's set.
+type UpdateUserLimitsInput struct {
+	Plan              *models.Plan `json:"plan" binding:"omitnil,oneof=free pro"`
+	MaxActiveListings *uint        `json:"maxActiveListings"`
+	MaxCreatesPerDay  *uint        `json:"maxCreatesPerDay"`
+	MaxPrice          *uint        `json:"maxPrice"`
+}
+
+type ItemLimitsResponse struct {
+	MaxActiveListings uint `json:"maxActiveListings"`
+	MaxCreatesPerDay  uint `json:"maxCreatesPerDay"`
+	MaxPrice          uint `json:"maxPrice"`
+}
+
+// ItemUsageResponse counts creates over the last 24 hours.
+type ItemUsageResponse struct {
+	ActiveListings uint `json:"activeListings"`
+	CreatesPerDay  uint `json:"createsPerDay"`
+}
+
+// ItemLimitStatusResponse is overridden when an admin changed the limits from those of the plan.
+type ItemLimitStatusResponse struct {
+	Plan       models.Plan        `json:"plan"`
+	Limits     ItemLimitsResponse `json:"limits"`
+	Overridden bool               `json:"overridden"`
+	Usage      ItemUsageResponse  `json:"usage"`
+}
+
+func NewItemLimitStatusResponse(status models.ItemLimitStatus) ItemLimitStatusResponse {
+	return ItemLimitStatusResponse{
+		Plan: status.Plan,
+		Limits: ItemLimitsResponse{
+			MaxActiveListings: status.Limits.MaxActiveListings,
+			MaxCreatesPerDay:  status.Limits.MaxCreatesPerDay,
+			MaxPrice:          status.Limits.MaxPrice,
+		},
+		Overridden: status.Overridden,
+		Usage: ItemUsageResponse{
+			ActiveListings: status.Usage.ActiveListings,
+			CreatesPerDay:  status.Usage.CreatesPerDay,
+		},
+	}
+}
@@ -0,0 +1,70 @@
+package dto
+
+import (
+	"flea-market/models"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ItemExportQuery is the query string of GET /me/items/export. format defaults to csv.
+type ItemExportQuery struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv jsonl"`
+}
+
+// ItemImportQuery is the query string of POST /me/items/import.
+// With dryRun, rows are only validated. With report=csv, the errors are returned as a CSV file.
+type ItemImportQuery struct {
+	DryRun bool   `form:"dryRun"`
+	Report string `form:"report" binding:"omitempty,oneof=json csv"`
+}
+
+// ItemImportError is a failed field of a row. Row is the line number in the file, and the header is line 1.
+// Field is empty when the whole row failed.
+type ItemImportError struct {
+	Row    int    `json:"row"`
+	Field  string `json:"field"`
+	Reason string `json:"reason"`
+}
+
+// Rows are imported only when every row is valid.
+type ItemImportReport struct {
+	DryRun   bool              `json:"dryRun"`
+	Rows     int               `json:"rows"`
+	Imported int               `json:"imported"`
+	Errors   []ItemImportError `json:"errors"`
+}
+
+// CSV columns of an item. Import reads ItemImportColumns, and the other exported columns are ignored,
+// so that an exported file can be imported again.
+var (
+	ItemImportColumns = []string{"name", "price", "currency", "description", "category_id", "condition", "tags"}
+	ItemExportColumns = slices.Concat([]string{"id"}, ItemImportColumns, []string{"sold_out", "created_at"})
+)
+
+// tags are joined with this separator in a cell
+const ItemCSVTagSeparator = "|"
+
+func NewItemCSVRecord(item models.Item) []string {
+	categoryId := ""
+	if item.CategoryID != nil {
+		categoryId = strconv.FormatUint(uint64(*item.CategoryID), 10)
+	}
+	tags := make([]string, 0, len(item.Tags))
+	for _, tag := range item.Tags {
+		tags = append(tags, tag.Name)
+	}
+	return []string{
+		strconv.FormatUint(uint64(item.ID), 10),
+		item.Name,
+		strconv.FormatUint(uint64(item.Price), 10),
+		string(item.Money().Currency),
+		item.Description,
+		categoryId,
+		string(item.Condition),
+		strings.Join(tags, ItemCSVTagSeparator),
+		strconv.FormatBool(item.SoldOut),
+		item.CreatedAt.Format(time.RFC3339),
+	}
+}
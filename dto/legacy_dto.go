@@ -0,0 +1,183 @@
+package dto
+
+import (
+	"flea-market/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The Legacy responses are the bodies of v1 and the aliases, frozen in the shape the models had when v2 was introduced.
+// They keep the field names of Go, which the models were serialized with, and leave out the columns added since then,
+// like currency and moderation, so that new columns aren't sent to v1 clients.
+
+// LegacyModel is gorm.Model as it was serialized.
+type LegacyModel struct {
+	ID        uint
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt *time.Time
+}
+
+type LegacyTagResponse struct {
+	LegacyModel
+	Name string
+}
+
+type LegacyCategoryResponse struct {
+	LegacyModel
+	Name     string
+	Slug     string
+	ParentID *uint
+	Children []LegacyCategoryResponse `json:",omitempty"`
+}
+
+type LegacyItemResponse struct {
+	LegacyModel
+	Name        string
+	Price       uint
+	Description string
+	SoldOut     bool
+	UserID      uint
+	CategoryID  *uint
+	Category    *LegacyCategoryResponse `json:",omitempty"`
+	Condition   string
+	Tags        []LegacyTagResponse
+}
+
+type LegacyItemSearchResultResponse struct {
+	LegacyItemResponse
+	Rank     float64
+	Headline string
+}
+
+type LegacyOfferResponse struct {
+	LegacyModel
+	ItemID        uint
+	BuyerID       uint
+	SellerID      uint
+	ProposerID    uint
+	Price         uint
+	Status        string
+	ParentID      *uint
+	ExpiresAt     time.Time
+	ReservedUntil *time.Time
+}
+
+type LegacyPurchaseResponse struct {
+	LegacyModel
+	ItemID   uint
+	BuyerID  uint
+	SellerID uint
+	Price    uint
+	OfferID  *uint
+}
+
+type LegacyReviewResponse struct {
+	LegacyModel
+	PurchaseID   uint
+	ReviewerID   uint
+	RevieweeID   uint
+	ReviewerRole string
+	Rating       uint
+	Comment      string
+}
+
+func newLegacyModel(model gorm.Model) LegacyModel {
+	legacy := LegacyModel{ID: model.ID, CreatedAt: model.CreatedAt, UpdatedAt: model.UpdatedAt}
+	if model.DeletedAt.Valid {
+		legacy.DeletedAt = &model.DeletedAt.Time
+	}
+	return legacy
+}
+
+func newLegacyCategoryResponse(category models.Category) LegacyCategoryResponse {
+	return LegacyCategoryResponse{
+		LegacyModel: newLegacyModel(category.Model),
+		Name:        category.Name,
+		Slug:        category.Slug,
+		ParentID:    category.ParentID,
+		Children:    mapSlice(category.Children, newLegacyCategoryResponse),
+	}
+}
+
+func NewLegacyItemResponse(item models.Item) LegacyItemResponse {
+	response := LegacyItemResponse{
+		LegacyModel: newLegacyModel(item.Model),
+		Name:        item.Name,
+		Price:       item.Price,
+		Description: item.Description,
+		SoldOut:     item.SoldOut,
+		UserID:      item.UserID,
+		CategoryID:  item.CategoryID,
+		Condition:   string(item.Condition),
+		Tags: mapSlice(item.Tags, func(tag models.Tag) LegacyTagResponse {
+			return LegacyTagResponse{LegacyModel: newLegacyModel(tag.Model), Name: tag.Name}
+		}),
+	}
+	if item.Category != nil {
+		category := newLegacyCategoryResponse(*item.Category)
+		response.Category = &category
+	}
+	return response
+}
+
+func NewLegacyItemResponses(items []models.Item) []LegacyItemResponse {
+	return mapSlice(items, NewLegacyItemResponse)
+}
+
+func NewLegacyItemSearchResultResponses(results []models.ItemSearchResult) []LegacyItemSearchResultResponse {
+	return mapSlice(results, func(result models.ItemSearchResult) LegacyItemSearchResultResponse {
+		return LegacyItemSearchResultResponse{
+			LegacyItemResponse: NewLegacyItemResponse(result.Item),
+			Rank:               result.Rank,
+			Headline:           result.Headline,
+		}
+	})
+}
+
+func NewLegacyOfferResponse(offer models.Offer) LegacyOfferResponse {
+	return LegacyOfferResponse{
+		LegacyModel:   newLegacyModel(offer.Model),
+		ItemID:        offer.ItemID,
+		BuyerID:       offer.BuyerID,
+		SellerID:      offer.SellerID,
+		ProposerID:    offer.ProposerID,
+		Price:         offer.Price,
+		Status:        string(offer.Status),
+		ParentID:      offer.ParentID,
+		ExpiresAt:     offer.ExpiresAt,
+		ReservedUntil: offer.ReservedUntil,
+	}
+}
+
+func NewLegacyOfferResponses(offers []models.Offer) []LegacyOfferResponse {
+	return mapSlice(offers, NewLegacyOfferResponse)
+}
+
+func NewLegacyPurchaseResponse(purchase models.Purchase) LegacyPurchaseResponse {
+	return LegacyPurchaseResponse{
+		LegacyModel: newLegacyModel(purchase.Model),
+		ItemID:      purchase.ItemID,
+		BuyerID:     purchase.BuyerID,
+		SellerID:    purchase.SellerID,
+		Price:       purchase.Price,
+		OfferID:     purchase.OfferID,
+	}
+}
+
+func NewLegacyReviewResponse(review models.Review) LegacyReviewResponse {
+	return LegacyReviewResponse{
+		LegacyModel:  newLegacyModel(review.Model),
+		PurchaseID:   review.PurchaseID,
+		ReviewerID:   review.ReviewerID,
+		RevieweeID:   review.RevieweeID,
+		ReviewerRole: string(review.ReviewerRole),
+		Rating:       review.Rating,
+		Comment:      review.Comment,
+	}
+}
+
+func NewLegacyReviewResponses(reviews []models.Review) []LegacyReviewResponse {
+	return mapSlice(reviews, NewLegacyReviewResponse)
+}
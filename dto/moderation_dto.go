@@ -0,0 +1,56 @@
+package dto
+
+import (
+	"flea-market/models"
+	"time"
+)
+
+type ReportItemInput struct {
+	Reason  string `json:"reason" binding:"required,oneof=prohibited offensive fraud other"`
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+type ItemReportResponse struct {
+	ID         uint      `json:"id"`
+	ItemID     uint      `json:"itemId"`
+	ReporterID uint      `json:"reporterId"`
+	Reason     string    `json:"reason"`
+	Comment    string    `json:"comment"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func NewItemReportResponse(report models.ItemReport) ItemReportResponse {
+	return ItemReportResponse{
+		ID:         report.ID,
+		ItemID:     report.ItemID,
+		ReporterID: report.ReporterID,
+		Reason:     string(report.Reason),
+		Comment:    report.Comment,
+		CreatedAt:  report.CreatedAt,
+	}
+}
+
+type ModerationQueueQuery struct {
+	Limit int `form:"limit" binding:"omitempty,min=1,max=100"`
+}
+
+// ModerationQueueEntryResponse is an item waiting for an admin. Reports are the open ones.
+type ModerationQueueEntryResponse struct {
+	Item    ItemResponse         `json:"item"`
+	Reports []ItemReportResponse `json:"reports"`
+}
+
+func NewModerationQueueResponses(entries []models.ModerationQueueEntry) []ModerationQueueEntryResponse {
+	return mapSlice(entries, func(entry models.ModerationQueueEntry) ModerationQueueEntryResponse {
+		return ModerationQueueEntryResponse{
+			Item:    NewItemResponse(entry.Item),
+			Reports: mapSlice(entry.Reports, NewItemReportResponse),
+		}
+	})
+}
+
+// ModerationDecisionInput needs a reason to reject, which is shown to the seller.
+type ModerationDecisionInput struct {
+	Status string `json:"status" binding:"required,oneof=approved rejected"`
+	Reason string `json:"reason" binding:"required_if=Status rejected,max=500"`
+}
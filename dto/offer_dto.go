@@ -0,0 +1,50 @@
+package dto
+
+import (
+	"flea-market/models"
+	"time"
+)
+
+type CreateOfferInput struct {
+	Price uint `json:"price" binding:"required,min=1,max=999999"`
+}
+
+type CounterOfferInput struct {
+	Price uint `json:"price" binding:"required,min=1,max=999999"`
+}
+
+type OfferResponse struct {
+	ID            uint       `json:"id"`
+	ItemID        uint       `json:"itemId"`
+	BuyerID       uint       `json:"buyerId"`
+	SellerID      uint       `json:"sellerId"`
+	ProposerID    uint       `json:"proposerId"`
+	Price         uint       `json:"price"`
+	Status        string     `json:"status"`
+	ParentID      *uint      `json:"parentId"`
+	ExpiresAt     time.Time  `json:"expiresAt"`
+	ReservedUntil *time.Time `json:"reservedUntil"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+func NewOfferResponse(offer models.Offer) OfferResponse {
+	return OfferResponse{
+		ID:            offer.ID,
+		ItemID:        offer.ItemID,
+		BuyerID:       offer.BuyerID,
+		SellerID:      offer.SellerID,
+		ProposerID:    offer.ProposerID,
+		Price:         offer.Price,
+		Status:        string(offer.Status),
+		ParentID:      offer.ParentID,
+		ExpiresAt:     offer.ExpiresAt,
+		ReservedUntil: offer.ReservedUntil,
+		CreatedAt:     offer.CreatedAt,
+		UpdatedAt:     offer.UpdatedAt,
+	}
+}
+
+func NewOfferResponses(offers []models.Offer) []OfferResponse {
+	return mapSlice(offers, NewOfferResponse)
+}
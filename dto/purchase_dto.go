@@ -0,0 +1,28 @@
+package dto
+
+import (
+	"flea-market/models"
+	"time"
+)
+
+type PurchaseResponse struct {
+	ID          uint      `json:"id"`
+	ItemID      uint      `json:"itemId"`
+	BuyerID     uint      `json:"buyerId"`
+	SellerID    uint      `json:"sellerId"`
+	Price       uint      `json:"price"`
+	OfferID     *uint     `json:"offerId"`
+	PurchasedAt time.Time `json:"purchasedAt"`
+}
+
+func NewPurchaseResponse(purchase models.Purchase) PurchaseResponse {
+	return PurchaseResponse{
+		ID:          purchase.ID,
+		ItemID:      purchase.ItemID,
+		BuyerID:     purchase.BuyerID,
+		SellerID:    purchase.SellerID,
+		Price:       purchase.Price,
+		OfferID:     purchase.OfferID,
+		PurchasedAt: purchase.CreatedAt,
+	}
+}
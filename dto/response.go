@@ -0,0 +1,22 @@
+package dto
+
+// Response is the body of every successful JSON response.
+// Models are never put in Data directly, so that new columns aren't exposed by accident.
+type Response struct {
+	Data any  `json:"data"`
+	Meta Meta `json:"meta"`
+}
+
+type Meta struct {
+	RequestID string `json:"requestId,omitempty"`
+	// set when Data is a list
+	Count *int `json:"count,omitempty"`
+}
+
+func mapSlice[M any, R any](models []M, mapper func(M) R) []R {
+	responses := make([]R, 0, len(models))
+	for _, model := range models {
+		responses = append(responses, mapper(model))
+	}
+	return responses
+}
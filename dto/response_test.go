@@ -0,0 +1,75 @@
+package dto
+
+import (
+	"encoding/json"
+	"flea-market/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestNewItemResponse_WireFormat(t *testing.T) {
+	createdAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	categoryId := uint(3)
+	item := models.Item{
+		Model:       gorm.Model{ID: 1, CreatedAt: createdAt, UpdatedAt: createdAt},
+		Name:        "phone",
+		Price:       100,
+		Description: "used",
+		UserID:      2,
+		CategoryID:  &categoryId,
+		Condition:   models.ConditionGood,
+		Tags:        []models.Tag{{Name: "apple"}},
+
+		ModerationStatus: models.ModerationApproved,
+	}
+
+	body, err := json.Marshal(Response{Data: NewItemResponse(item), Meta: Meta{RequestID: "abc"}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"data": {
+			"id": 1,
+			"name": "phone",
+			"price": 100,
+			"currency": "JPY",
+			"formattedPrice": "¥100",
+			"description": "used",
+			"soldOut": false,
+			"userId": 2,
+			"categoryId": 3,
+			"condition": "good",
+			"tags": ["apple"],
+			"createdAt": "2025-01-02T03:04:05Z",
+			"updatedAt": "2025-01-02T03:04:05Z",
+			"moderationStatus": "approved"
+		},
+		"meta": {"requestId": "abc"}
+	}`, string(body))
+}
+
+func TestNewItemResponses_Empty(t *testing.T) {
+	body, err := json.Marshal(NewItemResponses(nil))
+	assert.NoError(t, err)
+	assert.Equal(t, "[]", string(body))
+
+	// items without tags
+	body, err = json.Marshal(NewItemResponse(models.Item{}))
+	assert.NoError(t, err)
+	assert.Contains(t, string(body), `"tags":[]`)
+}
+
+func TestNewUserPublicResponse_WireFormat(t *testing.T) {
+	user := models.User{
+		Model:       gorm.Model{ID: 1},
+		Email:       "test@test.com",
+		Password:    "$2a$10$hashed",
+		ReviewCount: 2,
+		RatingSum:   9,
+	}
+
+	body, err := json.Marshal(NewUserPublicResponse(user))
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"id": 1, "reviewCount": 2, "averageRating": 4.5}`, string(body))
+}
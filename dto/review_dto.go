@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"flea-market/models"
+	"time"
+)
+
+type CreateReviewInput struct {
+	Rating  uint   `json:"rating" binding:"required,min=1,max=5"`
+	Comment string `json:"comment" binding:"max=1000"`
+}
+
+type UpdateReviewInput struct {
+	Rating  *uint   `json:"rating" binding:"omitnil,min=1,max=5"`
+	Comment *string `json:"comment" binding:"omitnil,max=1000"`
+}
+
+type ReviewResponse struct {
+	ID           uint      `json:"id"`
+	PurchaseID   uint      `json:"purchaseId"`
+	ReviewerID   uint      `json:"reviewerId"`
+	RevieweeID   uint      `json:"revieweeId"`
+	ReviewerRole string    `json:"reviewerRole"`
+	Rating       uint      `json:"rating"`
+	Comment      string    `json:"comment"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+func NewReviewResponse(review models.Review) ReviewResponse {
+	return ReviewResponse{
+		ID:           review.ID,
+		PurchaseID:   review.PurchaseID,
+		ReviewerID:   review.ReviewerID,
+		RevieweeID:   review.RevieweeID,
+		ReviewerRole: string(review.ReviewerRole),
+		Rating:       review.Rating,
+		Comment:      review.Comment,
+		CreatedAt:    review.CreatedAt,
+		UpdatedAt:    review.UpdatedAt,
+	}
+}
+
+func NewReviewResponses(reviews []models.Review) []ReviewResponse {
+	return mapSlice(reviews, NewReviewResponse)
+}
@@ -0,0 +1,18 @@
+package dto
+
+import "flea-market/models"
+
+// UserPublicResponse is the part of a user which anyone can see. Email and password must not be included.
+type UserPublicResponse struct {
+	ID            uint    `json:"id"`
+	ReviewCount   uint    `json:"reviewCount"`
+	AverageRating float64 `json:"averageRating"`
+}
+
+func NewUserPublicResponse(user models.User) UserPublicResponse {
+	return UserPublicResponse{
+		ID:            user.ID,
+		ReviewCount:   user.ReviewCount,
+		AverageRating: user.AverageRating(),
+	}
+}
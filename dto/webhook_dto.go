@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"encoding/json"
+	"flea-market/models"
+	"time"
+)
+
+type CreateWebhookInput struct {
+	URL    string   `json:"url" binding:"required,http_url,max=2048"`
+	Events []string `json:"events" binding:"required,min=1,unique,dive,oneof=offer_received item_sold"`
+}
+
+type WebhookResponse struct {
+	ID     uint     `json:"id"`
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	// only in the response of creation
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+func NewWebhookResponse(webhook models.Webhook) WebhookResponse {
+	return WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    mapSlice(webhook.Events, func(event models.NotificationType) string { return string(event) }),
+		CreatedAt: webhook.CreatedAt,
+	}
+}
+
+func NewWebhookResponses(webhooks []models.Webhook) []WebhookResponse {
+	return mapSlice(webhooks, NewWebhookResponse)
+}
+
+// NewCreatedWebhookResponse includes the secret, which can't be seen afterwards.
+func NewCreatedWebhookResponse(webhook models.Webhook) WebhookResponse {
+	response := NewWebhookResponse(webhook)
+	response.Secret = webhook.Secret
+	return response
+}
+
+type WebhookDeliveryResponse struct {
+	ID         uint      `json:"id"`
+	EventID    string    `json:"eventId"`
+	Event      string    `json:"event"`
+	Attempt    int       `json:"attempt"`
+	Succeeded  bool      `json:"succeeded"`
+	StatusCode int       `json:"statusCode"`
+	Error      string    `json:"error"`
+	DurationMs int64     `json:"durationMs"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+func NewWebhookDeliveryResponse(delivery models.WebhookDelivery) WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		ID:         delivery.ID,
+		EventID:    delivery.EventID,
+		Event:      string(delivery.Event),
+		Attempt:    delivery.Attempt,
+		Succeeded:  delivery.Succeeded(),
+		StatusCode: delivery.StatusCode,
+		Error:      delivery.Error,
+		DurationMs: delivery.DurationMs,
+		CreatedAt:  delivery.CreatedAt,
+	}
+}
+
+func NewWebhookDeliveryResponses(deliveries []models.WebhookDelivery) []WebhookDeliveryResponse {
+	return mapSlice(deliveries, NewWebhookDeliveryResponse)
+}
+
+// WebhookEvent is the body POSTed to webhooks. Data is the payload of the notification.
+type WebhookEvent struct {
+	ID        string          `json:"id"`
+	Event     string          `json:"event"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
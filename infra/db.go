@@ -1,36 +1,169 @@
 package infra
 
 import (
+	"context"
+	"database/sql"
 	"flea-market/utils"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
+	"time"
 
-	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
+const (
+	defaultMaxOpenConns    = 25
+	defaultMaxIdleConns    = 10
+	defaultConnMaxLifetime = 30 * time.Minute
+	defaultConnMaxIdleTime = 5 * time.Minute
+	defaultConnectTimeout  = 30 * time.Second
+	connectRetryInitial    = 500 * time.Millisecond
+	connectRetryMax        = 5 * time.Second
+)
+
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
 func SetupDB() *gorm.DB {
+	dialector := newDialector()
+	timeout := durationEnv("DB_CONNECT_TIMEOUT", defaultConnectTimeout)
+
+	// the DB can start later than the app, e.g. in docker compose
+	db, err := connectWithRetry(func() (*gorm.DB, error) {
+		return gorm.Open(dialector, &gorm.Config{TranslateError: true})
+	}, timeout, time.Sleep)
+	if err != nil {
+		cstmErr := utils.NewDBError("Connecting db error", err)
+		utils.Logger(cstmErr.MessageCode, "", "", "", cstmErr)
+		panic(cstmErr.Error())
+	}
+	utils.Logger(utils.GenericMessage, "", "", "", "Connecting DB successes")
+
+	pool, err := db.DB()
+	if err != nil {
+		panic(utils.NewDBError("Getting connection pool error", err).Error())
+	}
+	if IsSQLite(db) {
+		configureSQLitePool(pool)
+		return db
+	}
+	configurePool(pool)
+
+	// reads go to the replicas when they are configured
+	if router := setupReplicaRouter(); router != nil {
+		if err := db.Use(router); err != nil {
+			panic(utils.NewDBError("Registering replica router error", err).Error())
+		}
+		utils.Logger(utils.GenericMessage, "", "", "", fmt.Sprintf("Routing reads to %d replicas", len(router.replicas)))
+	}
+
+	return db
+}
+
+// primaryDSN builds the DSN from the DB_ envs. DB_SSLMODE defaults to disable for local development,
+// and DB_SSLROOTCERT is the CA file to verify the server with verify-ca or verify-full.
+func primaryDSN() string {
+	sslMode := os.Getenv("DB_SSLMODE")
+	if sslMode == "" {
+		sslMode = "disable"
+	}
+	if !slices.Contains(sslModes, sslMode) {
+		panic("env DB_SSLMODE must be one of disable, allow, prefer, require, verify-ca and verify-full: " + sslMode)
+	}
+
 	dns := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=disable TimeZone=Asia/Tokyo",
+		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Tokyo",
 		os.Getenv("DB_HOST"),
 		os.Getenv("DB_USER"),
 		os.Getenv("DB_PASSWORD"),
 		os.Getenv("DB_NAME"),
 		os.Getenv("DB_PORT"),
+		sslMode,
 	)
+	if rootCert := os.Getenv("DB_SSLROOTCERT"); rootCert != "" {
+		dns += " sslrootcert=" + rootCert
+	}
+	return dns
+}
 
-	var (
-		db  *gorm.DB
-		err error
-	)
+// connectWithRetry calls open until it succeeds, waiting with exponential backoff between the attempts.
+// It returns the last error when timeout passes. A timeout of 0 tries only once.
+func connectWithRetry(open func() (*gorm.DB, error), timeout time.Duration, sleep func(time.Duration)) (*gorm.DB, error) {
+	deadline := time.Now().Add(timeout)
+	wait := connectRetryInitial
+	for attempts := 1; ; attempts++ {
+		db, err := open()
+		if err == nil {
+			return db, nil
+		}
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return nil, err
+		}
+
+		wait = min(wait, remaining)
+		utils.Logger(utils.DBConnectRetrying, "", "", "", attempts, wait, err.Error())
+		sleep(wait)
+		wait = min(wait*2, connectRetryMax)
+	}
+}
+
+// configurePool limits the connections of a pool by DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS,
+// DB_CONN_MAX_LIFETIME and DB_CONN_MAX_IDLE_TIME. The same limits apply to each replica.
+func configurePool(pool *sql.DB) {
+	pool.SetMaxOpenConns(intEnv("DB_MAX_OPEN_CONNS", defaultMaxOpenConns))
+	pool.SetMaxIdleConns(intEnv("DB_MAX_IDLE_CONNS", defaultMaxIdleConns))
+	pool.SetConnMaxLifetime(durationEnv("DB_CONN_MAX_LIFETIME", defaultConnMaxLifetime))
+	pool.SetConnMaxIdleTime(durationEnv("DB_CONN_MAX_IDLE_TIME", defaultConnMaxIdleTime))
+}
+
+// PoolStats is the connection pool stats of the primary or a replica.
+type PoolStats struct {
+	Name    string
+	Healthy bool
+	sql.DBStats
+}
 
-	db, err = gorm.Open(postgres.Open(dns), &gorm.Config{TranslateError: true})
+// PoolStatsOf returns the stats of the primary and then of the replicas of db.
+// The primary is pinged, and the replicas report the result of their last health check.
+func PoolStatsOf(ctx context.Context, db *gorm.DB) ([]PoolStats, error) {
+	pool, err := db.DB()
 	if err != nil {
-		cstmErr := utils.NewDBError("Connecting db error", err)
-		utils.Logger(cstmErr.MessageCode, "", "", "", cstmErr)
-		panic(cstmErr.Error())
+		return nil, err
 	}
-	utils.Logger(utils.GenericMessage, "", "", "", "Connecting DB successes")
+	pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+	defer cancel()
+	stats := []PoolStats{{Name: "primary", Healthy: pool.PingContext(pingCtx) == nil, DBStats: pool.Stats()}}
 
-	return db
+	if router := ReplicaRouterOf(db); router != nil {
+		for _, replica := range router.replicas {
+			stats = append(stats, PoolStats{Name: replica.name, Healthy: replica.healthy.Load(), DBStats: replica.pool.Stats()})
+		}
+	}
+	return stats, nil
+}
+
+func intEnv(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		panic("env " + key + " must be a non-negative integer: " + value)
+	}
+	return n
+}
+
+func durationEnv(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		panic("env " + key + " must be a non-negative duration: " + value)
+	}
+	return d
 }
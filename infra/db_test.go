@@ -1,16 +1,25 @@
 package infra
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
 )
 
 func TestSetupDB_PanicOnError(t *testing.T) {
 	t.Setenv("ENV", "production") // testじゃないのでPostgresパス
+	t.Setenv("DB_DRIVER", "postgres")
 	t.Setenv("DB_HOST", "invalid_host")
 	t.Setenv("DB_USER", "invalid_user")
 	t.Setenv("DB_PASSWORD", "invalid_password")
 	t.Setenv("DB_NAME", "invalid_db")
 	t.Setenv("DB_PORT", "1234")
+	t.Setenv("DB_CONNECT_TIMEOUT", "0")
 
 	defer func() {
 		if r := recover(); r == nil {
@@ -20,3 +29,100 @@ func TestSetupDB_PanicOnError(t *testing.T) {
 
 	SetupDB()
 }
+
+func TestPrimaryDSN(t *testing.T) {
+	t.Setenv("DB_HOST", "db")
+	t.Setenv("DB_USER", "user")
+	t.Setenv("DB_PASSWORD", "password")
+	t.Setenv("DB_NAME", "fleamarket")
+	t.Setenv("DB_PORT", "5432")
+
+	t.Run("defaults to sslmode=disable", func(t *testing.T) {
+		assert.Equal(t, "host=db user=user password=password dbname=fleamarket port=5432 sslmode=disable TimeZone=Asia/Tokyo", primaryDSN())
+	})
+
+	t.Run("with sslmode and root CA", func(t *testing.T) {
+		t.Setenv("DB_SSLMODE", "verify-full")
+		t.Setenv("DB_SSLROOTCERT", "/etc/ssl/rds.pem")
+
+		assert.Equal(t, "host=db user=user password=password dbname=fleamarket port=5432 sslmode=verify-full TimeZone=Asia/Tokyo sslrootcert=/etc/ssl/rds.pem", primaryDSN())
+	})
+
+	t.Run("invalid sslmode", func(t *testing.T) {
+		t.Setenv("DB_SSLMODE", "on")
+
+		assert.Panics(t, func() { primaryDSN() })
+	})
+}
+
+func TestConnectWithRetry(t *testing.T) {
+	t.Run("retries with backoff until connected", func(t *testing.T) {
+		attempts := 0
+		var waits []time.Duration
+		db, err := connectWithRetry(func() (*gorm.DB, error) {
+			attempts++
+			if attempts < 5 {
+				return nil, errors.New("connection refused")
+			}
+			return &gorm.DB{}, nil
+		}, time.Minute, func(d time.Duration) { waits = append(waits, d) })
+
+		assert.NoError(t, err)
+		assert.NotNil(t, db)
+		assert.Equal(t, 5, attempts)
+		assert.Equal(t, []time.Duration{500 * time.Millisecond, time.Second, 2 * time.Second, 4 * time.Second}, waits)
+	})
+
+	t.Run("gives up after the timeout", func(t *testing.T) {
+		attempts := 0
+		_, err := connectWithRetry(func() (*gorm.DB, error) {
+			attempts++
+			return nil, errors.New("connection refused")
+		}, 50*time.Millisecond, time.Sleep)
+
+		assert.EqualError(t, err, "connection refused")
+		assert.Equal(t, 2, attempts)
+	})
+
+	t.Run("tries once without a timeout", func(t *testing.T) {
+		attempts := 0
+		_, err := connectWithRetry(func() (*gorm.DB, error) {
+			attempts++
+			return nil, errors.New("connection refused")
+		}, 0, func(time.Duration) { t.Fatal("must not wait") })
+
+		assert.Error(t, err)
+		assert.Equal(t, 1, attempts)
+	})
+}
+
+func TestConfigurePool(t *testing.T) {
+	pool, _, err := sqlmock.New()
+	assert.NoError(t, err)
+
+	configurePool(pool)
+	assert.Equal(t, defaultMaxOpenConns, pool.Stats().MaxOpenConnections)
+
+	t.Setenv("DB_MAX_OPEN_CONNS", "7")
+	configurePool(pool)
+	assert.Equal(t, 7, pool.Stats().MaxOpenConnections)
+
+	t.Setenv("DB_CONN_MAX_LIFETIME", "forever")
+	assert.Panics(t, func() { configurePool(pool) })
+}
+
+func TestPoolStatsOf(t *testing.T) {
+	db, _, _, router := setupReplicaRouterTest(t, 2)
+	router.replicas[1].healthy.Store(false)
+
+	stats, err := PoolStatsOf(context.Background(), db)
+
+	assert.NoError(t, err)
+	assert.Len(t, stats, 3)
+	assert.Equal(t, "primary", stats[0].Name)
+	assert.True(t, stats[0].Healthy)
+	assert.Equal(t, "replica-1", stats[1].Name)
+	assert.True(t, stats[1].Healthy)
+	assert.Equal(t, "replica-2", stats[2].Name)
+	assert.False(t, stats[2].Healthy)
+}
@@ -0,0 +1,51 @@
+package infra
+
+import (
+	"database/sql"
+	"os"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	DialectPostgres = "postgres"
+	DialectSQLite   = "sqlite"
+)
+
+// newDialector selects the driver by DB_DRIVER, postgres (default) or sqlite.
+// SQLite is for local development and tests without Postgres. Its DB_NAME is the file path, or :memory: for an in-memory DB.
+func newDialector() gorm.Dialector {
+	switch driver := os.Getenv("DB_DRIVER"); driver {
+	case "", DialectPostgres:
+		return postgres.Open(primaryDSN())
+	case DialectSQLite:
+		return sqlite.Open(sqliteDSN())
+	default:
+		panic("env DB_DRIVER must be postgres or sqlite: " + driver)
+	}
+}
+
+// SQLite doesn't enforce foreign keys unless it's enabled per connection.
+func sqliteDSN() string {
+	name := os.Getenv("DB_NAME")
+	if name == "" {
+		name = ":memory:"
+	}
+	return name + "?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+}
+
+// IsSQLite tells whether db is SQLite, for the queries which differ from Postgres.
+func IsSQLite(db *gorm.DB) bool {
+	return db.Dialector.Name() == DialectSQLite
+}
+
+// configureSQLitePool keeps a single connection open forever.
+// An in-memory DB is dropped with its last connection, and SQLite allows only one writer anyway.
+func configureSQLitePool(pool *sql.DB) {
+	pool.SetMaxOpenConns(1)
+	pool.SetMaxIdleConns(1)
+	pool.SetConnMaxLifetime(0)
+	pool.SetConnMaxIdleTime(0)
+}
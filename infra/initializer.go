@@ -1,13 +1,23 @@
 package infra
 
 import (
+	"flea-market/internal/i18n"
 	"flea-market/utils"
+	"os"
 
 	"github.com/joho/godotenv"
 )
 
+// Initializer loads .env and sets the locale of logs by LOG_LOCALE, which defaults to ja.
 func Initializer() {
 	if err := godotenv.Load(); err != nil {
 		utils.Logger(utils.GenericMessage, "", "", "", ".env file not found; relying on environment variables")
 	}
+	if value := os.Getenv("LOG_LOCALE"); value != "" {
+		locale, ok := i18n.ParseLocale(value)
+		if !ok {
+			panic("env LOG_LOCALE must be one of en and ja: " + value)
+		}
+		utils.SetLogLocale(locale)
+	}
 }
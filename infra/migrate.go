@@ -0,0 +1,93 @@
+package infra
+
+import (
+	"flea-market/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// search_vector is maintained by Postgres, so it's not a field of models.Item.
+// Name is weighted higher than Description when ranking.
+// 'simple' configuration is used because most listings are written in Japanese, which has no stemmer.
+var postgresSearchIndexSQLs = []string{
+	`ALTER TABLE items ADD COLUMN IF NOT EXISTS search_vector tsvector
+		GENERATED ALWAYS AS (
+			setweight(to_tsvector('simple', coalesce(name, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce(description, '')), 'B')
+		) STORED`,
+	`CREATE INDEX IF NOT EXISTS idx_items_search_vector ON items USING GIN (search_vector)`,
+}
+
+// items_search is an FTS5 index of items kept in sync by the triggers. Its rowid is the id of the item.
+var sqliteSearchIndexSQLs = []string{
+	`CREATE VIRTUAL TABLE IF NOT EXISTS items_search USING fts5(name, description, content='items', content_rowid='id')`,
+	`CREATE TRIGGER IF NOT EXISTS items_search_insert AFTER INSERT ON items BEGIN
+		INSERT INTO items_search(rowid, name, description) VALUES (new.id, new.name, new.description);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS items_search_delete AFTER DELETE ON items BEGIN
+		INSERT INTO items_search(items_search, rowid, name, description) VALUES ('delete', old.id, old.name, old.description);
+	END`,
+	`CREATE TRIGGER IF NOT EXISTS items_search_update AFTER UPDATE ON items BEGIN
+		INSERT INTO items_search(items_search, rowid, name, description) VALUES ('delete', old.id, old.name, old.description);
+		INSERT INTO items_search(rowid, name, description) VALUES (new.id, new.name, new.description);
+	END`,
+}
+
+// Migrate creates or updates the tables of the models and the search index.
+func Migrate(db *gorm.DB) error {
+	if err := db.AutoMigrate(&models.User{}, &models.Item{}, &models.Notification{}, &models.Offer{}, &models.Purchase{}, &models.Category{}, &models.Tag{}, &models.Review{}, &models.IdempotencyKey{}, &models.Job{}, &models.OutboxEvent{}, &models.Webhook{}, &models.WebhookDelivery{}, &models.UserLimitOverride{}, &models.ItemReport{}); err != nil {
+		return err
+	}
+
+	searchIndexSQLs := postgresSearchIndexSQLs
+	if IsSQLite(db) {
+		searchIndexSQLs = sqliteSearchIndexSQLs
+	}
+	for _, sql := range searchIndexSQLs {
+		if err := db.Exec(sql).Error; err != nil {
+			return fmt.Errorf("creating search index: %w", err)
+		}
+	}
+	return nil
+}
+
+// TruncateTables deletes all rows and resets the ids of every table.
+func TruncateTables(db *gorm.DB) error {
+	if IsSQLite(db) {
+		return truncateSQLiteTables(db)
+	}
+
+	var tables []string
+	if err := db.Raw("SELECT tablename FROM pg_tables WHERE schemaname = 'public'").Scan(&tables).Error; err != nil {
+		return err
+	}
+	for _, table := range tables {
+		query := fmt.Sprintf("TRUNCATE TABLE %s RESTART IDENTITY CASCADE;", table)
+		if err := db.Exec(query).Error; err != nil {
+			return fmt.Errorf("deleting from %s: %w", table, err)
+		}
+	}
+	return nil
+}
+
+// SQLite has no TRUNCATE, so the rows are deleted with the foreign keys off. Deleting sqlite_sequence resets the ids.
+// The virtual and shadow tables of the search index are left to the triggers.
+func truncateSQLiteTables(db *gorm.DB) error {
+	var tables []string
+	if err := db.Raw("SELECT name FROM pragma_table_list WHERE schema = 'main' AND type = 'table' AND (name NOT LIKE 'sqlite_%' OR name = 'sqlite_sequence')").Scan(&tables).Error; err != nil {
+		return err
+	}
+
+	if err := db.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+		return err
+	}
+	defer db.Exec("PRAGMA foreign_keys = ON")
+
+	for _, table := range tables {
+		if err := db.Exec(fmt.Sprintf("DELETE FROM %q", table)).Error; err != nil {
+			return fmt.Errorf("deleting from %s: %w", table, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package infra
+
+import (
+	"flea-market/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDialector(t *testing.T) {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_NAME", "")
+	assert.Equal(t, DialectSQLite, newDialector().Name())
+	assert.Equal(t, ":memory:?_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)", sqliteDSN())
+
+	t.Setenv("DB_DRIVER", "")
+	assert.Equal(t, DialectPostgres, newDialector().Name())
+
+	t.Setenv("DB_DRIVER", "mysql")
+	assert.Panics(t, func() { newDialector() })
+}
+
+func setupSQLite(t *testing.T) {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_NAME", ":memory:")
+	t.Setenv("DB_CONNECT_TIMEOUT", "0")
+}
+
+func TestMigrate_SQLite(t *testing.T) {
+	setupSQLite(t)
+	db := SetupDB()
+	assert.NoError(t, Migrate(db))
+	// it can be run again
+	assert.NoError(t, Migrate(db))
+
+	user := models.User{Email: "test@test.com", Password: "password"}
+	assert.NoError(t, db.Create(&user).Error)
+	item := models.Item{Name: "Vintage camera", Price: 5000, Description: "film camera", UserID: user.ID}
+	assert.NoError(t, db.Create(&item).Error)
+
+	// the triggers keep the search index in sync
+	var ids []uint
+	assert.NoError(t, db.Raw("SELECT rowid FROM items_search WHERE items_search MATCH ?", `"film"*`).Scan(&ids).Error)
+	assert.Equal(t, []uint{item.ID}, ids)
+
+	assert.NoError(t, db.Model(&item).Update("description", "instant camera").Error)
+	var count int64
+	assert.NoError(t, db.Raw("SELECT count(*) FROM items_search WHERE items_search MATCH ?", `"film"*`).Scan(&count).Error)
+	assert.Zero(t, count)
+
+	// foreign keys are enforced
+	assert.Error(t, db.Create(&models.Item{Name: "orphan", Price: 100, UserID: 999}).Error)
+}
+
+func TestTruncateTables_SQLite(t *testing.T) {
+	setupSQLite(t)
+	db := SetupDB()
+	assert.NoError(t, Migrate(db))
+
+	user := models.User{Email: "test@test.com", Password: "password"}
+	assert.NoError(t, db.Create(&user).Error)
+	assert.NoError(t, db.Create(&models.Item{Name: "camera", Price: 5000, UserID: user.ID}).Error)
+
+	assert.NoError(t, TruncateTables(db))
+
+	var count int64
+	assert.NoError(t, db.Model(&models.Item{}).Count(&count).Error)
+	assert.Zero(t, count)
+	assert.NoError(t, db.Raw("SELECT count(*) FROM items_search").Scan(&count).Error)
+	assert.Zero(t, count)
+
+	// the ids start from 1 again
+	user = models.User{Email: "test@test.com", Password: "password"}
+	assert.NoError(t, db.Create(&user).Error)
+	assert.Equal(t, uint(1), user.ID)
+}
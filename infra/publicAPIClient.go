@@ -0,0 +1,53 @@
+package infra
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+// ErrNonPublicAddress is the error of a connection refused by NewPublicAPIClient.
+var ErrNonPublicAddress = errors.New("connecting to a non-public address is not allowed")
+
+// 100.64.0.0/10, used inside carrier networks
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+// NewPublicAPIClient is NewBaseAPIClient which connects only to public addresses. It's for URLs given by users,
+// so that they can't make the server call itself or the internal network.
+// The address is checked when connecting, after the name is resolved, so DNS rebinding and redirects can't bypass it.
+func NewPublicAPIClient() *resty.Client {
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second, Control: denyNonPublic}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	// a proxy would connect to the address instead of the dialer
+	transport.Proxy = nil
+	return NewBaseAPIClient().SetTransport(transport)
+}
+
+// denyNonPublic is the Control of net.Dialer, which is called with the resolved address of every connection.
+func denyNonPublic(network string, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(ip) {
+		return fmt.Errorf("%w: %s", ErrNonPublicAddress, ip)
+	}
+	return nil
+}
+
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	return !ip.IsLoopback() && !ip.IsPrivate() && !ip.IsLinkLocalUnicast() && !ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() && !ip.IsMulticast() && !ip.IsUnspecified() && !sharedAddressSpace.Contains(ip)
+}
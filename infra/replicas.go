@@ -0,0 +1,229 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"flea-market/utils"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+const (
+	replicaRouterName           = "replica_router"
+	defaultReadYourWritesWindow = 5 * time.Second
+	replicaHealthCheckInterval  = 5 * time.Second
+	replicaPingTimeout          = 2 * time.Second
+)
+
+type primaryKey struct{}
+
+// UsePrimary makes the reads with the returned context go to the primary,
+// for reads which must see the latest writes of other users.
+func UsePrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func usesPrimary(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryKey{}).(bool)
+	return forced
+}
+
+type replica struct {
+	name    string
+	pool    *sql.DB
+	healthy atomic.Bool
+}
+
+// ReplicaRouter is a GORM plugin which sends reads to the read replicas in round-robin, so that repositories don't have to choose.
+// These go to the primary instead:
+//   - reads in a transaction, which includes the locking reads
+//   - reads with UsePrimary
+//   - reads of a user for a short window after the same user wrote, so that the user reads their own writes
+//   - reads while no replica is healthy
+//
+// The window is kept in memory, so it covers only the writes handled by this process.
+type ReplicaRouter struct {
+	replicas []*replica
+	next     atomic.Uint64
+	window   time.Duration
+	now      func() time.Time
+
+	mu         sync.Mutex
+	lastWrites map[uint]time.Time
+	lastPrune  time.Time
+}
+
+func newReplicaRouter(pools []*sql.DB, window time.Duration) *ReplicaRouter {
+	r := &ReplicaRouter{window: window, now: time.Now, lastWrites: map[uint]time.Time{}}
+	for i, pool := range pools {
+		r.replicas = append(r.replicas, &replica{name: fmt.Sprintf("replica-%d", i+1), pool: pool})
+	}
+	return r
+}
+
+// ReplicaRouterOf returns the router used by db, or nil when no replica is configured.
+func ReplicaRouterOf(db *gorm.DB) *ReplicaRouter {
+	router, _ := db.Config.Plugins[replicaRouterName].(*ReplicaRouter)
+	return router
+}
+
+func (r *ReplicaRouter) Name() string {
+	return replicaRouterName
+}
+
+func (r *ReplicaRouter) Initialize(db *gorm.DB) error {
+	callbacks := db.Callback()
+	if err := callbacks.Query().Before("gorm:query").Register("replica_router:route", r.route); err != nil {
+		return err
+	}
+	if err := callbacks.Row().Before("gorm:row").Register("replica_router:route", r.route); err != nil {
+		return err
+	}
+	// registered last, so that they run after the writes
+	if err := callbacks.Create().Register("replica_router:written", r.written); err != nil {
+		return err
+	}
+	if err := callbacks.Update().Register("replica_router:written", r.written); err != nil {
+		return err
+	}
+	if err := callbacks.Delete().Register("replica_router:written", r.written); err != nil {
+		return err
+	}
+	if err := callbacks.Raw().Register("replica_router:written", r.written); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *ReplicaRouter) route(db *gorm.DB) {
+	if db.Error != nil {
+		return
+	}
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+		return
+	}
+	ctx := db.Statement.Context
+	if usesPrimary(ctx) || r.wroteRecently(ctx) {
+		return
+	}
+	if pool := r.pick(); pool != nil {
+		db.Statement.ConnPool = pool
+	}
+}
+
+// pick returns the next healthy replica, or nil when there is none.
+func (r *ReplicaRouter) pick() *sql.DB {
+	start := r.next.Add(1)
+	for i := range uint64(len(r.replicas)) {
+		replica := r.replicas[(start+i)%uint64(len(r.replicas))]
+		if replica.healthy.Load() {
+			return replica.pool
+		}
+	}
+	return nil
+}
+
+func (r *ReplicaRouter) written(db *gorm.DB) {
+	user, ok := utils.GetUserDataFromContext(db.Statement.Context)
+	if !ok {
+		return
+	}
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lastWrites[user.ID] = now
+	if now.Sub(r.lastPrune) > r.window {
+		for userId, writtenAt := range r.lastWrites {
+			if now.Sub(writtenAt) > r.window {
+				delete(r.lastWrites, userId)
+			}
+		}
+		r.lastPrune = now
+	}
+}
+
+func (r *ReplicaRouter) wroteRecently(ctx context.Context) bool {
+	user, ok := utils.GetUserDataFromContext(ctx)
+	if !ok {
+		return false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	writtenAt, ok := r.lastWrites[user.ID]
+	return ok && r.now().Sub(writtenAt) <= r.window
+}
+
+// Run checks the health of the replicas periodically until ctx is canceled.
+// It does nothing for a nil router, so that it can be called whether replicas are configured or not.
+func (r *ReplicaRouter) Run(ctx context.Context) {
+	if r == nil {
+		return
+	}
+	ticker := time.NewTicker(replicaHealthCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.checkHealth(ctx)
+		}
+	}
+}
+
+// checkHealth pings every replica and logs the replicas which went down or came back.
+func (r *ReplicaRouter) checkHealth(ctx context.Context) {
+	for _, replica := range r.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, replicaPingTimeout)
+		err := replica.pool.PingContext(pingCtx)
+		cancel()
+
+		healthy := err == nil
+		if replica.healthy.Swap(healthy) == healthy {
+			continue
+		}
+		if healthy {
+			utils.Logger(utils.DBReplicaRecovered, "", "", "", replica.name)
+		} else {
+			utils.Logger(utils.DBReplicaUnavailable, "", "", "", replica.name, err.Error())
+		}
+	}
+}
+
+// setupReplicaRouter opens the replicas in DB_REPLICA_DSNS, a comma separated list of DSNs.
+// It returns nil when the env is empty. A replica which can't be reached is skipped until it passes a health check.
+func setupReplicaRouter() *ReplicaRouter {
+	value := os.Getenv("DB_REPLICA_DSNS")
+	if value == "" {
+		return nil
+	}
+
+	window := durationEnv("DB_READ_YOUR_WRITES_WINDOW", defaultReadYourWritesWindow)
+
+	var pools []*sql.DB
+	for _, dsn := range strings.Split(value, ",") {
+		db, err := gorm.Open(postgres.Open(strings.TrimSpace(dsn)), &gorm.Config{DisableAutomaticPing: true})
+		if err != nil {
+			panic(utils.NewDBError("Opening read replica error", err).Error())
+		}
+		pool, err := db.DB()
+		if err != nil {
+			panic(utils.NewDBError("Opening read replica error", err).Error())
+		}
+		configurePool(pool)
+		pools = append(pools, pool)
+	}
+
+	router := newReplicaRouter(pools, window)
+	router.checkHealth(context.Background())
+	return router
+}
@@ -0,0 +1,157 @@
+package infra
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flea-market/models"
+	"flea-market/utils"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+var selectItemsSQL = regexp.QuoteMeta(`SELECT * FROM "items" WHERE "items"."deleted_at" IS NULL`)
+
+func setupReplicaRouterTest(t *testing.T, replicaCount int) (*gorm.DB, sqlmock.Sqlmock, []sqlmock.Sqlmock, *ReplicaRouter) {
+	primary, primaryMock, err := sqlmock.New()
+	assert.NoError(t, err)
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: primary}), &gorm.Config{})
+	assert.NoError(t, err)
+
+	var pools []*sql.DB
+	var replicaMocks []sqlmock.Sqlmock
+	for range replicaCount {
+		pool, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+		assert.NoError(t, err)
+		pools = append(pools, pool)
+		replicaMocks = append(replicaMocks, mock)
+	}
+	router := newReplicaRouter(pools, time.Second)
+	for _, replica := range router.replicas {
+		replica.healthy.Store(true)
+	}
+	assert.NoError(t, db.Use(router))
+	return db, primaryMock, replicaMocks, router
+}
+
+func findItems(db *gorm.DB, ctx context.Context) error {
+	var items []models.Item
+	return db.WithContext(ctx).Find(&items).Error
+}
+
+func userContext(userId uint) context.Context {
+	user := &models.User{Email: "test@test.com"}
+	user.ID = userId
+	return context.WithValue(context.Background(), utils.ContextUser, user)
+}
+
+func TestReplicaRouter_RoundRobin(t *testing.T) {
+	db, primary, replicas, router := setupReplicaRouterTest(t, 2)
+	for _, replica := range replicas {
+		replica.ExpectQuery(selectItemsSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	}
+
+	assert.NoError(t, findItems(db, context.Background()))
+	assert.NoError(t, findItems(db, context.Background()))
+
+	for _, replica := range replicas {
+		assert.NoError(t, replica.ExpectationsWereMet())
+	}
+	assert.NoError(t, primary.ExpectationsWereMet())
+	assert.Same(t, router, ReplicaRouterOf(db))
+}
+
+func TestReplicaRouter_Primary(t *testing.T) {
+	t.Run("UsePrimary", func(t *testing.T) {
+		db, primary, _, _ := setupReplicaRouterTest(t, 1)
+		primary.ExpectQuery(selectItemsSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		assert.NoError(t, findItems(db, UsePrimary(context.Background())))
+		assert.NoError(t, primary.ExpectationsWereMet())
+	})
+
+	t.Run("in a transaction", func(t *testing.T) {
+		db, primary, _, _ := setupReplicaRouterTest(t, 1)
+		primary.ExpectBegin()
+		primary.ExpectQuery(selectItemsSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		primary.ExpectCommit()
+
+		err := db.Transaction(func(tx *gorm.DB) error {
+			return findItems(tx, context.Background())
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, primary.ExpectationsWereMet())
+	})
+
+	t.Run("no healthy replica", func(t *testing.T) {
+		db, primary, _, router := setupReplicaRouterTest(t, 2)
+		for _, replica := range router.replicas {
+			replica.healthy.Store(false)
+		}
+		primary.ExpectQuery(selectItemsSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+		assert.NoError(t, findItems(db, context.Background()))
+		assert.NoError(t, primary.ExpectationsWereMet())
+	})
+}
+
+func TestReplicaRouter_ReadYourWrites(t *testing.T) {
+	db, primary, replicas, router := setupReplicaRouterTest(t, 1)
+	now := time.Now()
+	router.now = func() time.Time { return now }
+
+	primary.ExpectBegin()
+	primary.ExpectExec(regexp.QuoteMeta(`UPDATE "items" SET "price"=$1`)).WillReturnResult(sqlmock.NewResult(0, 1))
+	primary.ExpectCommit()
+	err := db.WithContext(userContext(1)).Model(&models.Item{}).Where("id = ?", 1).Update("price", 200).Error
+	assert.NoError(t, err)
+
+	// the writer reads the primary, and the others read the replica
+	primary.ExpectQuery(selectItemsSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	assert.NoError(t, findItems(db, userContext(1)))
+	replicas[0].ExpectQuery(selectItemsSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	assert.NoError(t, findItems(db, userContext(2)))
+
+	// after the window, the writer reads the replica too
+	now = now.Add(2 * time.Second)
+	replicas[0].ExpectQuery(selectItemsSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	assert.NoError(t, findItems(db, userContext(1)))
+
+	assert.NoError(t, primary.ExpectationsWereMet())
+	assert.NoError(t, replicas[0].ExpectationsWereMet())
+}
+
+func TestReplicaRouter_CheckHealth(t *testing.T) {
+	db, primary, replicas, router := setupReplicaRouterTest(t, 2)
+
+	replicas[0].ExpectPing().WillReturnError(errors.New("connection refused"))
+	replicas[1].ExpectPing()
+	router.checkHealth(context.Background())
+
+	assert.False(t, router.replicas[0].healthy.Load())
+	assert.True(t, router.replicas[1].healthy.Load())
+
+	// only the healthy replica is used
+	replicas[1].ExpectQuery(selectItemsSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	replicas[1].ExpectQuery(selectItemsSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	assert.NoError(t, findItems(db, context.Background()))
+	assert.NoError(t, findItems(db, context.Background()))
+
+	// and the replica comes back after it responds
+	replicas[0].ExpectPing()
+	replicas[1].ExpectPing()
+	router.checkHealth(context.Background())
+	assert.True(t, router.replicas[0].healthy.Load())
+
+	for _, replica := range replicas {
+		assert.NoError(t, replica.ExpectationsWereMet())
+	}
+	assert.NoError(t, primary.ExpectationsWereMet())
+}
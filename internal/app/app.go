@@ -1,22 +1,124 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"flea-market/infra"
+	"flea-market/internal/jobs"
+	"flea-market/internal/outbox"
+	"flea-market/repositories"
+	"flea-market/services"
+	"flea-market/utils"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+const (
+	shutdownTimeout    = 10 * time.Second
+	offerSweepInterval = time.Minute
+)
+
 type App struct {
-	engine *gin.Engine
+	engine       *gin.Engine
+	hub          *services.NotificationHub
+	offerSweeper *services.OfferSweeper
+	jobQueue     jobs.IEnqueuer
+	jobPool      *jobs.Pool
+	outboxRelay  *outbox.Relay
+	// nil without replicas
+	replicaRouter *infra.ReplicaRouter
 }
 
 func NewApp() *App {
 	infra.Initializer()
 	db := infra.SetupDB()
-	engine := NewRouter(db)
-	return &App{engine: engine}
+	hub := services.NewNotificationHub()
+	engine := newRouter(db, hub)
+	offerSweeper := services.NewOfferSweeper(repositories.NewOfferRepository(db), offerSweepInterval)
+	jobRepository := repositories.NewJobRepository(db)
+	jobPool := jobs.NewPool(jobRepository, newJobRegistry(db, jobRepository), jobWorkers(defaultAPIJobWorkers))
+	outboxRelay := outbox.NewRelay(repositories.NewOutboxRepository(db), newOutboxPublisher(), outboxRelayInterval)
+	return &App{
+		engine:        engine,
+		hub:           hub,
+		offerSweeper:  offerSweeper,
+		jobQueue:      jobRepository,
+		jobPool:       jobPool,
+		outboxRelay:   outboxRelay,
+		replicaRouter: infra.ReplicaRouterOf(db),
+	}
 }
 
+// Run serves until SIGINT/SIGTERM is received and then shuts down gracefully.
 func (a *App) Run() error {
-	return a.engine.Run()
+	server := &http.Server{
+		Addr:    ":" + port(),
+		Handler: a.engine,
+	}
+	// Streaming connections(SSE) never become idle, so they have to be closed on shutdown.
+	server.RegisterOnShutdown(a.hub.Close)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	scheduleRecurringJobs(ctx, a.jobQueue)
+
+	// background workers stop when ctx is canceled
+	var workers sync.WaitGroup
+	workers.Add(4)
+	go func() {
+		defer workers.Done()
+		a.offerSweeper.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		a.jobPool.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		a.outboxRelay.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		a.replicaRouter.Run(ctx)
+	}()
+
+	errCh := make(chan error, 1)
+	go func() {
+		utils.Logger(utils.GenericMessage, "", "", "", "Listening on "+server.Addr)
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errCh <- err
+		}
+		close(errCh)
+	}()
+
+	select {
+	case err := <-errCh:
+		stop()
+		workers.Wait()
+		return err
+	case <-ctx.Done():
+	}
+
+	utils.Logger(utils.GenericMessage, "", "", "", "Shutting down server")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	err := server.Shutdown(shutdownCtx)
+	workers.Wait()
+	return err
+}
+
+// same as gin.Engine.Run()
+func port() string {
+	if p := os.Getenv("PORT"); p != "" {
+		return p
+	}
+	return "8080"
 }
@@ -0,0 +1,20 @@
+package app
+
+import (
+	"flea-market/internal/i18n"
+	"sync"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var registerValidatorOnce sync.Once
+
+// registerValidator makes the validation errors translatable. The validator of gin is global, so it's registered only once.
+func registerValidator() {
+	registerValidatorOnce.Do(func() {
+		if err := i18n.RegisterValidator(binding.Validator.Engine().(*validator.Validate)); err != nil {
+			panic("registering the validator failed: " + err.Error())
+		}
+	})
+}
@@ -0,0 +1,83 @@
+package app
+
+import (
+	"context"
+	"flea-market/infra"
+	"flea-market/internal/jobs"
+	"flea-market/repositories"
+	"flea-market/services"
+	"flea-market/utils"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	// the API serves requests first, and cmd/worker is run for heavier loads
+	defaultAPIJobWorkers    = 1
+	defaultWorkerJobWorkers = 4
+
+	purgeIdempotencyKeysInterval = time.Hour
+
+	webhookTimeout = 10 * time.Second
+)
+
+// purgeIdempotencyKeysJob deletes expired Idempotency-Key records and schedules its next run.
+var purgeIdempotencyKeysJob = jobs.Type[struct{}]{Kind: "purge_idempotency_keys", MaxAttempts: 3}
+
+// newJobRegistry registers the handler of every kind of job. The API and cmd/worker share it.
+func newJobRegistry(db *gorm.DB, queue jobs.IEnqueuer) *jobs.Registry {
+	idempotencyService := services.NewIdempotencyService(repositories.NewIdempotencyRepository(db))
+	webhookService := services.NewWebhookService(repositories.NewWebhookRepository(db), newWebhookClient(), queue)
+
+	registry := jobs.NewRegistry()
+	jobs.Register(registry, purgeIdempotencyKeysJob, func(ctx context.Context, _ struct{}) error {
+		count, err := idempotencyService.PurgeExpired(ctx)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			utils.Logger(utils.GenericMessage, "", "", "", fmt.Sprintf("Purged %d idempotency keys", count))
+		}
+		_, _, err = jobs.Enqueue(ctx, queue, purgeIdempotencyKeysJob, struct{}{},
+			jobs.RunAt(time.Now().Add(purgeIdempotencyKeysInterval)), jobs.Unique(purgeIdempotencyKeysJob.Kind))
+		return err
+	})
+	jobs.Register(registry, services.DeliverWebhookJob, webhookService.Deliver)
+	return registry
+}
+
+// newWebhookClient is the client calling the webhooks of users, which may not respond.
+// The URLs are given by users, so only public addresses are called unless WEBHOOK_ALLOW_PRIVATE_NETWORKS is true, e.g. in development.
+func newWebhookClient() *resty.Client {
+	if os.Getenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS") == "true" {
+		return infra.NewBaseAPIClient().SetTimeout(webhookTimeout)
+	}
+	return infra.NewPublicAPIClient().SetTimeout(webhookTimeout)
+}
+
+// scheduleRecurringJobs enqueues the first run of the jobs which schedule themselves.
+// It's called on every start, and the unique keys skip the jobs already queued.
+func scheduleRecurringJobs(ctx context.Context, queue jobs.IEnqueuer) {
+	_, _, err := jobs.Enqueue(ctx, queue, purgeIdempotencyKeysJob, struct{}{}, jobs.Unique(purgeIdempotencyKeysJob.Kind))
+	if err != nil {
+		utils.Logger(utils.DBError, "", "", "", err.Error())
+	}
+}
+
+// jobWorkers is the number of job workers in this process, set by JOB_WORKERS. 0 disables them.
+func jobWorkers(defaultCount int) int {
+	value := os.Getenv("JOB_WORKERS")
+	if value == "" {
+		return defaultCount
+	}
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		panic("env JOB_WORKERS must be a non-negative integer: " + value)
+	}
+	return count
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"flea-market/internal/jwtauth"
+	"flea-market/utils"
+	"os"
+	"time"
+)
+
+const tokenTTL = time.Hour
+
+// NewTokenIssuer signs the access tokens by the keyset in JWT_KEYS_FILE, or by HS256 with SECRET_KEY when it isn't set,
+// which is for development and is warned about.
+// The tests use it to make the tokens which the router accepts.
+func NewTokenIssuer() *jwtauth.Issuer {
+	var keys *jwtauth.KeySet
+	if path := os.Getenv("JWT_KEYS_FILE"); path != "" {
+		read, err := jwtauth.ReadKeySet(path)
+		if err != nil {
+			panic("env JWT_KEYS_FILE is invalid: " + err.Error())
+		}
+		keys = read
+	} else {
+		secret := os.Getenv("SECRET_KEY")
+		if secret == "" {
+			panic("env JWT_KEYS_FILE or SECRET_KEY must be set")
+		}
+		keys = jwtauth.NewSecretKeySet([]byte(secret))
+		// a shared secret can't be rotated without logging everyone out, and every service which verifies the tokens can forge them
+		utils.Logger(utils.JWTSecretKeyFallback, "", "", "")
+	}
+
+	config := jwtauth.Config{
+		Issuer:    stringEnv("JWT_ISSUER", "flea-market"),
+		Audience:  stringEnv("JWT_AUDIENCE", "flea-market"),
+		TTL:       tokenTTL,
+		ClockSkew: 30 * time.Second,
+	}
+	if value := os.Getenv("JWT_CLOCK_SKEW"); value != "" {
+		skew, err := time.ParseDuration(value)
+		if err != nil || skew < 0 {
+			panic("env JWT_CLOCK_SKEW must be a non-negative duration: " + value)
+		}
+		config.ClockSkew = skew
+	}
+	return jwtauth.NewIssuer(keys, config)
+}
+
+func stringEnv(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
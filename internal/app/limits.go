@@ -0,0 +1,44 @@
+package app
+
+import (
+	"flea-market/models"
+	"flea-market/services"
+	"maps"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// newPlanLimits starts from services.DefaultPlanLimits and overrides each plan by ITEM_LIMITS_<PLAN>,
+// e.g. ITEM_LIMITS_FREE="maxActiveListings=50,maxCreatesPerDay=10,maxPrice=100000". Omitted limits keep the default.
+func newPlanLimits() map[models.Plan]models.ItemLimits {
+	plans := maps.Clone(services.DefaultPlanLimits)
+	for plan, limits := range plans {
+		key := "ITEM_LIMITS_" + strings.ToUpper(string(plan))
+		if value := os.Getenv(key); value != "" {
+			plans[plan] = parsePlanLimits(key, value, limits)
+		}
+	}
+	return plans
+}
+
+func parsePlanLimits(key string, value string, limits models.ItemLimits) models.ItemLimits {
+	for _, pair := range strings.Split(value, ",") {
+		name, number, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		n, err := strconv.ParseUint(number, 10, 0)
+		if !ok || err != nil {
+			panic("env " + key + " must be like maxActiveListings=100,maxCreatesPerDay=30,maxPrice=300000: " + value)
+		}
+		switch name {
+		case "maxActiveListings":
+			limits.MaxActiveListings = uint(n)
+		case "maxCreatesPerDay":
+			limits.MaxCreatesPerDay = uint(n)
+		case "maxPrice":
+			limits.MaxPrice = uint(n)
+		default:
+			panic("env " + key + " has an unknown limit: " + name)
+		}
+	}
+	return limits
+}
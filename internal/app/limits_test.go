@@ -0,0 +1,26 @@
+package app
+
+import (
+	"flea-market/models"
+	"flea-market/services"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPlanLimits(t *testing.T) {
+	t.Setenv("ITEM_LIMITS_FREE", "maxActiveListings=5, maxPrice=1000")
+
+	plans := newPlanLimits()
+
+	free := services.DefaultPlanLimits[models.PlanFree]
+	assert.Equal(t, models.ItemLimits{MaxActiveListings: 5, MaxCreatesPerDay: free.MaxCreatesPerDay, MaxPrice: 1000}, plans[models.PlanFree])
+	assert.Equal(t, services.DefaultPlanLimits[models.PlanPro], plans[models.PlanPro])
+	// the defaults aren't changed
+	assert.Equal(t, free, services.DefaultPlanLimits[models.PlanFree])
+
+	t.Setenv("ITEM_LIMITS_PRO", "maxItems=5")
+	assert.PanicsWithValue(t, "env ITEM_LIMITS_PRO has an unknown limit: maxItems", func() { newPlanLimits() })
+	t.Setenv("ITEM_LIMITS_PRO", "maxPrice=-1")
+	assert.Panics(t, func() { newPlanLimits() })
+}
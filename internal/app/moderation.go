@@ -0,0 +1,24 @@
+package app
+
+import (
+	"flea-market/internal/moderation"
+	"flea-market/internal/money"
+	"os"
+)
+
+// newModerator moderates items by the rules in MODERATION_RULES_FILE, or by the default rules.
+func newModerator(rates money.RateProvider) moderation.Moderator {
+	rules := moderation.DefaultRules()
+	if path := os.Getenv("MODERATION_RULES_FILE"); path != "" {
+		read, err := moderation.ReadRules(path)
+		if err != nil {
+			panic("env MODERATION_RULES_FILE is invalid: " + err.Error())
+		}
+		rules = *read
+	}
+	moderator, err := moderation.NewRuleModerator(rules, rates)
+	if err != nil {
+		panic("moderation rules are invalid: " + err.Error())
+	}
+	return moderator
+}
@@ -0,0 +1,107 @@
+package app
+
+import (
+	"flea-market/dto"
+	"flea-market/internal/jwtauth"
+	"flea-market/internal/openapi"
+	"flea-market/repositories"
+	"net/http"
+)
+
+const apiVersion = "1.0.0"
+
+// the body of v1 login, which isn't in the envelope
+type loginResponse struct {
+	Token string `json:"token"`
+}
+
+// operations documents every route of newRouter. A route without an entry fails the OpenAPI test.
+var operations = openapi.Operations{
+	"GET /openapi.json":      {Summary: "OpenAPI document", Tag: "docs", Response: map[string]any{}, Unwrapped: true, Unversioned: true},
+	"GET /docs":              {Summary: "Swagger UI", Tag: "docs", Unversioned: true},
+	"GET /docs/assets/:file": {Summary: "Bundled files of Swagger UI", Tag: "docs", Response: "", Unwrapped: true, ContentType: "text/javascript", Errors: []int{http.StatusNotFound}, Unversioned: true},
+	// RFC 7517, so the other services can verify the access tokens
+	"GET /.well-known/jwks.json": {Summary: "Public keys of the access tokens", Tag: "auth", Response: jwtauth.JWKS{}, Unwrapped: true, Unversioned: true},
+
+	// the primary first, then the replicas
+	"GET /diagnostics/db": {Summary: "Connection pool stats of the primary and the replicas", Tag: "diagnostics", Auth: true, Response: []dto.DBPoolStatsResponse{}, Unversioned: true, Errors: []int{http.StatusForbidden}},
+
+	"GET /items":        {Summary: "List unsold items", Tag: "items", Query: dto.ItemQuery{}, Response: []dto.LegacyItemResponse{}, Legacy: true},
+	"GET /items/search": {Summary: "Full-text search of items", Tag: "items", Query: dto.ItemSearchQuery{}, Response: []dto.LegacyItemSearchResultResponse{}, Legacy: true},
+	"GET /items/:id":    {Summary: "Find an item", Tag: "items", Auth: true, Response: dto.LegacyItemResponse{}, Legacy: true, Errors: []int{http.StatusNotFound}},
+	"POST /items":       {Summary: "Create an item within the limits of the plan", Tag: "items", Auth: true, Request: dto.CreateItemInput{}, Response: dto.LegacyItemResponse{}, Legacy: true, Status: http.StatusCreated, Errors: []int{http.StatusForbidden, http.StatusTooManyRequests}},
+	"PUT /items/:id":    {Summary: "Update an item", Tag: "items", Auth: true, Request: dto.UpdateItemInput{}, Response: dto.LegacyItemResponse{}, Legacy: true, Errors: []int{http.StatusNotFound}},
+	"DELETE /items/:id": {Summary: "Delete an item", Tag: "items", Auth: true, Errors: []int{http.StatusNotFound}},
+
+	"POST /items/bulk":   {Summary: "Create items with a result per entry", Tag: "items", Auth: true, Query: dto.BulkQuery{}, Request: dto.BulkCreateItemsInput{}, Response: []dto.BulkItemResultResponse{}, Status: http.StatusMultiStatus},
+	"PATCH /items/bulk":  {Summary: "Update price and soldOut of items with a result per entry", Tag: "items", Auth: true, Query: dto.BulkQuery{}, Request: dto.BulkUpdateItemsInput{}, Response: []dto.BulkItemResultResponse{}, Status: http.StatusMultiStatus},
+	"DELETE /items/bulk": {Summary: "Delete items with a result per entry", Tag: "items", Auth: true, Query: dto.BulkQuery{}, Request: dto.BulkDeleteItemsInput{}, Response: []dto.BulkItemResultResponse{}, Status: http.StatusMultiStatus},
+
+	"POST /items/:id/offers": {Summary: "Make an offer", Tag: "offers", Auth: true, Request: dto.CreateOfferInput{}, Response: dto.LegacyOfferResponse{}, Legacy: true, Status: http.StatusCreated, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+	"GET /items/:id/offers":  {Summary: "List offers of an item", Tag: "offers", Auth: true, Response: []dto.LegacyOfferResponse{}, Legacy: true, Errors: []int{http.StatusNotFound}},
+	// 3 open reports hide the item until an admin reviews it
+	"POST /items/:id/report": {Summary: "Report another user's item to admins", Tag: "moderation", Auth: true, Request: dto.ReportItemInput{}, Response: dto.ItemReportResponse{}, Status: http.StatusCreated, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+
+	"POST /offers/:id/accept":   {Summary: "Accept an offer and reserve the item", Tag: "offers", Auth: true, Response: dto.LegacyOfferResponse{}, Legacy: true, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+	"POST /offers/:id/decline":  {Summary: "Decline an offer", Tag: "offers", Auth: true, Response: dto.LegacyOfferResponse{}, Legacy: true, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+	"POST /offers/:id/counter":  {Summary: "Counter an offer", Tag: "offers", Auth: true, Request: dto.CounterOfferInput{}, Response: dto.LegacyOfferResponse{}, Legacy: true, Status: http.StatusCreated, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+	"POST /offers/:id/withdraw": {Summary: "Withdraw an offer", Tag: "offers", Auth: true, Response: dto.LegacyOfferResponse{}, Legacy: true, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+
+	"POST /items/:id/purchase":    {Summary: "Purchase an item", Tag: "purchases", Auth: true, Response: dto.LegacyPurchaseResponse{}, Legacy: true, Status: http.StatusCreated, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+	"POST /purchases/:id/reviews": {Summary: "Review the other side of a purchase", Tag: "reviews", Auth: true, Request: dto.CreateReviewInput{}, Response: dto.LegacyReviewResponse{}, Legacy: true, Status: http.StatusCreated, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+	"PUT /reviews/:id":            {Summary: "Edit a review", Tag: "reviews", Auth: true, Request: dto.UpdateReviewInput{}, Response: dto.LegacyReviewResponse{}, Legacy: true, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+	"GET /users/:id":              {Summary: "Public profile of a user", Tag: "users", Response: dto.UserPublicResponse{}, Legacy: true, Errors: []int{http.StatusNotFound}},
+	"GET /users/:id/reviews":      {Summary: "Reviews received by a user", Tag: "reviews", Response: []dto.LegacyReviewResponse{}, Legacy: true},
+
+	"GET /categories": {Summary: "Category tree with item counts", Tag: "categories", Response: []dto.CategoryTreeNode{}, Legacy: true},
+
+	"POST /auth/signup": {Summary: "Sign up", Tag: "auth", Request: dto.SignupInput{}, Status: http.StatusCreated, Errors: []int{http.StatusConflict}},
+	"POST /auth/login":  {Summary: "Log in and get a JWT", Tag: "auth", Request: dto.LoginInput{}, Response: loginResponse{}, Unwrapped: true, Errors: []int{http.StatusUnauthorized}},
+
+	"GET /external":              {Summary: "Posts from the external API", Tag: "external", Response: []repositories.Post{}, Legacy: true},
+	"GET /external/user/:userId": {Summary: "A user and their posts from the external API", Tag: "external", Response: repositories.UserAndPosts{}, Legacy: true},
+
+	// each event's data is a Notification
+	"GET /me/events": {Summary: "Stream notifications over Server-Sent Events", Tag: "notifications", Auth: true, Response: "", Unwrapped: true, ContentType: "text/event-stream"},
+	"GET /me/limits": {Summary: "My listing limits and how much of them is used", Tag: "items", Auth: true, Response: dto.ItemLimitStatusResponse{}},
+	// format=jsonl streams one ItemResponse per line as application/x-ndjson
+	"GET /me/items/export": {Summary: "Download all of my items as CSV or JSON Lines", Tag: "items", Auth: true, Query: dto.ItemExportQuery{}, Response: "", Unwrapped: true, ContentType: "text/csv"},
+	// 200 for a dry run, 422 with the same body when a row is invalid, and report=csv responds the errors as a CSV file
+	"POST /me/items/import": {Summary: "Create items from a CSV file validated row by row", Tag: "items", Auth: true, Query: dto.ItemImportQuery{}, Upload: "file", Response: dto.ItemImportReport{}, Status: http.StatusCreated},
+
+	"GET /me/webhooks":                {Summary: "List my webhooks", Tag: "webhooks", Auth: true, Response: []dto.WebhookResponse{}},
+	"POST /me/webhooks":               {Summary: "Create a webhook. The secret is included only in this response", Tag: "webhooks", Auth: true, Request: dto.CreateWebhookInput{}, Response: dto.WebhookResponse{}, Status: http.StatusCreated},
+	"DELETE /me/webhooks/:id":         {Summary: "Delete a webhook", Tag: "webhooks", Auth: true, Errors: []int{http.StatusNotFound}},
+	"GET /me/webhooks/:id/deliveries": {Summary: "Latest delivery attempts of a webhook", Tag: "webhooks", Auth: true, Response: []dto.WebhookDeliveryResponse{}, Errors: []int{http.StatusNotFound}},
+	// responds 200 even when the webhook fails, with the status code and error in the body
+	"POST /me/webhooks/:id/test": {Summary: "Deliver a ping event to a webhook", Tag: "webhooks", Auth: true, Response: dto.WebhookDeliveryResponse{}, Errors: []int{http.StatusNotFound}},
+
+	// only for admins, others get 403
+	"GET /admin/users/:id/limits":      {Summary: "Listing limits of a user", Tag: "admin", Auth: true, Response: dto.ItemLimitStatusResponse{}, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+	"PUT /admin/users/:id/limits":      {Summary: "Change the plan of a user and override the limits of the plan", Tag: "admin", Auth: true, Request: dto.UpdateUserLimitsInput{}, Response: dto.ItemLimitStatusResponse{}, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+	"DELETE /admin/users/:id/limits":   {Summary: "Reset the limits of a user to those of the plan", Tag: "admin", Auth: true, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+	"GET /admin/moderation/queue":      {Summary: "Items which are pending or reported, from the oldest", Tag: "admin", Auth: true, Query: dto.ModerationQueueQuery{}, Response: []dto.ModerationQueueEntryResponse{}, Errors: []int{http.StatusForbidden}},
+	"POST /admin/moderation/items/:id": {Summary: "Approve or reject an item and resolve its reports", Tag: "admin", Auth: true, Request: dto.ModerationDecisionInput{}, Response: dto.ItemResponse{}, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+
+	// the v2 overrides of newV2Overrides, which respond with the response DTOs in the envelope
+	"GET /v2/items":                  {Summary: "List unsold items", Tag: "items", Query: dto.ItemQuery{}, Response: []dto.ItemResponse{}},
+	"GET /v2/items/search":           {Summary: "Full-text search of items", Tag: "items", Query: dto.ItemSearchQuery{}, Response: []dto.ItemSearchResultResponse{}},
+	"GET /v2/items/:id":              {Summary: "Find an item", Tag: "items", Auth: true, Response: dto.ItemResponse{}, Errors: []int{http.StatusNotFound}},
+	"POST /v2/items":                 {Summary: "Create an item within the limits of the plan", Tag: "items", Auth: true, Request: dto.CreateItemInput{}, Response: dto.ItemResponse{}, Status: http.StatusCreated, Errors: []int{http.StatusForbidden, http.StatusTooManyRequests}},
+	"PUT /v2/items/:id":              {Summary: "Update an item", Tag: "items", Auth: true, Request: dto.UpdateItemInput{}, Response: dto.ItemResponse{}, Errors: []int{http.StatusNotFound}},
+	"POST /v2/items/:id/offers":      {Summary: "Make an offer", Tag: "offers", Auth: true, Request: dto.CreateOfferInput{}, Response: dto.OfferResponse{}, Status: http.StatusCreated, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+	"GET /v2/items/:id/offers":       {Summary: "List offers of an item", Tag: "offers", Auth: true, Response: []dto.OfferResponse{}, Errors: []int{http.StatusNotFound}},
+	"POST /v2/offers/:id/accept":     {Summary: "Accept an offer and reserve the item", Tag: "offers", Auth: true, Response: dto.OfferResponse{}, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+	"POST /v2/offers/:id/decline":    {Summary: "Decline an offer", Tag: "offers", Auth: true, Response: dto.OfferResponse{}, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+	"POST /v2/offers/:id/counter":    {Summary: "Counter an offer", Tag: "offers", Auth: true, Request: dto.CounterOfferInput{}, Response: dto.OfferResponse{}, Status: http.StatusCreated, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+	"POST /v2/offers/:id/withdraw":   {Summary: "Withdraw an offer", Tag: "offers", Auth: true, Response: dto.OfferResponse{}, Errors: []int{http.StatusForbidden, http.StatusNotFound, http.StatusConflict}},
+	"POST /v2/items/:id/purchase":    {Summary: "Purchase an item", Tag: "purchases", Auth: true, Response: dto.PurchaseResponse{}, Status: http.StatusCreated, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+	"POST /v2/purchases/:id/reviews": {Summary: "Review the other side of a purchase", Tag: "reviews", Auth: true, Request: dto.CreateReviewInput{}, Response: dto.ReviewResponse{}, Status: http.StatusCreated, Errors: []int{http.StatusNotFound, http.StatusConflict}},
+	"PUT /v2/reviews/:id":            {Summary: "Edit a review", Tag: "reviews", Auth: true, Request: dto.UpdateReviewInput{}, Response: dto.ReviewResponse{}, Errors: []int{http.StatusForbidden, http.StatusNotFound}},
+	"GET /v2/users/:id":              {Summary: "Public profile of a user", Tag: "users", Response: dto.UserPublicResponse{}, Errors: []int{http.StatusNotFound}},
+	"GET /v2/users/:id/reviews":      {Summary: "Reviews received by a user", Tag: "reviews", Response: []dto.ReviewResponse{}},
+	"GET /v2/categories":             {Summary: "Category tree with item counts", Tag: "categories", Response: []dto.CategoryTreeNode{}},
+	"POST /v2/auth/login":            {Summary: "Log in and get a JWT", Tag: "auth", Request: dto.LoginInput{}, Response: dto.TokenResponse{}, Errors: []int{http.StatusUnauthorized}},
+	"GET /v2/external":               {Summary: "Posts from the external API", Tag: "external", Response: []repositories.Post{}},
+	"GET /v2/external/user/:userId":  {Summary: "A user and their posts from the external API", Tag: "external", Response: repositories.UserAndPosts{}},
+}
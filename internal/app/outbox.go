@@ -0,0 +1,32 @@
+package app
+
+import (
+	"flea-market/infra"
+	"flea-market/internal/outbox"
+	"os"
+	"time"
+)
+
+const (
+	outboxRelayInterval    = time.Second
+	outboxWebhookTimeout   = 10 * time.Second
+	outboxPublisherLog     = "log"
+	outboxPublisherWebhook = "webhook"
+)
+
+// newOutboxPublisher selects the publisher by OUTBOX_PUBLISHER, "log" (default) or "webhook".
+// The webhook publisher POSTs events to OUTBOX_WEBHOOK_URL.
+func newOutboxPublisher() outbox.Publisher {
+	switch publisher := os.Getenv("OUTBOX_PUBLISHER"); publisher {
+	case "", outboxPublisherLog:
+		return outbox.LogPublisher{}
+	case outboxPublisherWebhook:
+		url := os.Getenv("OUTBOX_WEBHOOK_URL")
+		if url == "" {
+			panic("env OUTBOX_WEBHOOK_URL is not set")
+		}
+		return outbox.NewWebhookPublisher(infra.NewBaseAPIClient().SetTimeout(outboxWebhookTimeout), url)
+	default:
+		panic("env OUTBOX_PUBLISHER must be log or webhook: " + publisher)
+	}
+}
@@ -0,0 +1,54 @@
+package app
+
+import (
+	"flea-market/internal/password"
+	"os"
+	"strconv"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newPasswordHasher hashes new passwords by PASSWORD_HASHER, argon2id by default or bcrypt, and verifies the hashes of both,
+// so that changing it upgrades the hashes on login. ARGON2ID_PARAMS like "m=65536,t=3,p=2" and BCRYPT_COST set the costs.
+func newPasswordHasher() password.Hasher {
+	params := password.DefaultArgon2idParams
+	if value := os.Getenv("ARGON2ID_PARAMS"); value != "" {
+		parsed, err := password.ParseArgon2idParams(value, params)
+		if err != nil {
+			panic("env ARGON2ID_PARAMS is invalid: " + err.Error())
+		}
+		params = parsed
+	}
+	cost := bcrypt.DefaultCost
+	if value := os.Getenv("BCRYPT_COST"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < bcrypt.MinCost || parsed > bcrypt.MaxCost {
+			panic("env BCRYPT_COST must be an integer from 4 to 31: " + value)
+		}
+		cost = parsed
+	}
+
+	argon2id := password.NewArgon2id(params)
+	bcryptHasher := password.NewBcrypt(cost)
+	switch value := os.Getenv("PASSWORD_HASHER"); value {
+	case "", "argon2id":
+		return password.NewChain(argon2id, bcryptHasher)
+	case "bcrypt":
+		return password.NewChain(bcryptHasher, argon2id)
+	default:
+		panic("env PASSWORD_HASHER must be argon2id or bcrypt: " + value)
+	}
+}
+
+// newPasswordPolicy rejects the passwords in BREACHED_PASSWORDS_FILE, or in the default list.
+func newPasswordPolicy() *password.Policy {
+	breached := password.DefaultBreached()
+	if path := os.Getenv("BREACHED_PASSWORDS_FILE"); path != "" {
+		read, err := password.ReadBreached(path)
+		if err != nil {
+			panic("env BREACHED_PASSWORDS_FILE is invalid: " + err.Error())
+		}
+		breached = read
+	}
+	return password.NewPolicy(password.DefaultMinLength, password.DefaultMaxLength, breached)
+}
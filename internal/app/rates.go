@@ -0,0 +1,18 @@
+package app
+
+import (
+	"flea-market/internal/money"
+	"os"
+)
+
+// newRateProvider converts prices by the rates in EXCHANGE_RATES_FILE, or by the default rates.
+func newRateProvider() money.RateProvider {
+	if path := os.Getenv("EXCHANGE_RATES_FILE"); path != "" {
+		rates, err := money.ReadRates(path)
+		if err != nil {
+			panic("env EXCHANGE_RATES_FILE is invalid: " + err.Error())
+		}
+		return rates
+	}
+	return money.DefaultRates()
+}
@@ -3,23 +3,82 @@ package app
 import (
 	"flea-market/controllers"
 	"flea-market/infra"
+	"flea-market/internal/jwtauth"
+	"flea-market/internal/openapi"
 	"flea-market/middlewares"
 	"flea-market/repositories"
 	"flea-market/services"
+	"slices"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/gorm"
 )
 
+var (
+	legacyDeprecatedAt = time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC)
+	legacySunset       = time.Date(2027, time.May, 1, 0, 0, 0, 0, time.UTC)
+)
+
 func NewRouter(db *gorm.DB) *gin.Engine {
+	return newRouter(db, services.NewNotificationHub())
+}
+
+// hub is passed from App so that it can be closed on shutdown.
+func newRouter(db *gorm.DB, hub *services.NotificationHub) *gin.Engine {
+	registerValidator()
+
+	jobRepository := repositories.NewJobRepository(db)
+
+	webhookRepository := repositories.NewWebhookRepository(db)
+	webhookService := services.NewWebhookService(webhookRepository, newWebhookClient(), jobRepository)
+	webhookController := controllers.NewWebhookController(webhookService)
+
+	notificationRepository := repositories.NewNotificationRepository(db)
+	notificationService := services.NewNotificationService(notificationRepository, hub, webhookService)
+	notificationController := controllers.NewNotificationController(notificationService)
+
+	userRepository := repositories.NewUserRepository(db)
+	userService := services.NewUserService(userRepository)
+	userController := controllers.NewUserController(userService)
+
+	rates := newRateProvider()
+	itemLimitService := services.NewItemLimitService(repositories.NewItemLimitRepository(db), userRepository, repositories.NewTxManager(db), newPlanLimits(), rates)
+	itemLimitController := controllers.NewItemLimitController(itemLimitService)
+
 	itemRepository := repositories.NewItemRepository(db)
-	itemService := services.NewItemService(itemRepository)
+	itemService := services.NewItemService(itemRepository, repositories.NewTxManager(db), itemLimitService, newModerator(rates), rates)
 	itemController := controllers.NewItemController(itemService)
 
+	moderationService := services.NewModerationService(repositories.NewModerationRepository(db), repositories.NewTxManager(db))
+	moderationController := controllers.NewModerationController(moderationService)
+
+	offerRepository := repositories.NewOfferRepository(db)
+	offerService := services.NewOfferService(offerRepository, itemRepository, notificationService)
+	offerController := controllers.NewOfferController(offerService)
+
+	purchaseRepository := repositories.NewPurchaseRepository(db)
+	purchaseService := services.NewPurchaseService(purchaseRepository, itemRepository, notificationService)
+	purchaseController := controllers.NewPurchaseController(purchaseService)
+
+	reviewRepository := repositories.NewReviewRepository(db)
+	reviewService := services.NewReviewService(reviewRepository, purchaseRepository)
+	reviewController := controllers.NewReviewController(reviewService)
+
+	categoryRepository := repositories.NewCategoryRepository(db)
+	categoryService := services.NewCategoryService(categoryRepository)
+	categoryController := controllers.NewCategoryController(categoryService)
+
 	authRepository := repositories.NewAuthRepository(db)
-	authService := services.NewAuthService(authRepository)
+	tokenIssuer := NewTokenIssuer()
+	authService := services.NewAuthService(authRepository, newPasswordHasher(), newPasswordPolicy(), tokenIssuer)
 	authController := controllers.NewAuthController(authService)
 
+	idempotencyRepository := repositories.NewIdempotencyRepository(db)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepository)
+
+	diagnosticsController := controllers.NewDiagnosticsController(services.NewDiagnosticsService(repositories.NewDiagnosticsRepository(db)))
+
 	apiClient := infra.NewBaseAPIClient()
 	apiCallRepository := repositories.NewAPICallRepository(apiClient)
 	apiCallService := services.NewAPICallService(apiCallRepository)
@@ -30,22 +89,115 @@ func NewRouter(db *gorm.DB) *gin.Engine {
 	router.Use(middlewares.APIErrorHandler())
 	router.Use(middlewares.CustomRecovery())
 
-	itemRouter := router.Group("/items")
-	itemRouterWithAuth := router.Group("/items", middlewares.AuthMiddleware(authService))
-	authRouter := router.Group("/auth")
-	externalRouter := router.Group("/external")
+	c := routeControllers{
+		item:         itemController,
+		offer:        offerController,
+		purchase:     purchaseController,
+		review:       reviewController,
+		user:         userController,
+		category:     categoryController,
+		auth:         authController,
+		apiCall:      apiCallController,
+		notification: notificationController,
+		webhook:      webhookController,
+		itemLimit:    itemLimitController,
+		moderation:   moderationController,
+	}
+	// retried POSTs of an authenticated user are replayed by Idempotency-Key
+	auth := []gin.HandlerFunc{middlewares.AuthMiddleware(authService), middlewares.IdempotencyMiddleware(idempotencyService)}
 
-	itemRouter.GET("", itemController.FindAll)
-	itemRouterWithAuth.GET("/:id", itemController.FindById)
-	itemRouterWithAuth.POST("", itemController.Create)
-	itemRouterWithAuth.PUT("/:id", itemController.Update)
-	itemRouterWithAuth.DELETE("/:id", itemController.Delete)
+	registerRoutes(newVersionedGroup(router.Group("/v1"), nil), c, auth)
+	registerRoutes(newVersionedGroup(router.Group("/v2"), newV2Overrides(c)), c, auth)
+	// kept for the clients released before versioning
+	legacy := router.Group("", middlewares.DeprecationMiddleware("/v1", legacyDeprecatedAt, legacySunset))
+	registerRoutes(newVersionedGroup(legacy, nil), c, auth)
 
-	authRouter.POST("/signup", authController.Signup)
-	authRouter.POST("/login", authController.Login)
-
-	externalRouter.GET("", apiCallController.GetAllPosts)
-	externalRouter.GET("/user/:userId", apiCallController.GetUserAndPosts)
+	router.GET("/openapi.json", openapi.Handler(router, "flea-market API", apiVersion, operations))
+	router.GET("/docs", openapi.DocsHandler)
+	router.GET("/docs/assets/:file", openapi.AssetsHandler)
+	// for the services which verify our tokens, so it isn't versioned
+	router.GET("/.well-known/jwks.json", jwtauth.JWKSHandler(tokenIssuer))
+	// for operators, so it isn't versioned
+	router.GET("/diagnostics/db", middlewares.AuthMiddleware(authService), middlewares.AdminMiddleware(), diagnosticsController.DBPoolStats)
 
 	return router
 }
+
+type routeControllers struct {
+	item         *controllers.ItemController
+	offer        *controllers.OfferController
+	purchase     *controllers.PurchaseController
+	review       *controllers.ReviewController
+	user         *controllers.UserController
+	category     *controllers.CategoryController
+	auth         *controllers.AuthController
+	apiCall      *controllers.APICallController
+	notification *controllers.NotificationController
+	webhook      *controllers.WebhookController
+	itemLimit    *controllers.ItemLimitController
+	moderation   *controllers.ModerationController
+}
+
+// registerRoutes is called for each API version, so paths here don't have the version prefix.
+// auth is the chain of middlewares for the routes which need a logged-in user.
+func registerRoutes(api versionedGroup, c routeControllers, auth []gin.HandlerFunc) {
+	itemRouter := api.Group("/items")
+	itemRouterWithAuth := api.Group("/items", auth...)
+	authRouter := api.Group("/auth")
+	externalRouter := api.Group("/external")
+	categoryRouter := api.Group("/categories")
+	meRouter := api.Group("/me", auth...)
+	offerRouter := api.Group("/offers", auth...)
+	purchaseRouter := api.Group("/purchases", auth...)
+	reviewRouter := api.Group("/reviews", auth...)
+	userRouter := api.Group("/users")
+	adminRouter := api.Group("/admin", append(slices.Clone(auth), middlewares.AdminMiddleware())...)
+
+	itemRouter.GET("", c.item.FindAll)
+	itemRouter.GET("/search", c.item.Search)
+	itemRouterWithAuth.GET("/:id", c.item.FindById)
+	itemRouterWithAuth.POST("", c.item.Create)
+	itemRouterWithAuth.PUT("/:id", c.item.Update)
+	itemRouterWithAuth.DELETE("/:id", c.item.Delete)
+	itemRouterWithAuth.POST("/bulk", c.item.CreateBulk)
+	itemRouterWithAuth.PATCH("/bulk", c.item.UpdateBulk)
+	itemRouterWithAuth.DELETE("/bulk", c.item.DeleteBulk)
+	itemRouterWithAuth.POST("/:id/offers", c.offer.Create)
+	itemRouterWithAuth.GET("/:id/offers", c.offer.FindByItem)
+	itemRouterWithAuth.POST("/:id/purchase", c.purchase.Purchase)
+	itemRouterWithAuth.POST("/:id/report", c.moderation.Report)
+
+	offerRouter.POST("/:id/accept", c.offer.Accept)
+	offerRouter.POST("/:id/decline", c.offer.Decline)
+	offerRouter.POST("/:id/counter", c.offer.Counter)
+	offerRouter.POST("/:id/withdraw", c.offer.Withdraw)
+
+	purchaseRouter.POST("/:id/reviews", c.review.Create)
+	reviewRouter.PUT("/:id", c.review.Update)
+	userRouter.GET("/:id", c.user.FindProfile)
+	userRouter.GET("/:id/reviews", c.review.FindByUser)
+
+	categoryRouter.GET("", c.category.FindTree)
+
+	authRouter.POST("/signup", c.auth.Signup)
+	authRouter.POST("/login", c.auth.Login)
+
+	externalRouter.GET("", c.apiCall.GetAllPosts)
+	externalRouter.GET("/user/:userId", c.apiCall.GetUserAndPosts)
+
+	meRouter.GET("/events", c.notification.Stream)
+	meRouter.GET("/limits", c.itemLimit.FindMine)
+	meRouter.GET("/items/export", c.item.Export)
+	meRouter.POST("/items/import", c.item.Import)
+	meRouter.GET("/webhooks", c.webhook.FindByUser)
+	meRouter.POST("/webhooks", c.webhook.Create)
+	meRouter.DELETE("/webhooks/:id", c.webhook.Delete)
+	meRouter.GET("/webhooks/:id/deliveries", c.webhook.FindDeliveries)
+	meRouter.POST("/webhooks/:id/test", c.webhook.Test)
+
+	adminRouter.GET("/users/:id/limits", c.itemLimit.FindByUser)
+	adminRouter.PUT("/users/:id/limits", c.itemLimit.Update)
+	adminRouter.DELETE("/users/:id/limits", c.itemLimit.Delete)
+	adminRouter.GET("/moderation/queue", c.moderation.FindQueue)
+	adminRouter.POST("/moderation/items/:id", c.moderation.Decide)
+}
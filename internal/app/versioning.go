@@ -0,0 +1,105 @@
+package app
+
+import (
+	"flea-market/controllers"
+	"net/http"
+	"path"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+)
+
+// routeOverrides replaces the handlers of some routes in an API version.
+// It's keyed by "METHOD /path" without the version prefix, e.g. "GET /items/:id".
+type routeOverrides map[string]gin.HandlerFunc
+
+// newV2Overrides are the routes whose response differs from v1. The other v2 routes fall through to v1.
+// They respond in the {data, meta} envelope with response DTOs, while v1 keeps responding in {"data": ...} with the legacy DTOs.
+// The routes added after the envelope respond in it in every version.
+func newV2Overrides(c routeControllers) routeOverrides {
+	return routeOverrides{
+		"GET /items":               controllers.V2(c.item.FindAll),
+		"GET /items/search":        controllers.V2(c.item.Search),
+		"GET /items/:id":           controllers.V2(c.item.FindById),
+		"POST /items":              controllers.V2(c.item.Create),
+		"PUT /items/:id":           controllers.V2(c.item.Update),
+		"POST /items/:id/offers":   controllers.V2(c.offer.Create),
+		"GET /items/:id/offers":    controllers.V2(c.offer.FindByItem),
+		"POST /items/:id/purchase": controllers.V2(c.purchase.Purchase),
+
+		"POST /offers/:id/accept":   controllers.V2(c.offer.Accept),
+		"POST /offers/:id/decline":  controllers.V2(c.offer.Decline),
+		"POST /offers/:id/counter":  controllers.V2(c.offer.Counter),
+		"POST /offers/:id/withdraw": controllers.V2(c.offer.Withdraw),
+
+		"POST /purchases/:id/reviews": controllers.V2(c.review.Create),
+		"PUT /reviews/:id":            controllers.V2(c.review.Update),
+		"GET /users/:id":              controllers.V2(c.user.FindProfile),
+		"GET /users/:id/reviews":      controllers.V2(c.review.FindByUser),
+
+		"GET /categories":  controllers.V2(c.category.FindTree),
+		"POST /auth/login": controllers.V2(c.auth.Login),
+
+		"GET /external":              controllers.V2(c.apiCall.GetAllPosts),
+		"GET /external/user/:userId": controllers.V2(c.apiCall.GetUserAndPosts),
+	}
+}
+
+// versionedGroup registers the same routes to each API version.
+// Middlewares like auth are shared, and only the last handler is replaced by overrides.
+type versionedGroup struct {
+	group     *gin.RouterGroup
+	path      string
+	overrides routeOverrides
+}
+
+func newVersionedGroup(group *gin.RouterGroup, overrides routeOverrides) versionedGroup {
+	return versionedGroup{group: group, path: "/", overrides: overrides}
+}
+
+func (g versionedGroup) Group(relativePath string, handlers ...gin.HandlerFunc) versionedGroup {
+	return versionedGroup{
+		group:     g.group.Group(relativePath, handlers...),
+		path:      joinPath(g.path, relativePath),
+		overrides: g.overrides,
+	}
+}
+
+func (g versionedGroup) GET(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodGet, relativePath, handlers)
+}
+
+func (g versionedGroup) POST(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPost, relativePath, handlers)
+}
+
+func (g versionedGroup) PUT(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPut, relativePath, handlers)
+}
+
+func (g versionedGroup) PATCH(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodPatch, relativePath, handlers)
+}
+
+func (g versionedGroup) DELETE(relativePath string, handlers ...gin.HandlerFunc) {
+	g.handle(http.MethodDelete, relativePath, handlers)
+}
+
+func (g versionedGroup) handle(method string, relativePath string, handlers []gin.HandlerFunc) {
+	if override, ok := g.overrides[method+" "+joinPath(g.path, relativePath)]; ok {
+		handlers = append(slices.Clone(handlers[:len(handlers)-1]), override)
+	}
+	g.group.Handle(method, relativePath, handlers...)
+}
+
+// same as gin's, the trailing slash of relativePath is kept
+func joinPath(absolutePath string, relativePath string) string {
+	if relativePath == "" {
+		return absolutePath
+	}
+	joined := path.Join(absolutePath, relativePath)
+	if relativePath[len(relativePath)-1] == '/' && joined[len(joined)-1] != '/' {
+		return joined + "/"
+	}
+	return joined
+}
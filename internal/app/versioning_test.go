@@ -0,0 +1,70 @@
+package app
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionedGroup_Overrides(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	respond := func(body string) gin.HandlerFunc {
+		return func(ctx *gin.Context) { ctx.String(http.StatusOK, body) }
+	}
+	middleware := func(ctx *gin.Context) { ctx.Header("X-Middleware", "called") }
+	register := func(api versionedGroup) {
+		items := api.Group("/items", middleware)
+		items.GET("/:id", respond("v1 find"))
+		items.DELETE("/:id", respond("v1 delete"))
+	}
+	register(newVersionedGroup(router.Group("/v1"), nil))
+	register(newVersionedGroup(router.Group("/v2"), routeOverrides{"GET /items/:id": respond("v2 find")}))
+
+	cases := []struct {
+		method   string
+		path     string
+		wantBody string
+	}{
+		{method: "GET", path: "/v1/items/1", wantBody: "v1 find"},
+		{method: "GET", path: "/v2/items/1", wantBody: "v2 find"},
+		{method: "DELETE", path: "/v2/items/1", wantBody: "v1 delete"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(tc.method, tc.path, nil))
+			assert.Equal(t, tc.wantBody, w.Body.String())
+			// middlewares of the group are kept for the override
+			assert.Equal(t, "called", w.Header().Get("X-Middleware"))
+		})
+	}
+}
+
+// v2 overrides respond in another shape than v1, so each of them has its own document.
+func TestNewV2Overrides_Documented(t *testing.T) {
+	for key := range newV2Overrides(routeControllers{}) {
+		method, path, _ := strings.Cut(key, " ")
+		assert.Contains(t, operations, method+" /v2"+path)
+	}
+}
+
+// The models have internal columns, so responses are documented by the dto structs only.
+func TestOperations_RespondWithDTOs(t *testing.T) {
+	for key, operation := range operations {
+		if operation.Response == nil {
+			continue
+		}
+		response := reflect.TypeOf(operation.Response)
+		for response.Kind() == reflect.Slice {
+			response = response.Elem()
+		}
+		assert.NotEqual(t, "flea-market/models", response.PkgPath(), key)
+	}
+}
@@ -0,0 +1,52 @@
+package app
+
+import (
+	"context"
+	"flea-market/infra"
+	"flea-market/internal/jobs"
+	"flea-market/internal/outbox"
+	"flea-market/repositories"
+	"flea-market/utils"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+// Worker runs only the job workers and the outbox relay, so that background work can be scaled apart from the API.
+type Worker struct {
+	queue         jobs.IEnqueuer
+	pool          *jobs.Pool
+	outboxRelay   *outbox.Relay
+	replicaRouter *infra.ReplicaRouter
+}
+
+func NewWorker() *Worker {
+	infra.Initializer()
+	db := infra.SetupDB()
+	jobRepository := repositories.NewJobRepository(db)
+	pool := jobs.NewPool(jobRepository, newJobRegistry(db, jobRepository), jobWorkers(defaultWorkerJobWorkers))
+	outboxRelay := outbox.NewRelay(repositories.NewOutboxRepository(db), newOutboxPublisher(), outboxRelayInterval)
+	return &Worker{queue: jobRepository, pool: pool, outboxRelay: outboxRelay, replicaRouter: infra.ReplicaRouterOf(db)}
+}
+
+// Run runs jobs until SIGINT/SIGTERM is received and the running jobs finish.
+func (w *Worker) Run() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	scheduleRecurringJobs(ctx, w.queue)
+	var workers sync.WaitGroup
+	workers.Add(2)
+	go func() {
+		defer workers.Done()
+		w.outboxRelay.Run(ctx)
+	}()
+	go func() {
+		defer workers.Done()
+		w.replicaRouter.Run(ctx)
+	}()
+	w.pool.Run(ctx)
+	workers.Wait()
+	utils.Logger(utils.GenericMessage, "", "", "", "Job workers stopped")
+	return nil
+}
@@ -0,0 +1,108 @@
+// Package i18n has the message catalogs of each locale, which are embedded from locales/, and picks a locale from Accept-Language.
+package i18n
+
+import (
+	"embed"
+	"fmt"
+
+	"golang.org/x/text/language"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed locales/*.yaml
+var localeFiles embed.FS
+
+type Locale string
+
+const (
+	En Locale = "en"
+	Ja Locale = "ja"
+)
+
+// DefaultLocale is used when Accept-Language matches none of the locales, and for the messages missing in a catalog.
+const DefaultLocale = En
+
+// in the order of the tags of matcher
+var locales = []Locale{En, Ja}
+
+var matcher = language.NewMatcher([]language.Tag{language.English, language.Japanese})
+
+// Catalog is the messages of a locale keyed by utils.MessageCode.
+type Catalog struct {
+	// templates for operators, filled with fmt.Sprintf
+	Logs map[string]string `yaml:"logs"`
+	// for clients, so they mustn't have internal details
+	API map[string]string `yaml:"api"`
+	// for a failed field whose rule has no translation. %v is the field
+	Validation string `yaml:"validation"`
+}
+
+var catalogs = loadCatalogs()
+
+func loadCatalogs() map[Locale]Catalog {
+	catalogs := make(map[Locale]Catalog, len(locales))
+	for _, locale := range locales {
+		catalog, err := readCatalog(locale)
+		if err != nil {
+			panic(err.Error())
+		}
+		catalogs[locale] = *catalog
+	}
+	return catalogs
+}
+
+func readCatalog(locale Locale) (*Catalog, error) {
+	path := fmt.Sprintf("locales/%s.yaml", locale)
+	data, err := localeFiles.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var catalog Catalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("%s is invalid: %w", path, err)
+	}
+	return &catalog, nil
+}
+
+func Locales() []Locale {
+	return locales
+}
+
+func ParseLocale(value string) (Locale, bool) {
+	for _, locale := range locales {
+		if string(locale) == value {
+			return locale, true
+		}
+	}
+	return "", false
+}
+
+// Match picks the locale which fits Accept-Language best, e.g. ja for "ja-JP,en;q=0.8".
+func Match(acceptLanguage string) Locale {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return DefaultLocale
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return DefaultLocale
+	}
+	return locales[index]
+}
+
+// LogMessage returns the log template of code in locale, or in DefaultLocale when locale doesn't have it.
+func LogMessage(locale Locale, code string) string {
+	if message, ok := catalogs[locale].Logs[code]; ok {
+		return message
+	}
+	return catalogs[DefaultLocale].Logs[code]
+}
+
+// APIMessage returns the message of code for clients. ok is false when no catalog has it.
+func APIMessage(locale Locale, code string) (message string, ok bool) {
+	if message, ok := catalogs[locale].API[code]; ok {
+		return message, true
+	}
+	message, ok = catalogs[DefaultLocale].API[code]
+	return message, ok
+}
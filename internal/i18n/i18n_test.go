@@ -0,0 +1,73 @@
+package i18n
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogs_HaveSameCodes(t *testing.T) {
+	base := catalogs[DefaultLocale]
+	for _, locale := range Locales() {
+		catalog := catalogs[locale]
+		assert.ElementsMatch(t, slices.Collect(maps.Keys(base.Logs)), slices.Collect(maps.Keys(catalog.Logs)), locale)
+		assert.ElementsMatch(t, slices.Collect(maps.Keys(base.API)), slices.Collect(maps.Keys(catalog.API)), locale)
+		assert.NotEmpty(t, catalog.Validation, locale)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		want           Locale
+	}{
+		{"", En},
+		{"ja", Ja},
+		{"ja-JP,en;q=0.8", Ja},
+		{"fr-FR,ja;q=0.5", Ja},
+		{"en-US", En},
+		{"fr", En},
+		{"*", En},
+		{"invalid;;q", En},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Match(tt.acceptLanguage), tt.acceptLanguage)
+	}
+}
+
+func TestAPIMessage(t *testing.T) {
+	message, ok := APIMessage(Ja, "I001-00011")
+	assert.True(t, ok)
+	assert.Equal(t, "対象が見つかりません。", message)
+
+	_, ok = APIMessage(Ja, "I001-00001")
+	assert.False(t, ok)
+	assert.Equal(t, "Request started", LogMessage(En, "I001-00001"))
+}
+
+func TestTranslateValidation(t *testing.T) {
+	v := validator.New()
+	assert.NoError(t, RegisterValidator(v))
+
+	type entry struct {
+		Price uint `json:"price" validate:"min=1"`
+	}
+	type input struct {
+		Name  string  `json:"name" validate:"required"`
+		Items []entry `json:"items" validate:"dive"`
+		URL   string  `json:"url" validate:"omitempty,http_url"`
+	}
+	err := v.Struct(input{Items: []entry{{Price: 0}}, URL: "ftp://example.com"})
+
+	assert.Equal(t, []FieldError{
+		{Field: "name", Message: "nameは必須フィールドです"},
+		{Field: "items[0].price", Message: "priceは1以上でなければなりません"},
+		{Field: "url", Message: "urlが正しくありません。"},
+	}, TranslateValidation(err, Ja))
+	assert.Equal(t, "name is a required field", TranslateValidation(err, En)[0].Message)
+
+	assert.Nil(t, TranslateValidation(assert.AnError, En))
+}
@@ -0,0 +1,73 @@
+package i18n
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ja"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+	ja_translations "github.com/go-playground/validator/v10/translations/ja"
+)
+
+var translator = ut.New(en.New(), en.New(), ja.New())
+
+// FieldError is a failed field of a request. Field is the name clients send, like "items[0].price".
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// RegisterValidator adds the translations of every locale to v, and names the fields by their json or form tags,
+// so that the messages show the names clients send. It must be called only once for v.
+func RegisterValidator(v *validator.Validate) error {
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, key := range []string{"json", "form"} {
+			name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	registers := map[Locale]func(*validator.Validate, ut.Translator) error{
+		En: en_translations.RegisterDefaultTranslations,
+		Ja: ja_translations.RegisterDefaultTranslations,
+	}
+	for locale, register := range registers {
+		trans, _ := translator.GetTranslator(string(locale))
+		if err := register(v, trans); err != nil {
+			return fmt.Errorf("registering the translations of %s: %w", locale, err)
+		}
+	}
+	return nil
+}
+
+// TranslateValidation returns the failed fields of err in locale, or nil when err isn't a validation error.
+func TranslateValidation(err error, locale Locale) []FieldError {
+	var validationErrors validator.ValidationErrors
+	if !errors.As(err, &validationErrors) {
+		return nil
+	}
+	trans, _ := translator.GetTranslator(string(locale))
+	fields := make([]FieldError, 0, len(validationErrors))
+	for _, fieldError := range validationErrors {
+		// without the name of the struct, which clients don't know
+		_, field, _ := strings.Cut(fieldError.Namespace(), ".")
+		message := fieldError.Translate(trans)
+		// Translate falls back to the error of the validator, which is for developers
+		if message == fieldError.Error() {
+			message = fmt.Sprintf(catalogs[locale].Validation, fieldError.Field())
+		}
+		fields = append(fields, FieldError{Field: field, Message: message})
+	}
+	return fields
+}
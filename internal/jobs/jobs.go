@@ -0,0 +1,137 @@
+// Package jobs runs background work queued in the jobs table of the application DB.
+//
+// A kind of job is declared as a Type with its payload, registered to a Registry with its handler at startup,
+// and enqueued with Enqueue from anywhere. Pool claims and runs due jobs, retrying failed ones with backoff
+// until MaxAttempts, after which they are kept in the dead letter state.
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"flea-market/models"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultMaxAttempts = 5
+	defaultTimeout     = time.Minute
+)
+
+// Type ties a kind of job to its payload, which is stored as JSON.
+type Type[T any] struct {
+	Kind string
+	// defaults to 5
+	MaxAttempts int
+	// how long a handler can run. defaults to 1 minute.
+	Timeout time.Duration
+}
+
+func (t Type[T]) maxAttempts() int {
+	if t.MaxAttempts == 0 {
+		return defaultMaxAttempts
+	}
+	return t.MaxAttempts
+}
+
+func (t Type[T]) timeout() time.Duration {
+	if t.Timeout == 0 {
+		return defaultTimeout
+	}
+	return t.Timeout
+}
+
+type IEnqueuer interface {
+	Enqueue(ctx context.Context, job models.Job) (*models.Job, bool, error)
+}
+
+type Option func(job *models.Job)
+
+// RunAt schedules the job. It runs as soon as possible by default.
+func RunAt(runAt time.Time) Option {
+	return func(job *models.Job) {
+		job.RunAt = runAt
+	}
+}
+
+// Unique skips enqueueing while a queued job has the same key.
+func Unique(key string) Option {
+	return func(job *models.Job) {
+		job.UniqueKey = &key
+	}
+}
+
+// Enqueue adds a job of t. It returns false when it's skipped by Unique, with the queued job.
+func Enqueue[T any](ctx context.Context, enqueuer IEnqueuer, t Type[T], payload T, options ...Option) (*models.Job, bool, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, false, fmt.Errorf("marshal payload of %s: %w", t.Kind, err)
+	}
+	job := models.Job{
+		Kind:        t.Kind,
+		Payload:     string(data),
+		State:       models.JobQueued,
+		RunAt:       time.Now(),
+		MaxAttempts: t.maxAttempts(),
+	}
+	for _, option := range options {
+		option(&job)
+	}
+	return enqueuer.Enqueue(ctx, job)
+}
+
+type attemptKey struct{}
+
+// Attempt returns the attempt number of the running job, starting from 1. It's 0 outside of a handler.
+func Attempt(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
+
+type handler struct {
+	handle  func(ctx context.Context, payload string) error
+	timeout time.Duration
+}
+
+// Registry holds the handler of each kind. Workers claim only the registered kinds.
+type Registry struct {
+	handlers map[string]handler
+}
+
+func NewRegistry() *Registry {
+	return &Registry{handlers: map[string]handler{}}
+}
+
+// Register sets the handler of t. It panics when t.Kind is already registered, because it's a programming error.
+func Register[T any](r *Registry, t Type[T], handle func(ctx context.Context, payload T) error) {
+	if _, ok := r.handlers[t.Kind]; ok {
+		panic("job kind is registered twice: " + t.Kind)
+	}
+	r.handlers[t.Kind] = handler{
+		handle: func(ctx context.Context, payload string) error {
+			var value T
+			if err := json.Unmarshal([]byte(payload), &value); err != nil {
+				return fmt.Errorf("unmarshal payload of %s: %w", t.Kind, err)
+			}
+			return handle(ctx, value)
+		},
+		timeout: t.timeout(),
+	}
+}
+
+func (r *Registry) kinds() []string {
+	kinds := make([]string, 0, len(r.handlers))
+	for kind := range r.handlers {
+		kinds = append(kinds, kind)
+	}
+	return kinds
+}
+
+// the longest timeout, to lock a claimed job whatever its kind is
+func (r *Registry) maxTimeout() time.Duration {
+	var longest time.Duration
+	for _, handler := range r.handlers {
+		longest = max(longest, handler.timeout)
+	}
+	return longest
+}
@@ -0,0 +1,64 @@
+package jobs
+
+import (
+	"context"
+	"flea-market/internal/mocks"
+	"flea-market/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testPayload struct {
+	ItemID uint `json:"itemId"`
+}
+
+var testJob = Type[testPayload]{Kind: "test"}
+
+func TestEnqueue(t *testing.T) {
+	var enqueued models.Job
+	repo := &mocks.MockJobRepository{
+		EnqueueFunc: func(ctx context.Context, job models.Job) (*models.Job, bool, error) {
+			enqueued = job
+			return &job, true, nil
+		},
+	}
+	runAt := time.Now().Add(time.Hour)
+
+	_, created, err := Enqueue(context.Background(), repo, testJob, testPayload{ItemID: 3}, RunAt(runAt), Unique("item-3"))
+
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "test", enqueued.Kind)
+	assert.JSONEq(t, `{"itemId":3}`, enqueued.Payload)
+	assert.Equal(t, models.JobQueued, enqueued.State)
+	assert.Equal(t, runAt, enqueued.RunAt)
+	assert.Equal(t, "item-3", *enqueued.UniqueKey)
+	assert.Equal(t, defaultMaxAttempts, enqueued.MaxAttempts)
+}
+
+func TestRegister(t *testing.T) {
+	registry := NewRegistry()
+	var handled testPayload
+	Register(registry, testJob, func(ctx context.Context, payload testPayload) error {
+		handled = payload
+		return nil
+	})
+
+	assert.NoError(t, registry.handlers["test"].handle(context.Background(), `{"itemId":3}`))
+	assert.Equal(t, uint(3), handled.ItemID)
+	assert.Error(t, registry.handlers["test"].handle(context.Background(), `[]`))
+	assert.Equal(t, defaultTimeout, registry.maxTimeout())
+	assert.Panics(t, func() {
+		Register(registry, testJob, func(ctx context.Context, payload testPayload) error { return nil })
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 10*time.Second, backoff(1))
+	assert.Equal(t, 20*time.Second, backoff(2))
+	assert.Equal(t, 80*time.Second, backoff(4))
+	assert.Equal(t, time.Hour, backoff(10))
+	assert.Equal(t, time.Hour, backoff(100))
+}
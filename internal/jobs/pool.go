@@ -0,0 +1,139 @@
+package jobs
+
+import (
+	"context"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPollInterval = time.Second
+	// added to the handler timeout, so that the lock doesn't expire while the result is being saved
+	lockMargin     = 30 * time.Second
+	retryBaseDelay = 10 * time.Second
+	maxRetryDelay  = time.Hour
+)
+
+type IJobRepository interface {
+	Claim(ctx context.Context, kinds []string, now time.Time, lockedUntil time.Time) (*models.Job, error)
+	Complete(ctx context.Context, job models.Job, now time.Time) error
+	Retry(ctx context.Context, job models.Job, runAt time.Time, lastError string) error
+	Bury(ctx context.Context, job models.Job, now time.Time, lastError string) error
+}
+
+// Pool runs jobs with a fixed number of workers. Workers poll the jobs table while there's nothing to run.
+type Pool struct {
+	repository   IJobRepository
+	registry     *Registry
+	concurrency  int
+	pollInterval time.Duration
+}
+
+func NewPool(repository IJobRepository, registry *Registry, concurrency int) *Pool {
+	return &Pool{repository: repository, registry: registry, concurrency: concurrency, pollInterval: defaultPollInterval}
+}
+
+// Run blocks until ctx is canceled and the running jobs finish.
+// Running jobs aren't canceled with ctx, so that they aren't retried only because of a shutdown, but they are bounded by their timeout.
+func (p *Pool) Run(ctx context.Context) {
+	if p.concurrency <= 0 || len(p.registry.handlers) == 0 {
+		return
+	}
+	utils.Logger(utils.GenericMessage, "", "", "", fmt.Sprintf("Starting %d job workers", p.concurrency))
+
+	var workers sync.WaitGroup
+	for range p.concurrency {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			p.work(ctx)
+		}()
+	}
+	workers.Wait()
+}
+
+func (p *Pool) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		if p.runNext(ctx) {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(p.pollInterval):
+		}
+	}
+}
+
+// runNext returns false when there was no job to run.
+func (p *Pool) runNext(ctx context.Context) bool {
+	now := time.Now()
+	job, err := p.repository.Claim(ctx, p.registry.kinds(), now, now.Add(p.registry.maxTimeout()+lockMargin))
+	if err != nil {
+		if ctx.Err() == nil {
+			logError(err)
+		}
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	// the result has to be saved even while shutting down
+	jobCtx := context.WithoutCancel(ctx)
+	err = p.handle(jobCtx, *job)
+	finishedAt := time.Now()
+	switch {
+	case err == nil:
+		err = p.repository.Complete(jobCtx, *job, finishedAt)
+	case job.Attempts >= job.MaxAttempts:
+		utils.Logger(utils.JobDead, "", "", "", job.Kind, job.ID, job.Attempts, err.Error())
+		err = p.repository.Bury(jobCtx, *job, finishedAt, err.Error())
+	default:
+		retryAt := finishedAt.Add(backoff(job.Attempts))
+		utils.Logger(utils.JobFailed, "", "", "", job.Kind, job.ID, job.Attempts, retryAt.Format(time.RFC3339), err.Error())
+		err = p.repository.Retry(jobCtx, *job, retryAt, err.Error())
+	}
+	if err != nil {
+		logError(err)
+	}
+	return true
+}
+
+// handle runs the handler of job. A panic fails the job instead of stopping the worker.
+func (p *Pool) handle(ctx context.Context, job models.Job) (err error) {
+	handler, ok := p.registry.handlers[job.Kind]
+	if !ok {
+		return fmt.Errorf("no handler for job kind %s", job.Kind)
+	}
+	ctx, cancel := context.WithTimeout(context.WithValue(ctx, attemptKey{}, job.Attempts), handler.timeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return handler.handle(ctx, job.Payload)
+}
+
+// backoff doubles the delay every attempt: 10s, 20s, 40s, ... up to an hour.
+func backoff(attempts int) time.Duration {
+	if attempts <= 1 {
+		return retryBaseDelay
+	}
+	if attempts > 16 {
+		return maxRetryDelay
+	}
+	return min(retryBaseDelay<<(attempts-1), maxRetryDelay)
+}
+
+func logError(err error) {
+	if apiErr, ok := err.(*utils.APIError); ok {
+		utils.Logger(apiErr.MessageCode, "", "", "", err.Error())
+		return
+	}
+	utils.Logger(utils.UnknownError, "", "", "", err.Error())
+}
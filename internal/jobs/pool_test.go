@@ -0,0 +1,129 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"flea-market/internal/mocks"
+	"flea-market/models"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// results records how the claimed job is finished.
+type results struct {
+	completed bool
+	retryAt   time.Time
+	buried    bool
+	lastError string
+}
+
+func newTestPool(job *models.Job, handle func(ctx context.Context, payload testPayload) error) (*Pool, *results) {
+	r := &results{}
+	repo := &mocks.MockJobRepository{
+		ClaimFunc: func(ctx context.Context, kinds []string, now time.Time, lockedUntil time.Time) (*models.Job, error) {
+			claimed := job
+			job = nil
+			return claimed, nil
+		},
+		CompleteFunc: func(ctx context.Context, job models.Job, now time.Time) error {
+			r.completed = true
+			return nil
+		},
+		RetryFunc: func(ctx context.Context, job models.Job, runAt time.Time, lastError string) error {
+			r.retryAt, r.lastError = runAt, lastError
+			return nil
+		},
+		BuryFunc: func(ctx context.Context, job models.Job, now time.Time, lastError string) error {
+			r.buried, r.lastError = true, lastError
+			return nil
+		},
+	}
+	registry := NewRegistry()
+	Register(registry, testJob, handle)
+	return NewPool(repo, registry, 1), r
+}
+
+func TestPool_RunNext(t *testing.T) {
+	newJob := func(attempts int) *models.Job {
+		return &models.Job{ID: 1, Kind: "test", Payload: `{"itemId":3}`, State: models.JobRunning, Attempts: attempts, MaxAttempts: 3}
+	}
+
+	t.Run("completes", func(t *testing.T) {
+		pool, r := newTestPool(newJob(1), func(ctx context.Context, payload testPayload) error {
+			_, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.Equal(t, 1, Attempt(ctx))
+			return nil
+		})
+
+		assert.True(t, pool.runNext(context.Background()))
+		assert.True(t, r.completed)
+		assert.False(t, pool.runNext(context.Background()))
+	})
+
+	t.Run("retries with backoff", func(t *testing.T) {
+		pool, r := newTestPool(newJob(2), func(ctx context.Context, payload testPayload) error {
+			return errors.New("failed")
+		})
+
+		before := time.Now()
+		pool.runNext(context.Background())
+		assert.False(t, r.completed)
+		assert.Equal(t, "failed", r.lastError)
+		assert.WithinDuration(t, before.Add(20*time.Second), r.retryAt, time.Second)
+	})
+
+	t.Run("dead after max attempts", func(t *testing.T) {
+		pool, r := newTestPool(newJob(3), func(ctx context.Context, payload testPayload) error {
+			return errors.New("failed")
+		})
+
+		pool.runNext(context.Background())
+		assert.True(t, r.buried)
+		assert.True(t, r.retryAt.IsZero())
+	})
+
+	t.Run("panic fails the job", func(t *testing.T) {
+		pool, r := newTestPool(newJob(1), func(ctx context.Context, payload testPayload) error {
+			panic("boom")
+		})
+
+		pool.runNext(context.Background())
+		assert.Equal(t, "panic: boom", r.lastError)
+	})
+}
+
+func TestPool_Run(t *testing.T) {
+	t.Run("running jobs finish after cancel", func(t *testing.T) {
+		started := make(chan struct{})
+		var mu sync.Mutex
+		finished := false
+		pool, r := newTestPool(&models.Job{ID: 1, Kind: "test", Payload: `{}`, Attempts: 1, MaxAttempts: 3}, func(ctx context.Context, payload testPayload) error {
+			close(started)
+			time.Sleep(50 * time.Millisecond)
+			mu.Lock()
+			finished = true
+			mu.Unlock()
+			return ctx.Err()
+		})
+		pool.pollInterval = time.Millisecond
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			pool.Run(ctx)
+			close(done)
+		}()
+		<-started
+		cancel()
+		<-done
+
+		mu.Lock()
+		defer mu.Unlock()
+		assert.True(t, finished)
+		assert.True(t, r.completed)
+	})
+}
@@ -0,0 +1,111 @@
+package jwtauth
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type Config struct {
+	Issuer   string
+	Audience string
+	TTL      time.Duration
+	// tolerated difference of the clocks of the issuer and the verifier, for exp, nbf and iat
+	ClockSkew time.Duration
+}
+
+// Claims are the claims of an access token. Subject is the user id.
+type Claims struct {
+	Email string `json:"email"`
+	jwt.RegisteredClaims
+}
+
+// UserID is the user id in Subject, which Verify has checked to be a number.
+func (c *Claims) UserID() uint {
+	id, _ := strconv.ParseUint(c.Subject, 10, 64)
+	return uint(id)
+}
+
+// Issuer issues access tokens signed by the signing key of the keyset, and verifies them by any key of it.
+type Issuer struct {
+	keys   *KeySet
+	config Config
+	now    func() time.Time
+}
+
+func NewIssuer(keys *KeySet, config Config) *Issuer {
+	return &Issuer{keys: keys, config: config, now: time.Now}
+}
+
+func (i *Issuer) Issue(userId uint, email string) (string, error) {
+	now := i.now()
+	key := i.keys.Signing()
+	token := jwt.NewWithClaims(key.Method, Claims{
+		Email: email,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    i.config.Issuer,
+			Subject:   strconv.FormatUint(uint64(userId), 10),
+			Audience:  jwt.ClaimStrings{i.config.Audience},
+			ExpiresAt: jwt.NewNumericDate(now.Add(i.config.TTL)),
+			NotBefore: jwt.NewNumericDate(now),
+			IssuedAt:  jwt.NewNumericDate(now),
+		},
+	})
+	if key.ID != "" {
+		token.Header["kid"] = key.ID
+	}
+	return token.SignedString(key.signingKey())
+}
+
+// Verify checks the signature by the key of the kid header, and iss, aud, exp, nbf and iat, which are all required.
+// Errors wrap the ones of jwt like jwt.ErrTokenExpired, or ErrUnknownKey.
+func (i *Issuer) Verify(token string) (*Claims, error) {
+	parser := jwt.NewParser(
+		jwt.WithValidMethods(i.keys.Methods()),
+		jwt.WithIssuer(i.config.Issuer),
+		jwt.WithAudience(i.config.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(i.config.ClockSkew),
+		jwt.WithTimeFunc(i.now),
+	)
+
+	var key *Key
+	claims := &Claims{}
+	_, err := parser.ParseWithClaims(token, claims, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		found, ok := i.keys.Find(kid)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownKey, kid)
+		}
+		// a public key must not be taken as the secret of HS256
+		if t.Method.Alg() != found.Method.Alg() {
+			return nil, fmt.Errorf("key %q is for %s, not %s", kid, found.Method.Alg(), t.Method.Alg())
+		}
+		key = found
+		return found.public, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if claims.NotBefore == nil || claims.IssuedAt == nil {
+		return nil, fmt.Errorf("%w: nbf and iat are required", jwt.ErrTokenRequiredClaimMissing)
+	}
+	if key.RetiredAt != nil && claims.IssuedAt.After(*key.RetiredAt) {
+		return nil, fmt.Errorf("%w: key %q was retired at %s", jwt.ErrTokenInvalidClaims, key.ID, key.RetiredAt.Format(time.RFC3339))
+	}
+	if _, err := strconv.ParseUint(claims.Subject, 10, 64); err != nil {
+		return nil, fmt.Errorf("%w: sub %q isn't a user id", jwt.ErrTokenInvalidClaims, claims.Subject)
+	}
+	if claims.Email == "" {
+		return nil, fmt.Errorf("%w: email is required", jwt.ErrTokenRequiredClaimMissing)
+	}
+	return claims, nil
+}
+
+func (i *Issuer) JWKS() JWKS {
+	return i.keys.JWKS(i.now(), i.config.TTL+i.config.ClockSkew)
+}
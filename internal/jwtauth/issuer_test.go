@@ -0,0 +1,177 @@
+package jwtauth
+
+import (
+	"crypto/ed25519"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testConfig = Config{Issuer: "flea-market", Audience: "flea-market", TTL: time.Hour, ClockSkew: 30 * time.Second}
+
+func newTestIssuer(keys *KeySet, now time.Time) *Issuer {
+	issuer := NewIssuer(keys, testConfig)
+	issuer.now = func() time.Time { return now }
+	return issuer
+}
+
+func readTestKeySet(t *testing.T, dir string, manifest string) *KeySet {
+	t.Helper()
+	path := filepath.Join(dir, "keys.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(manifest), 0o600))
+	keys, err := ReadKeySet(path)
+	require.NoError(t, err)
+	return keys
+}
+
+func TestIssueAndVerify(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	dir := writeKeys(t, map[string]any{"a": newEd25519(t)})
+	issuer := newTestIssuer(readTestKeySet(t, dir, "signing: a\nkeys: [{id: a, file: a.pem}]"), now)
+
+	token, err := issuer.Issue(1, "test@example.com")
+	require.NoError(t, err)
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+	require.NoError(t, err)
+	assert.Equal(t, "a", parsed.Header["kid"])
+	assert.Equal(t, "EdDSA", parsed.Header["alg"])
+
+	claims, err := issuer.Verify(token)
+	require.NoError(t, err)
+	assert.Equal(t, "1", claims.Subject)
+	assert.Equal(t, uint(1), claims.UserID())
+	assert.Equal(t, "test@example.com", claims.Email)
+	assert.Equal(t, "flea-market", claims.Issuer)
+	assert.Equal(t, jwt.ClaimStrings{"flea-market"}, claims.Audience)
+	assert.Equal(t, now, claims.IssuedAt.Time.UTC())
+	assert.Equal(t, now, claims.NotBefore.Time.UTC())
+	assert.Equal(t, now.Add(time.Hour), claims.ExpiresAt.Time.UTC())
+
+	// within the clock skew
+	issuer.now = func() time.Time { return now.Add(-20 * time.Second) }
+	_, err = issuer.Verify(token)
+	assert.NoError(t, err)
+	issuer.now = func() time.Time { return now.Add(time.Hour + 20*time.Second) }
+	_, err = issuer.Verify(token)
+	assert.NoError(t, err)
+
+	// beyond it
+	issuer.now = func() time.Time { return now.Add(-time.Minute) }
+	_, err = issuer.Verify(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenNotValidYet)
+	issuer.now = func() time.Time { return now.Add(time.Hour + time.Minute) }
+	_, err = issuer.Verify(token)
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+}
+
+func TestVerifyRejects(t *testing.T) {
+	now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+	dir := writeKeys(t, map[string]any{"a": newEd25519(t), "b": newEd25519(t)})
+	keys := readTestKeySet(t, dir, "signing: a\nkeys: [{id: a, file: a.pem}]")
+	issuer := newTestIssuer(keys, now)
+
+	sign := func(t *testing.T, method jwt.SigningMethod, kid string, key any, claims jwt.Claims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		require.NoError(t, err)
+		return signed
+	}
+	valid := func() Claims {
+		return Claims{Email: "test@example.com", RegisteredClaims: jwt.RegisteredClaims{
+			Issuer: "flea-market", Subject: "1", Audience: jwt.ClaimStrings{"flea-market"},
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)), NotBefore: jwt.NewNumericDate(now), IssuedAt: jwt.NewNumericDate(now),
+		}}
+	}
+	a, _ := keys.Find("a")
+	otherKeys := readTestKeySet(t, dir, "signing: b\nkeys: [{id: b, file: b.pem}]")
+
+	otherIssuer := valid()
+	otherIssuer.Issuer = "other"
+	otherAudience := valid()
+	otherAudience.Audience = jwt.ClaimStrings{"other"}
+	noNbf := valid()
+	noNbf.NotBefore = nil
+	noIat := valid()
+	noIat.IssuedAt = nil
+	futureIat := valid()
+	futureIat.IssuedAt = jwt.NewNumericDate(now.Add(time.Minute))
+
+	cases := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"unknown kid", sign(t, jwt.SigningMethodEdDSA, "b", otherKeys.Signing().private, valid()), ErrUnknownKey},
+		{"wrong key for kid", sign(t, jwt.SigningMethodEdDSA, "a", otherKeys.Signing().private, valid()), jwt.ErrTokenSignatureInvalid},
+		// the public key used as the secret of HS256
+		{"alg confusion", sign(t, jwt.SigningMethodHS256, "a", []byte(a.public.(ed25519.PublicKey)), valid()), jwt.ErrTokenSignatureInvalid},
+		{"other issuer", sign(t, jwt.SigningMethodEdDSA, "a", a.private, otherIssuer), jwt.ErrTokenInvalidIssuer},
+		{"other audience", sign(t, jwt.SigningMethodEdDSA, "a", a.private, otherAudience), jwt.ErrTokenInvalidAudience},
+		{"no nbf", sign(t, jwt.SigningMethodEdDSA, "a", a.private, noNbf), jwt.ErrTokenRequiredClaimMissing},
+		{"no iat", sign(t, jwt.SigningMethodEdDSA, "a", a.private, noIat), jwt.ErrTokenRequiredClaimMissing},
+		{"iat in the future", sign(t, jwt.SigningMethodEdDSA, "a", a.private, futureIat), jwt.ErrTokenUsedBeforeIssued},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := issuer.Verify(tc.token)
+			assert.ErrorIs(t, err, tc.want)
+		})
+	}
+}
+
+// The old key verifies the tokens it signed before it was retired until they expire.
+func TestRotation(t *testing.T) {
+	retiredAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	dir := writeKeys(t, map[string]any{"old": newEd25519(t), "new": newEd25519(t)})
+	before := newTestIssuer(readTestKeySet(t, dir, "signing: old\nkeys: [{id: old, file: old.pem}, {id: new, file: new.pub.pem}]"), retiredAt.Add(-time.Minute))
+	oldToken, err := before.Issue(1, "test@example.com")
+	require.NoError(t, err)
+
+	after := newTestIssuer(readTestKeySet(t, dir, `
+signing: new
+keys:
+  - {id: new, file: new.pem}
+  - {id: old, file: old.pem, retiredAt: 2026-10-01T00:00:00Z}
+`), retiredAt.Add(10*time.Minute))
+	_, err = after.Verify(oldToken)
+	assert.NoError(t, err)
+	newToken, err := after.Issue(1, "test@example.com")
+	require.NoError(t, err)
+	_, err = after.Verify(newToken)
+	assert.NoError(t, err)
+
+	// a token signed by the old key after it was retired
+	late := newTestIssuer(before.keys, retiredAt.Add(5*time.Minute))
+	lateToken, err := late.Issue(1, "test@example.com")
+	require.NoError(t, err)
+	_, err = after.Verify(lateToken)
+	assert.ErrorIs(t, err, jwt.ErrTokenInvalidClaims)
+
+	after.now = func() time.Time { return retiredAt.Add(2 * time.Hour) }
+	_, err = after.Verify(oldToken)
+	assert.ErrorIs(t, err, jwt.ErrTokenExpired)
+}
+
+func TestSecretKeySet(t *testing.T) {
+	now := time.Now()
+	issuer := newTestIssuer(NewSecretKeySet([]byte("test")), now)
+	token, err := issuer.Issue(1, "test@example.com")
+	require.NoError(t, err)
+
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, &Claims{})
+	require.NoError(t, err)
+	assert.Equal(t, "HS256", parsed.Header["alg"])
+	assert.NotContains(t, parsed.Header, "kid")
+	_, err = issuer.Verify(token)
+	assert.NoError(t, err)
+
+	// the secret isn't published
+	assert.Empty(t, issuer.JWKS().Keys)
+}
@@ -0,0 +1,197 @@
+// Package jwtauth signs and verifies the access tokens, and publishes the public keys as a JWKS.
+package jwtauth
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrUnknownKey is returned for a token whose kid isn't in the keyset.
+var ErrUnknownKey = errors.New("signing key is unknown")
+
+const minRSABits = 2048
+
+// Key is a key of the keyset. A key given only by its public key can verify but not sign.
+type Key struct {
+	ID     string
+	Method jwt.SigningMethod
+	// nil for a public key
+	private crypto.Signer
+	// the public key, or the secret for HS256
+	public any
+	// Tokens issued after it are rejected, and the ones before it are valid until they expire.
+	RetiredAt *time.Time
+}
+
+func (k *Key) signingKey() any {
+	if k.private != nil {
+		return k.private
+	}
+	return k.public
+}
+
+// KeySet is the keys to verify tokens, one of which signs new tokens.
+type KeySet struct {
+	signing *Key
+	keys    []*Key
+}
+
+// NewSecretKeySet signs with HS256 by a shared secret. It's for development, and its tokens have no kid.
+func NewSecretKeySet(secret []byte) *KeySet {
+	key := &Key{Method: jwt.SigningMethodHS256, public: secret}
+	return &KeySet{signing: key, keys: []*Key{key}}
+}
+
+// Signing is the key which signs new tokens.
+func (s *KeySet) Signing() *Key {
+	return s.signing
+}
+
+// Find returns the key of kid. Retired keys are found too, so that their tokens are valid until they expire.
+func (s *KeySet) Find(kid string) (*Key, bool) {
+	for _, key := range s.keys {
+		if key.ID == kid {
+			return key, true
+		}
+	}
+	return nil, false
+}
+
+// Methods are the algorithms of the keys, which are the only ones accepted.
+func (s *KeySet) Methods() []string {
+	var methods []string
+	seen := map[string]bool{}
+	for _, key := range s.keys {
+		if alg := key.Method.Alg(); !seen[alg] {
+			seen[alg] = true
+			methods = append(methods, alg)
+		}
+	}
+	return methods
+}
+
+type keySetFile struct {
+	Signing string `yaml:"signing"`
+	Keys    []struct {
+		ID        string     `yaml:"id"`
+		File      string     `yaml:"file"`
+		RetiredAt *time.Time `yaml:"retiredAt"`
+	} `yaml:"keys"`
+}
+
+// ReadKeySet reads a YAML file which lists the PEM files of the keys, relative to the file:
+//
+//	signing: 2026-10
+//	keys:
+//	  - id: 2026-10
+//	    file: 2026-10.pem
+//	  - id: 2026-04
+//	    file: 2026-04.pub.pem
+//	    retiredAt: 2026-10-01T00:00:00Z
+//
+// A file is a PKCS#8 private key, a PKCS#1 RSA private key or a PKIX public key, of RSA (RS256) or Ed25519 (EdDSA).
+func ReadKeySet(path string) (*KeySet, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseKeySet(data, filepath.Dir(path))
+}
+
+// ParseKeySet parses the YAML of ReadKeySet. The key files are read from dir.
+func ParseKeySet(data []byte, dir string) (*KeySet, error) {
+	var file keySetFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	set := &KeySet{}
+	for _, entry := range file.Keys {
+		if entry.ID == "" {
+			return nil, fmt.Errorf("key of %q has no id", entry.File)
+		}
+		if _, ok := set.Find(entry.ID); ok {
+			return nil, fmt.Errorf("key %q is duplicated", entry.ID)
+		}
+		path := entry.File
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		pemData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", entry.ID, err)
+		}
+		key, err := parseKey(pemData)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", entry.ID, err)
+		}
+		key.ID = entry.ID
+		key.RetiredAt = entry.RetiredAt
+		set.keys = append(set.keys, key)
+	}
+
+	signing, ok := set.Find(file.Signing)
+	switch {
+	case !ok:
+		return nil, fmt.Errorf("signing key %q isn't in the keys", file.Signing)
+	case signing.private == nil:
+		return nil, fmt.Errorf("signing key %q is a public key", file.Signing)
+	case signing.RetiredAt != nil:
+		return nil, fmt.Errorf("signing key %q is retired", file.Signing)
+	}
+	set.signing = signing
+	return set, nil
+}
+
+func parseKey(data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM block")
+	}
+
+	var parsed any
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("PEM block %q isn't supported", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		if k.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA key must be %d bits or more", minRSABits)
+		}
+		return &Key{Method: jwt.SigningMethodRS256, private: k, public: &k.PublicKey}, nil
+	case *rsa.PublicKey:
+		if k.N.BitLen() < minRSABits {
+			return nil, fmt.Errorf("RSA key must be %d bits or more", minRSABits)
+		}
+		return &Key{Method: jwt.SigningMethodRS256, public: k}, nil
+	case ed25519.PrivateKey:
+		return &Key{Method: jwt.SigningMethodEdDSA, private: k, public: k.Public()}, nil
+	case ed25519.PublicKey:
+		return &Key{Method: jwt.SigningMethodEdDSA, public: k}, nil
+	default:
+		return nil, fmt.Errorf("key type %T isn't supported, use RSA or Ed25519", parsed)
+	}
+}
@@ -0,0 +1,110 @@
+package jwtauth
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeKeys writes the keys as PEM files named by their ids, and the private ones also as <id>.pub.pem.
+func writeKeys(t *testing.T, keys map[string]any) string {
+	t.Helper()
+	dir := t.TempDir()
+	for id, key := range keys {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		writePEM(t, filepath.Join(dir, id+".pem"), "PRIVATE KEY", der)
+
+		var public any
+		switch k := key.(type) {
+		case *rsa.PrivateKey:
+			public = &k.PublicKey
+		case ed25519.PrivateKey:
+			public = k.Public()
+		}
+		der, err = x509.MarshalPKIXPublicKey(public)
+		require.NoError(t, err)
+		writePEM(t, filepath.Join(dir, id+".pub.pem"), "PUBLIC KEY", der)
+	}
+	return dir
+}
+
+func writePEM(t *testing.T, path string, blockType string, der []byte) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+}
+
+func newEd25519(t *testing.T) ed25519.PrivateKey {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func TestReadKeySet(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	dir := writeKeys(t, map[string]any{"new": newEd25519(t), "old": rsaKey})
+	manifest := filepath.Join(dir, "keys.yaml")
+	require.NoError(t, os.WriteFile(manifest, []byte(`
+signing: new
+keys:
+  - id: new
+    file: new.pem
+  - id: old
+    file: old.pub.pem
+    retiredAt: 2026-10-01T00:00:00Z
+`), 0o600))
+
+	keys, err := ReadKeySet(manifest)
+	require.NoError(t, err)
+	assert.Equal(t, "new", keys.Signing().ID)
+	assert.Equal(t, jwt.SigningMethodEdDSA, keys.Signing().Method)
+	old, ok := keys.Find("old")
+	require.True(t, ok)
+	assert.Equal(t, jwt.SigningMethodRS256, old.Method)
+	assert.Nil(t, old.private)
+	assert.Equal(t, []string{"EdDSA", "RS256"}, keys.Methods())
+
+	retiredAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	jwks := keys.JWKS(retiredAt.Add(30*time.Minute), time.Hour)
+	require.Len(t, jwks.Keys, 2)
+	assert.Equal(t, JWK{Kty: "OKP", Use: "sig", Alg: "EdDSA", Kid: "new", Crv: "Ed25519", X: jwks.Keys[0].X}, jwks.Keys[0])
+	assert.Equal(t, "RSA", jwks.Keys[1].Kty)
+	assert.Equal(t, "AQAB", jwks.Keys[1].E)
+	// the tokens of the retired key have expired
+	jwks = keys.JWKS(retiredAt.Add(2*time.Hour), time.Hour)
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "new", jwks.Keys[0].Kid)
+}
+
+func TestParseKeySetErrors(t *testing.T) {
+	weak, err := rsa.GenerateKey(rand.Reader, 1024)
+	require.NoError(t, err)
+	dir := writeKeys(t, map[string]any{"a": newEd25519(t), "weak": weak})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.pem"), []byte("not a key"), 0o600))
+
+	cases := map[string]string{
+		"signing: b\nkeys: [{id: a, file: a.pem}]":                                  `signing key "b" isn't in the keys`,
+		"signing: a\nkeys: [{id: a, file: a.pub.pem}]":                              `signing key "a" is a public key`,
+		"signing: a\nkeys: [{id: a, file: a.pem, retiredAt: 2026-10-01T00:00:00Z}]": `signing key "a" is retired`,
+		"signing: a\nkeys: [{id: a, file: a.pem}, {id: a, file: a.pub.pem}]":        `key "a" is duplicated`,
+		"signing: a\nkeys: [{file: a.pem}]":                                         "has no id",
+		"signing: a\nkeys: [{id: a, file: a.pem}, {id: b, file: broken.pem}]":       "no PEM block",
+		"signing: a\nkeys: [{id: a, file: a.pem}, {id: b, file: weak.pem}]":         "2048 bits or more",
+		"signing: a\nkeys: [{id: a, file: a.pem}, {id: b, file: missing.pem}]":      "missing.pem",
+	}
+	for data, want := range cases {
+		_, err := ParseKeySet([]byte(data), dir)
+		assert.ErrorContains(t, err, want, data)
+	}
+}
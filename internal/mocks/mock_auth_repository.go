@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+	"flea-market/models"
+)
+
+type MockAuthRepository struct {
+	CreateUserFunc     func(ctx context.Context, user models.User) error
+	FindUserFunc       func(ctx context.Context, email string) (*models.User, error)
+	UpdatePasswordFunc func(ctx context.Context, userId uint, hashed string) error
+
+	FindUserByIdFunc func(ctx context.Context, userId uint) (*models.User, error)
+}
+
+func (m *MockAuthRepository) CreateUser(ctx context.Context, user models.User) error {
+	return m.CreateUserFunc(ctx, user)
+}
+func (m *MockAuthRepository) FindUser(ctx context.Context, email string) (*models.User, error) {
+	return m.FindUserFunc(ctx, email)
+}
+func (m *MockAuthRepository) FindUserById(ctx context.Context, userId uint) (*models.User, error) {
+	return m.FindUserByIdFunc(ctx, userId)
+}
+func (m *MockAuthRepository) UpdatePassword(ctx context.Context, userId uint, hashed string) error {
+	return m.UpdatePasswordFunc(ctx, userId, hashed)
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"context"
+	"flea-market/models"
+	"net/http"
+	"time"
+)
+
+type MockIdempotencyRepository struct {
+	ReserveFunc  func(ctx context.Context, key models.IdempotencyKey, now time.Time, staleBefore time.Time) (*models.IdempotencyKey, bool, error)
+	CompleteFunc func(ctx context.Context, id uint, status int, headers http.Header, body []byte) error
+	DeleteFunc   func(ctx context.Context, id uint) error
+
+	DeleteExpiredFunc func(ctx context.Context, now time.Time) (int64, error)
+}
+
+func (m *MockIdempotencyRepository) Reserve(ctx context.Context, key models.IdempotencyKey, now time.Time, staleBefore time.Time) (*models.IdempotencyKey, bool, error) {
+	return m.ReserveFunc(ctx, key, now, staleBefore)
+}
+
+func (m *MockIdempotencyRepository) Complete(ctx context.Context, id uint, status int, headers http.Header, body []byte) error {
+	return m.CompleteFunc(ctx, id, status, headers, body)
+}
+
+func (m *MockIdempotencyRepository) Delete(ctx context.Context, id uint) error {
+	return m.DeleteFunc(ctx, id)
+}
+
+func (m *MockIdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	return m.DeleteExpiredFunc(ctx, now)
+}
@@ -0,0 +1,27 @@
+package mocks
+
+import (
+	"context"
+	"flea-market/models"
+	"time"
+)
+
+type MockItemLimitRepository struct {
+	CountUsageFunc     func(ctx context.Context, userId uint, since time.Time) (*models.ItemUsage, error)
+	FindOverrideFunc   func(ctx context.Context, userId uint) (*models.UserLimitOverride, error)
+	SaveOverrideFunc   func(ctx context.Context, override models.UserLimitOverride) (*models.UserLimitOverride, error)
+	DeleteOverrideFunc func(ctx context.Context, userId uint) error
+}
+
+func (m *MockItemLimitRepository) CountUsage(ctx context.Context, userId uint, since time.Time) (*models.ItemUsage, error) {
+	return m.CountUsageFunc(ctx, userId, since)
+}
+func (m *MockItemLimitRepository) FindOverride(ctx context.Context, userId uint) (*models.UserLimitOverride, error) {
+	return m.FindOverrideFunc(ctx, userId)
+}
+func (m *MockItemLimitRepository) SaveOverride(ctx context.Context, override models.UserLimitOverride) (*models.UserLimitOverride, error) {
+	return m.SaveOverrideFunc(ctx, override)
+}
+func (m *MockItemLimitRepository) DeleteOverride(ctx context.Context, userId uint) error {
+	return m.DeleteOverrideFunc(ctx, userId)
+}
@@ -0,0 +1,26 @@
+package mocks
+
+import (
+	"context"
+	"flea-market/internal/money"
+)
+
+// MockItemLimiter allows every create and update unless CheckCreateFunc or CheckUpdateFunc is set.
+type MockItemLimiter struct {
+	CheckCreateFunc func(ctx context.Context, userId uint, prices []money.Money) error
+	CheckUpdateFunc func(ctx context.Context, userId uint, price *money.Money, relisted bool) error
+}
+
+func (m *MockItemLimiter) CheckCreate(ctx context.Context, userId uint, prices []money.Money) error {
+	if m.CheckCreateFunc != nil {
+		return m.CheckCreateFunc(ctx, userId, prices)
+	}
+	return nil
+}
+
+func (m *MockItemLimiter) CheckUpdate(ctx context.Context, userId uint, price *money.Money, relisted bool) error {
+	if m.CheckUpdateFunc != nil {
+		return m.CheckUpdateFunc(ctx, userId, price, relisted)
+	}
+	return nil
+}
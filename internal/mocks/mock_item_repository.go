@@ -3,25 +3,40 @@
 package mocks
 
 import (
+	"flea-market/dto"
 	"flea-market/models"
 
 	"golang.org/x/net/context"
 )
 
 type MockItemRepository struct {
-	FindAllFunc  func(ctx context.Context) (*[]models.Item, error)
+	FindAllFunc  func(ctx context.Context, query dto.ItemQuery) (*[]models.Item, error)
 	FindByIdFunc func(ctx context.Context, itemId uint, userId uint) (*models.Item, error)
+	SearchFunc   func(ctx context.Context, tsQuery string, limit int) (*[]models.ItemSearchResult, error)
 	CreateFunc   func(ctx context.Context, newItem models.Item) (*models.Item, error)
 	UpdateFunc   func(ctx context.Context, updateItem models.Item) (*models.Item, error)
 	DeleteFunc   func(ctx context.Context, itemId uint, userId uint) error
+
+	FindByIdForUpdateFunc func(ctx context.Context, itemId uint, userId uint) (*models.Item, error)
+
+	CreateBulkFunc func(ctx context.Context, items []models.Item, atomic bool) []error
+	DeleteBulkFunc func(ctx context.Context, itemIds []uint, userId uint, atomic bool) []error
+
+	FindByUserInBatchesFunc func(ctx context.Context, userId uint, batchSize int, fn func(items []models.Item) error) error
 }
 
-func (m *MockItemRepository) FindAll(ctx context.Context) (*[]models.Item, error) {
-	return m.FindAllFunc(ctx)
+func (m *MockItemRepository) FindAll(ctx context.Context, query dto.ItemQuery) (*[]models.Item, error) {
+	return m.FindAllFunc(ctx, query)
 }
 func (m *MockItemRepository) FindById(ctx context.Context, itemId uint, userId uint) (*models.Item, error) {
 	return m.FindByIdFunc(ctx, itemId, userId)
 }
+func (m *MockItemRepository) FindByIdForUpdate(ctx context.Context, itemId uint, userId uint) (*models.Item, error) {
+	return m.FindByIdForUpdateFunc(ctx, itemId, userId)
+}
+func (m *MockItemRepository) Search(ctx context.Context, tsQuery string, limit int) (*[]models.ItemSearchResult, error) {
+	return m.SearchFunc(ctx, tsQuery, limit)
+}
 func (m *MockItemRepository) Create(ctx context.Context, newItem models.Item) (*models.Item, error) {
 	return m.CreateFunc(ctx, newItem)
 }
@@ -31,3 +46,12 @@ func (m *MockItemRepository) Update(ctx context.Context, updateItem models.Item)
 func (m *MockItemRepository) Delete(ctx context.Context, itemId uint, userId uint) error {
 	return m.DeleteFunc(ctx, itemId, userId)
 }
+func (m *MockItemRepository) CreateBulk(ctx context.Context, items []models.Item, atomic bool) []error {
+	return m.CreateBulkFunc(ctx, items, atomic)
+}
+func (m *MockItemRepository) DeleteBulk(ctx context.Context, itemIds []uint, userId uint, atomic bool) []error {
+	return m.DeleteBulkFunc(ctx, itemIds, userId, atomic)
+}
+func (m *MockItemRepository) FindByUserInBatches(ctx context.Context, userId uint, batchSize int, fn func(items []models.Item) error) error {
+	return m.FindByUserInBatchesFunc(ctx, userId, batchSize, fn)
+}
@@ -0,0 +1,35 @@
+package mocks
+
+import (
+	"context"
+	"flea-market/models"
+	"time"
+)
+
+type MockJobRepository struct {
+	EnqueueFunc  func(ctx context.Context, job models.Job) (*models.Job, bool, error)
+	ClaimFunc    func(ctx context.Context, kinds []string, now time.Time, lockedUntil time.Time) (*models.Job, error)
+	CompleteFunc func(ctx context.Context, job models.Job, now time.Time) error
+	RetryFunc    func(ctx context.Context, job models.Job, runAt time.Time, lastError string) error
+	BuryFunc     func(ctx context.Context, job models.Job, now time.Time, lastError string) error
+}
+
+func (m *MockJobRepository) Enqueue(ctx context.Context, job models.Job) (*models.Job, bool, error) {
+	return m.EnqueueFunc(ctx, job)
+}
+
+func (m *MockJobRepository) Claim(ctx context.Context, kinds []string, now time.Time, lockedUntil time.Time) (*models.Job, error) {
+	return m.ClaimFunc(ctx, kinds, now, lockedUntil)
+}
+
+func (m *MockJobRepository) Complete(ctx context.Context, job models.Job, now time.Time) error {
+	return m.CompleteFunc(ctx, job, now)
+}
+
+func (m *MockJobRepository) Retry(ctx context.Context, job models.Job, runAt time.Time, lastError string) error {
+	return m.RetryFunc(ctx, job, runAt, lastError)
+}
+
+func (m *MockJobRepository) Bury(ctx context.Context, job models.Job, now time.Time, lastError string) error {
+	return m.BuryFunc(ctx, job, now, lastError)
+}
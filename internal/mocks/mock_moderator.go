@@ -0,0 +1,19 @@
+package mocks
+
+import (
+	"context"
+	"flea-market/internal/moderation"
+	"flea-market/models"
+)
+
+// MockModerator approves every item unless ModerateFunc is set.
+type MockModerator struct {
+	ModerateFunc func(ctx context.Context, item models.Item) (moderation.Verdict, error)
+}
+
+func (m *MockModerator) Moderate(ctx context.Context, item models.Item) (moderation.Verdict, error) {
+	if m.ModerateFunc != nil {
+		return m.ModerateFunc(ctx, item)
+	}
+	return moderation.Approved(), nil
+}
@@ -0,0 +1,14 @@
+package mocks
+
+import (
+	"context"
+	"flea-market/models"
+)
+
+type MockNotifier struct {
+	NotifyFunc func(ctx context.Context, userId uint, notificationType models.NotificationType, payload any) error
+}
+
+func (m *MockNotifier) Notify(ctx context.Context, userId uint, notificationType models.NotificationType, payload any) error {
+	return m.NotifyFunc(ctx, userId, notificationType, payload)
+}
@@ -0,0 +1,20 @@
+package mocks
+
+import (
+	"context"
+	"flea-market/models"
+	"time"
+)
+
+type MockOutboxRepository struct {
+	ClaimFunc func(ctx context.Context, limit int, now time.Time, claimedUntil time.Time) ([]models.OutboxEvent, error)
+	SaveFunc  func(ctx context.Context, events []models.OutboxEvent) error
+}
+
+func (m *MockOutboxRepository) Claim(ctx context.Context, limit int, now time.Time, claimedUntil time.Time) ([]models.OutboxEvent, error) {
+	return m.ClaimFunc(ctx, limit, now, claimedUntil)
+}
+
+func (m *MockOutboxRepository) Save(ctx context.Context, events []models.OutboxEvent) error {
+	return m.SaveFunc(ctx, events)
+}
@@ -0,0 +1,15 @@
+package mocks
+
+import "context"
+
+// MockTxManager runs fn without a transaction unless WithinTxFunc is set.
+type MockTxManager struct {
+	WithinTxFunc func(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+func (m *MockTxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if m.WithinTxFunc != nil {
+		return m.WithinTxFunc(ctx, fn)
+	}
+	return fn(ctx)
+}
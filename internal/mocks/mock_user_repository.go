@@ -0,0 +1,23 @@
+package mocks
+
+import (
+	"context"
+	"flea-market/models"
+)
+
+type MockUserRepository struct {
+	FindByIdFunc   func(ctx context.Context, userId uint) (*models.User, error)
+	UpdatePlanFunc func(ctx context.Context, userId uint, plan models.Plan) error
+
+	FindByIdForUpdateFunc func(ctx context.Context, userId uint) (*models.User, error)
+}
+
+func (m *MockUserRepository) FindById(ctx context.Context, userId uint) (*models.User, error) {
+	return m.FindByIdFunc(ctx, userId)
+}
+func (m *MockUserRepository) FindByIdForUpdate(ctx context.Context, userId uint) (*models.User, error) {
+	return m.FindByIdForUpdateFunc(ctx, userId)
+}
+func (m *MockUserRepository) UpdatePlan(ctx context.Context, userId uint, plan models.Plan) error {
+	return m.UpdatePlanFunc(ctx, userId, plan)
+}
@@ -0,0 +1,39 @@
+package mocks
+
+import (
+	"context"
+	"flea-market/models"
+)
+
+type MockWebhookRepository struct {
+	CreateFunc         func(ctx context.Context, webhook models.Webhook) (*models.Webhook, error)
+	FindByUserFunc     func(ctx context.Context, userId uint) (*[]models.Webhook, error)
+	FindByIdFunc       func(ctx context.Context, webhookId uint, userId uint) (*models.Webhook, error)
+	DeleteFunc         func(ctx context.Context, webhookId uint, userId uint) error
+	CreateDeliveryFunc func(ctx context.Context, delivery models.WebhookDelivery) (*models.WebhookDelivery, error)
+	FindDeliveriesFunc func(ctx context.Context, webhookId uint, limit int) (*[]models.WebhookDelivery, error)
+}
+
+func (m *MockWebhookRepository) Create(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	return m.CreateFunc(ctx, webhook)
+}
+
+func (m *MockWebhookRepository) FindByUser(ctx context.Context, userId uint) (*[]models.Webhook, error) {
+	return m.FindByUserFunc(ctx, userId)
+}
+
+func (m *MockWebhookRepository) FindById(ctx context.Context, webhookId uint, userId uint) (*models.Webhook, error) {
+	return m.FindByIdFunc(ctx, webhookId, userId)
+}
+
+func (m *MockWebhookRepository) Delete(ctx context.Context, webhookId uint, userId uint) error {
+	return m.DeleteFunc(ctx, webhookId, userId)
+}
+
+func (m *MockWebhookRepository) CreateDelivery(ctx context.Context, delivery models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	return m.CreateDeliveryFunc(ctx, delivery)
+}
+
+func (m *MockWebhookRepository) FindDeliveries(ctx context.Context, webhookId uint, limit int) (*[]models.WebhookDelivery, error) {
+	return m.FindDeliveriesFunc(ctx, webhookId, limit)
+}
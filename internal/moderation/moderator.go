@@ -0,0 +1,21 @@
+// Package moderation decides whether a listing can be shown before anyone sees it.
+package moderation
+
+import (
+	"context"
+	"flea-market/models"
+)
+
+// Verdict is the status given to an item. Reason is empty when the item is approved.
+type Verdict struct {
+	Status models.ModerationStatus
+	Reason string
+}
+
+func Approved() Verdict {
+	return Verdict{Status: models.ModerationApproved}
+}
+
+type Moderator interface {
+	Moderate(ctx context.Context, item models.Item) (Verdict, error)
+}
@@ -0,0 +1,152 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"flea-market/internal/money"
+	"flea-market/models"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed rules.yaml
+var defaultRules []byte
+
+// Rules are matched against the name, the description and the tags of an item after normalizing them,
+// so that full-width letters and upper cases don't slip through. Words and patterns should be lower case.
+type Rules struct {
+	// an item containing any of them is rejected
+	BannedWords []string `json:"bannedWords"`
+	// regular expressions. An item matching any of them is rejected
+	BannedPatterns []string `json:"bannedPatterns"`
+	// an item containing any of them waits for review
+	ReviewWords []string `json:"reviewWords"`
+	// an item cheaper than it waits for review. Prices are in money.DefaultCurrency
+	MinPrice uint `json:"minPrice"`
+	// too cheap for what it is, which is typical of fraud
+	PriceFloors []PriceFloor `json:"priceFloors"`
+}
+
+// PriceFloor makes an item containing any of Words wait for review when it's cheaper than MinPrice.
+type PriceFloor struct {
+	Words    []string `json:"words"`
+	MinPrice uint     `json:"minPrice"`
+}
+
+// DefaultRules are embedded from rules.yaml.
+func DefaultRules() Rules {
+	rules, err := ParseRules(defaultRules)
+	if err != nil {
+		panic("rules.yaml is invalid: " + err.Error())
+	}
+	return *rules
+}
+
+// ReadRules reads rules from a YAML or JSON file.
+func ReadRules(path string) (*Rules, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := ParseRules(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rules, nil
+}
+
+// ParseRules parses YAML, which includes JSON. It's converted to JSON first, so that the fields are named only by the json tags.
+func ParseRules(data []byte) (*Rules, error) {
+	var doc any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	var rules Rules
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, err
+	}
+	return &rules, nil
+}
+
+// RuleModerator moderates items by Rules. Rejecting rules are checked before the ones for review.
+// Prices in the other currencies are converted by rates to compare with the rules.
+type RuleModerator struct {
+	rules    Rules
+	patterns []*regexp.Regexp
+	rates    money.RateProvider
+}
+
+func NewRuleModerator(rules Rules, rates money.RateProvider) (*RuleModerator, error) {
+	patterns := make([]*regexp.Regexp, 0, len(rules.BannedPatterns))
+	for _, pattern := range rules.BannedPatterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("banned pattern %q: %w", pattern, err)
+		}
+		patterns = append(patterns, re)
+	}
+	return &RuleModerator{rules: rules, patterns: patterns, rates: rates}, nil
+}
+
+func (m *RuleModerator) Moderate(ctx context.Context, item models.Item) (Verdict, error) {
+	text := normalize(item)
+
+	if word, ok := findWord(text, m.rules.BannedWords); ok {
+		return Verdict{Status: models.ModerationRejected, Reason: fmt.Sprintf("contains banned word %q", word)}, nil
+	}
+	for _, re := range m.patterns {
+		if re.MatchString(text) {
+			return Verdict{Status: models.ModerationRejected, Reason: fmt.Sprintf("matches banned pattern %q", re.String())}, nil
+		}
+	}
+
+	if word, ok := findWord(text, m.rules.ReviewWords); ok {
+		return Verdict{Status: models.ModerationPending, Reason: fmt.Sprintf("contains %q", word)}, nil
+	}
+	price, err := money.Convert(ctx, m.rates, item.Money(), money.DefaultCurrency)
+	if err != nil {
+		return Verdict{}, err
+	}
+	if minPrice := money.New(uint64(m.rules.MinPrice), money.DefaultCurrency); price.Amount < minPrice.Amount {
+		return Verdict{Status: models.ModerationPending, Reason: fmt.Sprintf("price %s is below %s", item.Money(), minPrice)}, nil
+	}
+	for _, floor := range m.rules.PriceFloors {
+		minPrice := money.New(uint64(floor.MinPrice), money.DefaultCurrency)
+		if word, ok := findWord(text, floor.Words); ok && price.Amount < minPrice.Amount {
+			return Verdict{Status: models.ModerationPending, Reason: fmt.Sprintf("price %s is below %s for %q", item.Money(), minPrice, word)}, nil
+		}
+	}
+	return Approved(), nil
+}
+
+// normalize joins the text of item in NFKC and lower case, which folds full-width letters and half-width kana.
+func normalize(item models.Item) string {
+	parts := []string{item.Name, item.Description}
+	for _, tag := range item.Tags {
+		parts = append(parts, tag.Name)
+	}
+	return strings.ToLower(norm.NFKC.String(strings.Join(parts, "\n")))
+}
+
+func findWord(text string, words []string) (string, bool) {
+	for _, word := range words {
+		if strings.Contains(text, strings.ToLower(norm.NFKC.String(word))) {
+			return word, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,66 @@
+package moderation
+
+import (
+	"context"
+	"flea-market/internal/money"
+	"flea-market/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleModerator_Moderate(t *testing.T) {
+	rates, err := money.ParseRates([]byte("base: JPY\nrates: {USD: '0.01'}"))
+	assert.NoError(t, err)
+	moderator, err := NewRuleModerator(Rules{
+		BannedWords:    []string{"counterfeit"},
+		BannedPatterns: []string{`line\s*id`},
+		ReviewWords:    []string{"チケット"},
+		MinPrice:       100,
+		PriceFloors:    []PriceFloor{{Words: []string{"iphone"}, MinPrice: 10000}},
+	}, rates)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name   string
+		item   models.Item
+		status models.ModerationStatus
+		reason string
+	}{
+		{"approved", models.Item{Name: "Vintage camera", Price: 5000}, models.ModerationApproved, ""},
+		{"banned word in full-width", models.Item{Name: "ＣＯＵＮＴＥＲＦＥＩＴ bag", Price: 5000}, models.ModerationRejected, `contains banned word "counterfeit"`},
+		{"banned word in a tag", models.Item{Name: "bag", Price: 5000, Tags: []models.Tag{{Name: "counterfeit"}}}, models.ModerationRejected, `contains banned word "counterfeit"`},
+		{"banned pattern", models.Item{Name: "bag", Description: "LINE ID: abcd", Price: 5000}, models.ModerationRejected, `matches banned pattern "line\\s*id"`},
+		{"review word", models.Item{Name: "ライブチケット", Price: 5000}, models.ModerationPending, `contains "チケット"`},
+		{"below min price", models.Item{Name: "pen", Price: 10}, models.ModerationPending, "price ¥10 is below ¥100"},
+		{"below price floor", models.Item{Name: "iPhone 15", Price: 3000}, models.ModerationPending, `price ¥3,000 is below ¥10,000 for "iphone"`},
+		// $50.00 is ¥5,000
+		{"below price floor in dollars", models.Item{Name: "iPhone 15", Price: 5000, Currency: money.USD}, models.ModerationPending, `price $50.00 is below ¥10,000 for "iphone"`},
+		{"above price floor in dollars", models.Item{Name: "iPhone 15", Price: 50000, Currency: money.USD}, models.ModerationApproved, ""},
+		{"above price floor", models.Item{Name: "iPhone 15", Price: 80000}, models.ModerationApproved, ""},
+		// rejecting rules come first
+		{"banned and cheap", models.Item{Name: "counterfeit iphone", Price: 10}, models.ModerationRejected, `contains banned word "counterfeit"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, err := moderator.Moderate(context.Background(), tt.item)
+			assert.NoError(t, err)
+			assert.Equal(t, Verdict{Status: tt.status, Reason: tt.reason}, verdict)
+		})
+	}
+}
+
+func TestNewRuleModerator_InvalidPattern(t *testing.T) {
+	_, err := NewRuleModerator(Rules{BannedPatterns: []string{"("}}, money.DefaultRates())
+	assert.ErrorContains(t, err, `banned pattern "("`)
+}
+
+func TestDefaultRules(t *testing.T) {
+	rules := DefaultRules()
+	assert.NotEmpty(t, rules.BannedWords)
+	_, err := NewRuleModerator(rules, money.DefaultRates())
+	assert.NoError(t, err)
+
+	_, err = ParseRules([]byte("bannedWord: [x]"))
+	assert.ErrorContains(t, err, "bannedWord")
+}
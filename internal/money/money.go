@@ -0,0 +1,142 @@
+// Package money handles prices in several currencies. Amounts are integers in the minor unit of the currency,
+// e.g. cents for USD and yen for JPY, so that they're never rounded by floats.
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Currency is an ISO 4217 code in upper case.
+type Currency string
+
+const (
+	JPY Currency = "JPY"
+	USD Currency = "USD"
+	EUR Currency = "EUR"
+	GBP Currency = "GBP"
+	KRW Currency = "KRW"
+)
+
+// DefaultCurrency is the currency of the prices made before multi-currency.
+const DefaultCurrency = JPY
+
+type currencyInfo struct {
+	minorUnits int
+	symbol     string
+	// limits of a listing price in minor units
+	min, max uint64
+}
+
+var currencies = map[Currency]currencyInfo{
+	JPY: {minorUnits: 0, symbol: "¥", min: 1, max: 999999},
+	USD: {minorUnits: 2, symbol: "$", min: 50, max: 999999},
+	EUR: {minorUnits: 2, symbol: "€", min: 50, max: 999999},
+	GBP: {minorUnits: 2, symbol: "£", min: 50, max: 999999},
+	KRW: {minorUnits: 0, symbol: "₩", min: 100, max: 9999999},
+}
+
+func IsSupported(code string) bool {
+	_, ok := currencies[Currency(code)]
+	return ok
+}
+
+// Currencies returns the supported codes.
+func Currencies() []Currency {
+	return []Currency{JPY, USD, EUR, GBP, KRW}
+}
+
+// MinorUnits is the number of decimal places, 2 for USD and 0 for JPY.
+func (c Currency) MinorUnits() int {
+	return currencies[c].minorUnits
+}
+
+// Money is Amount in the minor unit of Currency.
+type Money struct {
+	Amount   uint64
+	Currency Currency
+}
+
+func New(amount uint64, currency Currency) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+var (
+	ErrUnsupportedCurrency = errors.New("currency is not supported")
+	ErrBelowMin            = errors.New("price is below the min")
+	ErrAboveMax            = errors.New("price is above the max")
+)
+
+// Validate checks that m is a supported currency within the limits of a listing price.
+func (m Money) Validate() error {
+	info, ok := currencies[m.Currency]
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnsupportedCurrency, m.Currency)
+	}
+	if m.Amount < info.min {
+		return fmt.Errorf("%w: %s < %s", ErrBelowMin, m, New(info.min, m.Currency))
+	}
+	if m.Amount > info.max {
+		return fmt.Errorf("%w: %s > %s", ErrAboveMax, m, New(info.max, m.Currency))
+	}
+	return nil
+}
+
+// Decimal is the amount in the major unit, like "19.99" or "1000".
+func (m Money) Decimal() string {
+	units := m.Currency.MinorUnits()
+	digits := strconv.FormatUint(m.Amount, 10)
+	if units == 0 {
+		return digits
+	}
+	if len(digits) <= units {
+		digits = strings.Repeat("0", units-len(digits)+1) + digits
+	}
+	return digits[:len(digits)-units] + "." + digits[len(digits)-units:]
+}
+
+// String formats m for display with the symbol and thousands separators, like "$1,234.50" or "¥1,000".
+func (m Money) String() string {
+	decimal := m.Decimal()
+	integer, fraction, hasFraction := strings.Cut(decimal, ".")
+	var b strings.Builder
+	b.WriteString(currencies[m.Currency].symbol)
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(digit)
+	}
+	if hasFraction {
+		b.WriteByte('.')
+		b.WriteString(fraction)
+	}
+	return b.String()
+}
+
+// Convert converts m into to by rate, which is the amount of to for 1 of the currency of m in major units.
+// The result is rounded half away from zero to the minor unit of to.
+func (m Money) Convert(to Currency, rate *big.Rat) Money {
+	amount := new(big.Rat).SetInt(new(big.Int).SetUint64(m.Amount))
+	amount.Mul(amount, rate)
+	amount.Mul(amount, pow10(to.MinorUnits()-m.Currency.MinorUnits()))
+
+	// rounds half up, as amounts aren't negative
+	num, denom := amount.Num(), amount.Denom()
+	quotient, remainder := new(big.Int).QuoRem(num, denom, new(big.Int))
+	if remainder.Mul(remainder, big.NewInt(2)).Cmp(denom) >= 0 {
+		quotient.Add(quotient, big.NewInt(1))
+	}
+	return Money{Amount: quotient.Uint64(), Currency: to}
+}
+
+func pow10(exp int) *big.Rat {
+	ten := big.NewInt(10)
+	if exp >= 0 {
+		return new(big.Rat).SetInt(new(big.Int).Exp(ten, big.NewInt(int64(exp)), nil))
+	}
+	return new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(ten, big.NewInt(int64(-exp)), nil))
+}
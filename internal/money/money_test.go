@@ -0,0 +1,47 @@
+package money
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "¥1,000", New(1000, JPY).String())
+	assert.Equal(t, "¥999", New(999, JPY).String())
+	assert.Equal(t, "$19.99", New(1999, USD).String())
+	assert.Equal(t, "$1,234,567.05", New(123456705, USD).String())
+	assert.Equal(t, "€0.05", New(5, EUR).String())
+	assert.Equal(t, "0.05", New(5, EUR).Decimal())
+}
+
+func TestMoney_Validate(t *testing.T) {
+	assert.NoError(t, New(1, JPY).Validate())
+	assert.ErrorIs(t, New(1000000, JPY).Validate(), ErrAboveMax)
+	// 50 cents
+	assert.ErrorIs(t, New(49, USD).Validate(), ErrBelowMin)
+	assert.NoError(t, New(50, USD).Validate())
+	assert.ErrorIs(t, New(100, "XXX").Validate(), ErrUnsupportedCurrency)
+}
+
+func TestMoney_Convert(t *testing.T) {
+	tests := []struct {
+		name string
+		from Money
+		to   Currency
+		rate *big.Rat
+		want Money
+	}{
+		{"yen to cents", New(1000, JPY), USD, big.NewRat(67, 10000), New(670, USD)},
+		{"rounds half up", New(15, JPY), USD, big.NewRat(1, 1000), New(2, USD)},
+		{"rounds down", New(14, JPY), USD, big.NewRat(1, 1000), New(1, USD)},
+		{"cents to yen", New(1999, USD), JPY, big.NewRat(150, 1), New(2999, JPY)},
+		{"cents to cents", New(1000, USD), EUR, big.NewRat(92, 100), New(920, EUR)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.from.Convert(tt.to, tt.rate))
+		})
+	}
+}
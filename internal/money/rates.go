@@ -0,0 +1,99 @@
+package money
+
+import (
+	"context"
+	_ "embed"
+	"fmt"
+	"math/big"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed rates.yaml
+var defaultRates []byte
+
+// RateProvider gives the amount of to for 1 of from in major units.
+type RateProvider interface {
+	Rate(ctx context.Context, from Currency, to Currency) (*big.Rat, error)
+}
+
+// StaticRates are fixed rates read from a file, relative to a base currency.
+type StaticRates struct {
+	base  Currency
+	rates map[Currency]*big.Rat
+}
+
+type ratesFile struct {
+	Base Currency `yaml:"base"`
+	// decimal strings, so that they're exact
+	Rates map[Currency]string `yaml:"rates"`
+}
+
+// DefaultRates are embedded from rates.yaml.
+func DefaultRates() *StaticRates {
+	rates, err := ParseRates(defaultRates)
+	if err != nil {
+		panic("rates.yaml is invalid: " + err.Error())
+	}
+	return rates
+}
+
+func ReadRates(path string) (*StaticRates, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	rates, err := ParseRates(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rates, nil
+}
+
+// ParseRates parses YAML like "base: JPY" and "rates: {USD: '0.0067'}", where a rate is the amount of the currency for 1 of the base.
+func ParseRates(data []byte) (*StaticRates, error) {
+	var file ratesFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+	if !IsSupported(string(file.Base)) {
+		return nil, fmt.Errorf("base currency %q is not supported", file.Base)
+	}
+	rates := &StaticRates{base: file.Base, rates: map[Currency]*big.Rat{file.Base: big.NewRat(1, 1)}}
+	for currency, value := range file.Rates {
+		if !IsSupported(string(currency)) {
+			return nil, fmt.Errorf("currency %q is not supported", currency)
+		}
+		rate, ok := new(big.Rat).SetString(value)
+		if !ok || rate.Sign() <= 0 {
+			return nil, fmt.Errorf("rate of %s must be a positive decimal: %q", currency, value)
+		}
+		rates.rates[currency] = rate
+	}
+	return rates, nil
+}
+
+func (r *StaticRates) Rate(ctx context.Context, from Currency, to Currency) (*big.Rat, error) {
+	fromRate, ok := r.rates[from]
+	if !ok {
+		return nil, fmt.Errorf("no rate of %s", from)
+	}
+	toRate, ok := r.rates[to]
+	if !ok {
+		return nil, fmt.Errorf("no rate of %s", to)
+	}
+	return new(big.Rat).Quo(toRate, fromRate), nil
+}
+
+// Convert converts m into to by the rate of provider. It doesn't ask provider when the currencies are the same.
+func Convert(ctx context.Context, provider RateProvider, m Money, to Currency) (Money, error) {
+	if m.Currency == to {
+		return m, nil
+	}
+	rate, err := provider.Rate(ctx, m.Currency, to)
+	if err != nil {
+		return Money{}, err
+	}
+	return m.Convert(to, rate), nil
+}
@@ -0,0 +1,36 @@
+package money
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticRates(t *testing.T) {
+	ctx := context.Background()
+	rates, err := ParseRates([]byte("base: JPY\nrates: {USD: '0.0065', EUR: '0.0060'}"))
+	assert.NoError(t, err)
+
+	converted, err := Convert(ctx, rates, New(10000, JPY), USD)
+	assert.NoError(t, err)
+	assert.Equal(t, New(6500, USD), converted)
+
+	// through the base
+	converted, err = Convert(ctx, rates, New(6500, USD), EUR)
+	assert.NoError(t, err)
+	assert.Equal(t, New(6000, EUR), converted)
+
+	_, err = Convert(ctx, rates, New(100, JPY), KRW)
+	assert.ErrorContains(t, err, "no rate of KRW")
+
+	_, err = ParseRates([]byte("base: JPY\nrates: {USD: '-1'}"))
+	assert.ErrorContains(t, err, "positive")
+	_, err = ParseRates([]byte("base: XXX"))
+	assert.ErrorContains(t, err, "not supported")
+
+	for _, currency := range Currencies() {
+		_, err := DefaultRates().Rate(ctx, JPY, currency)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,57 @@
+package openapi
+
+import (
+	"embed"
+	"flea-market/utils"
+	"fmt"
+	"mime"
+	"net/http"
+	"path"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:embed docs.html
+var docsHTML []byte
+
+//go:embed swagger-ui/*.css swagger-ui/*.js
+var swaggerUI embed.FS
+
+// Handler serves the document of the routes of engine.
+// It's built on the first request, because routes are registered after the handler is created.
+func Handler(engine *gin.Engine, title string, version string, operations Operations) gin.HandlerFunc {
+	var (
+		once sync.Once
+		spec Schema
+		err  error
+	)
+	return func(ctx *gin.Context) {
+		once.Do(func() {
+			spec, err = Build(title, version, engine.Routes(), operations)
+		})
+		if err != nil {
+			_ = ctx.Error(utils.NewUnknownError("Build OpenAPI document failed", err))
+			return
+		}
+		ctx.JSON(http.StatusOK, spec)
+	}
+}
+
+// DocsHandler serves Swagger UI that loads /openapi.json.
+func DocsHandler(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", docsHTML)
+}
+
+// AssetsHandler serves the files of Swagger UI bundled in swagger-ui, which docs.html loads from /docs/assets/.
+func AssetsHandler(ctx *gin.Context) {
+	name := ctx.Param("file")
+	data, err := swaggerUI.ReadFile("swagger-ui/" + name)
+	if err != nil {
+		_ = ctx.Error(utils.NewNotFoundError(fmt.Sprintf("asset %q not found", name), err))
+		return
+	}
+	// the files change only with the version of Swagger UI
+	ctx.Header("Cache-Control", "public, max-age=86400")
+	ctx.Data(http.StatusOK, mime.TypeByExtension(path.Ext(name)), data)
+}
@@ -0,0 +1,219 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	timeType      = reflect.TypeOf(time.Time{})
+	deletedAtType = reflect.TypeOf(gorm.DeletedAt{})
+)
+
+// Schema is a JSON Schema object of OpenAPI 3.1.
+type Schema map[string]any
+
+// schemaRegistry turns Go types into schemas. Named structs are put in components and referenced,
+// so that recursive types like Category can be described.
+type schemaRegistry struct {
+	components map[string]Schema
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{components: map[string]Schema{}}
+}
+
+func (r *schemaRegistry) schemaOf(t reflect.Type) Schema {
+	switch {
+	case t == timeType:
+		return Schema{"type": "string", "format": "date-time"}
+	case t == deletedAtType:
+		return Schema{"type": []string{"string", "null"}, "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(r.schemaOf(t.Elem()))
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return Schema{"type": "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return Schema{"type": "array", "items": r.schemaOf(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": "object", "additionalProperties": r.schemaOf(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return r.structSchema(t)
+		}
+		if _, ok := r.components[t.Name()]; !ok {
+			// reserve the name before walking the fields to stop recursion
+			r.components[t.Name()] = Schema{}
+			r.components[t.Name()] = r.structSchema(t)
+		}
+		return Schema{"$ref": "#/components/schemas/" + t.Name()}
+	}
+	return Schema{}
+}
+
+func (r *schemaRegistry) structSchema(t reflect.Type) Schema {
+	properties := Schema{}
+	required := []string{}
+	r.addFields(t, properties, &required)
+
+	schema := Schema{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// addFields flattens embedded structs like gorm.Model in the same way as encoding/json.
+func (r *schemaRegistry) addFields(t reflect.Type, properties Schema, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, ok := jsonName(field)
+		if !ok {
+			continue
+		}
+		if field.Anonymous && name == "" && field.Type.Kind() == reflect.Struct {
+			r.addFields(field.Type, properties, required)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema := r.schemaOf(field.Type)
+		if applyBinding(schema, field.Type, field.Tag.Get("binding")) {
+			*required = append(*required, name)
+		}
+		properties[name] = schema
+	}
+}
+
+// jsonName returns "" when the field has no name in its tag, and false when it's skipped.
+func jsonName(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	return name, true
+}
+
+// parameters describes a query struct like dto.ItemQuery, whose fields have "form" tags.
+func (r *schemaRegistry) parameters(t reflect.Type) []Schema {
+	params := []Schema{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("form"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		schema := r.schemaOf(fieldType)
+		params = append(params, Schema{
+			"name":     name,
+			"in":       "query",
+			"required": applyBinding(schema, fieldType, field.Tag.Get("binding")),
+			"schema":   schema,
+		})
+	}
+	return params
+}
+
+// applyBinding translates the validator rules of gin's "binding" tag into schema constraints
+// and reports whether the field is required. Unknown rules are ignored.
+func applyBinding(schema Schema, t reflect.Type, binding string) bool {
+	if binding == "" {
+		return false
+	}
+	required := false
+	target, targetType := schema, t
+	// "min" and "max" are for the elements after "dive"
+	for _, rule := range strings.Split(binding, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+		case "dive":
+			targetType = elemType(targetType)
+			items, ok := itemsOf(target)
+			if !ok {
+				return required
+			}
+			target = items
+		case "min", "max":
+			n, err := strconv.Atoi(param)
+			if err != nil {
+				continue
+			}
+			target[limitKeyword(name, targetType)] = n
+		case "email":
+			target["format"] = "email"
+		case "url", "http_url":
+			target["format"] = "uri"
+		case "unique":
+			target["uniqueItems"] = true
+		case "oneof":
+			target["enum"] = strings.Fields(param)
+		}
+	}
+	return required
+}
+
+func limitKeyword(rule string, t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return rule + "Length"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return rule + "Items"
+	}
+	return rule + "imum"
+}
+
+func elemType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array || t.Kind() == reflect.Map {
+		return t.Elem()
+	}
+	return t
+}
+
+func itemsOf(schema Schema) (Schema, bool) {
+	items, ok := schema["items"].(Schema)
+	return items, ok
+}
+
+// nullable allows null in addition to the schema. Constraints are still added to the returned schema.
+func nullable(schema Schema) Schema {
+	if _, ok := schema["$ref"]; ok {
+		return Schema{"anyOf": []Schema{schema, {"type": "null"}}}
+	}
+	if typ, ok := schema["type"].(string); ok {
+		schema["type"] = []string{typ, "null"}
+	}
+	return schema
+}
@@ -0,0 +1,249 @@
+package openapi
+
+import (
+	"errors"
+	"flea-market/dto"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Operation describes a route registered to gin.
+// Types are given as zero values like dto.CreateItemInput{}, and the schemas are derived by reflection.
+type Operation struct {
+	Summary string
+	Tag     string
+	// the route is behind AuthMiddleware
+	Auth bool
+	// struct with "form" tags
+	Query any
+	// JSON request body
+	Request any
+	// name of the file field of a multipart/form-data request body
+	Upload string
+	// the value responded as {"data": ..., "meta": ...}. nil means no body.
+	Response any
+	// Response is the whole body instead of "data"
+	Unwrapped bool
+	// Response is responded as {"data": ...} without "meta", as v1 did before the envelope
+	Legacy bool
+	// defaults to application/json
+	ContentType string
+	// defaults to 200
+	Status int
+	// 400, 401 and 500 are added automatically when they can happen
+	Errors []int
+	// the route isn't part of the versioned API, e.g. the docs
+	Unversioned bool
+}
+
+// Operations is keyed by "METHOD /path" in gin's syntax without the version prefix, e.g. "GET /items/:id".
+// A route which differs in a version can be described with the prefix, e.g. "GET /v2/items/:id".
+// Routes of the API without the version prefix are legacy aliases and marked as deprecated.
+type Operations map[string]Operation
+
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+/`)
+
+func (o Operations) find(method string, path string) (Operation, string, bool) {
+	key := method + " " + path
+	if operation, ok := o[key]; ok {
+		return operation, key, true
+	}
+	if prefix := versionPrefix.FindString(path); prefix != "" {
+		key = method + " /" + strings.TrimPrefix(path, prefix)
+		operation, ok := o[key]
+		return operation, key, ok
+	}
+	return Operation{}, "", false
+}
+
+// Build generates the OpenAPI document of routes.
+// Routes without an operation and operations without a route are reported as an error,
+// so that the document can't silently drift from the router.
+func Build(title string, version string, routes gin.RoutesInfo, operations Operations) (Schema, error) {
+	registry := newSchemaRegistry()
+	paths := Schema{}
+
+	var errs []error
+	registered := map[string]bool{}
+	for _, route := range routes {
+		operation, key, ok := operations.find(route.Method, route.Path)
+		if !ok {
+			errs = append(errs, fmt.Errorf("route %s %s has no OpenAPI operation", route.Method, route.Path))
+			continue
+		}
+		registered[key] = true
+
+		path, params := openAPIPath(route.Path)
+		item, ok := paths[path].(Schema)
+		if !ok {
+			item = Schema{}
+			paths[path] = item
+		}
+		result := registry.operation(route.Method, operation, params)
+		if !operation.Unversioned && !versionPrefix.MatchString(route.Path) {
+			result["deprecated"] = true
+		}
+		item[strings.ToLower(route.Method)] = result
+	}
+	for key := range operations {
+		if !registered[key] {
+			errs = append(errs, fmt.Errorf("OpenAPI operation %s has no route", key))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	registry.components["APIError"] = Schema{
+		"type": "object",
+		"properties": Schema{
+			// one of utils.MessageCode
+			"error": Schema{"type": "string", "pattern": `^[IWE]\d{3}-\d{5}$`, "examples": []string{"I001-00011"}},
+			// in the locale of Accept-Language
+			"message": Schema{"type": "string"},
+			// only for validation errors
+			"details": Schema{
+				"type": "array",
+				"items": Schema{
+					"type":       "object",
+					"properties": Schema{"field": Schema{"type": "string"}, "message": Schema{"type": "string"}},
+					"required":   []string{"field", "message"},
+				},
+			},
+		},
+		"required": []string{"error", "message"},
+	}
+
+	return Schema{
+		"openapi": "3.1.0",
+		"info":    Schema{"title": title, "version": version},
+		"paths":   paths,
+		"components": Schema{
+			"schemas": registry.components,
+			"securitySchemes": Schema{
+				"bearerAuth": Schema{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"},
+			},
+		},
+	}, nil
+}
+
+func (r *schemaRegistry) operation(method string, operation Operation, pathParams []Schema) Schema {
+	result := Schema{"summary": operation.Summary}
+	if operation.Tag != "" {
+		result["tags"] = []string{operation.Tag}
+	}
+	if operation.Auth {
+		result["security"] = []Schema{{"bearerAuth": []string{}}}
+	}
+
+	params := slices.Clone(pathParams)
+	if operation.Query != nil {
+		params = append(params, r.parameters(reflect.TypeOf(operation.Query))...)
+	}
+	// IdempotencyMiddleware is placed after AuthMiddleware
+	idempotent := operation.Auth && method == http.MethodPost
+	if idempotent {
+		params = append(params, Schema{
+			"name":        "Idempotency-Key",
+			"in":          "header",
+			"required":    false,
+			"description": "A retry with the same key gets the stored response with Idempotent-Replayed: true",
+			"schema":      Schema{"type": "string", "maxLength": 255},
+		})
+	}
+	if len(params) > 0 {
+		result["parameters"] = params
+	}
+
+	if operation.Request != nil {
+		result["requestBody"] = Schema{
+			"required": true,
+			"content":  Schema{"application/json": Schema{"schema": r.schemaOf(reflect.TypeOf(operation.Request))}},
+		}
+	}
+	if operation.Upload != "" {
+		result["requestBody"] = Schema{
+			"required": true,
+			"content": Schema{"multipart/form-data": Schema{"schema": Schema{
+				"type":       "object",
+				"properties": Schema{operation.Upload: Schema{"type": "string", "contentMediaType": "application/octet-stream"}},
+				"required":   []string{operation.Upload},
+			}}},
+		}
+	}
+
+	status := operation.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	success := Schema{"description": http.StatusText(status)}
+	if operation.Response != nil {
+		contentType := operation.ContentType
+		if contentType == "" {
+			contentType = "application/json"
+		}
+		schema := r.schemaOf(reflect.TypeOf(operation.Response))
+		switch {
+		case operation.Unwrapped:
+		case operation.Legacy:
+			schema = Schema{"type": "object", "properties": Schema{"data": schema}, "required": []string{"data"}}
+		default:
+			schema = Schema{
+				"type":       "object",
+				"properties": Schema{"data": schema, "meta": r.schemaOf(reflect.TypeOf(dto.Meta{}))},
+				"required":   []string{"data", "meta"},
+			}
+		}
+		success["content"] = Schema{contentType: Schema{"schema": schema}}
+	}
+	responses := Schema{fmt.Sprint(status): success}
+
+	errorStatuses := slices.Clone(operation.Errors)
+	if len(params) > 0 || operation.Request != nil || operation.Upload != "" {
+		errorStatuses = append(errorStatuses, http.StatusBadRequest)
+	}
+	if operation.Auth {
+		errorStatuses = append(errorStatuses, http.StatusUnauthorized)
+	}
+	if idempotent {
+		// the key is used for another request, or the first request is in flight
+		errorStatuses = append(errorStatuses, http.StatusConflict, http.StatusTooEarly)
+	}
+	errorStatuses = append(errorStatuses, http.StatusInternalServerError)
+	for _, errorStatus := range errorStatuses {
+		responses[fmt.Sprint(errorStatus)] = Schema{
+			"description": http.StatusText(errorStatus),
+			"content": Schema{"application/json": Schema{
+				"schema": Schema{"$ref": "#/components/schemas/APIError"},
+			}},
+		}
+	}
+	result["responses"] = responses
+	return result
+}
+
+// openAPIPath converts "/items/:id" to "/items/{id}". Every path parameter of this API is an id.
+func openAPIPath(path string) (string, []Schema) {
+	segments := strings.Split(path, "/")
+	params := []Schema{}
+	for i, segment := range segments {
+		name, ok := strings.CutPrefix(segment, ":")
+		if !ok {
+			continue
+		}
+		segments[i] = "{" + name + "}"
+		params = append(params, Schema{
+			"name":     name,
+			"in":       "path",
+			"required": true,
+			"schema":   Schema{"type": "integer", "minimum": 1},
+		})
+	}
+	return strings.Join(segments, "/"), params
+}
@@ -0,0 +1,126 @@
+package openapi
+
+import (
+	"flea-market/dto"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaOf_BindingConstraints(t *testing.T) {
+	registry := newSchemaRegistry()
+	ref := registry.schemaOf(reflect.TypeOf(dto.CreateItemInput{}))
+	assert.Equal(t, "#/components/schemas/CreateItemInput", ref["$ref"])
+
+	schema := registry.components["CreateItemInput"]
+	assert.ElementsMatch(t, []string{"name", "price"}, schema["required"])
+
+	properties := schema["properties"].(Schema)
+	assert.Equal(t, 2, properties["name"].(Schema)["minLength"])
+	assert.Equal(t, 1, properties["price"].(Schema)["minimum"])
+	assert.Equal(t, []string{"JPY", "USD", "EUR", "GBP", "KRW"}, properties["currency"].(Schema)["enum"])
+	assert.Equal(t, []string{"new", "like-new", "good", "fair", "poor"}, properties["condition"].(Schema)["enum"])
+	assert.Equal(t, []string{"integer", "null"}, properties["categoryId"].(Schema)["type"])
+
+	tags := properties["tags"].(Schema)
+	assert.Equal(t, 10, tags["maxItems"])
+	assert.Equal(t, 1, tags["items"].(Schema)["minLength"])
+	assert.Equal(t, 30, tags["items"].(Schema)["maxLength"])
+
+	registry.schemaOf(reflect.TypeOf(dto.SignupInput{}))
+	email := registry.components["SignupInput"]["properties"].(Schema)["email"].(Schema)
+	assert.Equal(t, "email", email["format"])
+
+	registry.schemaOf(reflect.TypeOf(dto.CreateWebhookInput{}))
+	webhook := registry.components["CreateWebhookInput"]["properties"].(Schema)
+	assert.Equal(t, "uri", webhook["url"].(Schema)["format"])
+	assert.Equal(t, true, webhook["events"].(Schema)["uniqueItems"])
+	assert.Equal(t, []string{"offer_received", "item_sold"}, webhook["events"].(Schema)["items"].(Schema)["enum"])
+}
+
+func TestBuild(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := func(ctx *gin.Context) {}
+	router := gin.New()
+	router.GET("/items/:id", handler)
+	router.POST("/items", handler)
+
+	operations := Operations{
+		"GET /items/:id": {Summary: "find", Auth: true, Response: dto.UserPublicResponse{}},
+		"POST /items":    {Summary: "create", Request: dto.CreateItemInput{}, Status: http.StatusCreated},
+	}
+
+	spec, err := Build("test", "1", router.Routes(), operations)
+	assert.NoError(t, err)
+
+	find := spec["paths"].(Schema)["/items/{id}"].(Schema)["get"].(Schema)
+	assert.Equal(t, "id", find["parameters"].([]Schema)[0]["name"])
+	assert.Contains(t, find["responses"], "401")
+	assert.Contains(t, find["responses"], "400")
+	create := spec["paths"].(Schema)["/items"].(Schema)["post"].(Schema)
+	assert.Contains(t, create["responses"], "201")
+	assert.NotContains(t, create["responses"], "401")
+
+	t.Run("route without operation", func(t *testing.T) {
+		router.DELETE("/items/:id", handler)
+		_, err := Build("test", "1", router.Routes(), operations)
+		assert.ErrorContains(t, err, "route DELETE /items/:id has no OpenAPI operation")
+	})
+
+	t.Run("operation without route", func(t *testing.T) {
+		operations["PUT /items/:id"] = Operation{Summary: "update"}
+		_, err := Build("test", "1", gin.RoutesInfo{}, operations)
+		assert.ErrorContains(t, err, "OpenAPI operation PUT /items/:id has no route")
+	})
+}
+
+func TestBuild_IdempotencyKey(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := func(ctx *gin.Context) {}
+	router := gin.New()
+	router.POST("/items", handler)
+	router.PUT("/items/:id", handler)
+
+	spec, err := Build("test", "1", router.Routes(), Operations{
+		"POST /items":    {Summary: "create", Auth: true, Request: dto.CreateItemInput{}},
+		"PUT /items/:id": {Summary: "update", Auth: true, Request: dto.UpdateItemInput{}},
+	})
+	assert.NoError(t, err)
+
+	create := spec["paths"].(Schema)["/items"].(Schema)["post"].(Schema)
+	assert.Equal(t, "Idempotency-Key", create["parameters"].([]Schema)[0]["name"])
+	assert.Contains(t, create["responses"], "409")
+	assert.Contains(t, create["responses"], "425")
+	update := spec["paths"].(Schema)["/items/{id}"].(Schema)["put"].(Schema)
+	assert.Len(t, update["parameters"], 1)
+	assert.NotContains(t, update["responses"], "425")
+}
+
+func TestBuild_Versions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	handler := func(ctx *gin.Context) {}
+	router := gin.New()
+	router.GET("/v1/items", handler)
+	router.GET("/v2/items", handler)
+	router.GET("/items", handler)
+	router.GET("/docs", handler)
+
+	operations := Operations{
+		"GET /items":    {Summary: "v1"},
+		"GET /v2/items": {Summary: "v2"},
+		"GET /docs":     {Summary: "docs", Unversioned: true},
+	}
+
+	spec, err := Build("test", "1", router.Routes(), operations)
+	assert.NoError(t, err)
+
+	paths := spec["paths"].(Schema)
+	assert.Equal(t, "v1", paths["/v1/items"].(Schema)["get"].(Schema)["summary"])
+	assert.Equal(t, "v2", paths["/v2/items"].(Schema)["get"].(Schema)["summary"])
+	assert.NotContains(t, paths["/v1/items"].(Schema)["get"], "deprecated")
+	assert.Equal(t, true, paths["/items"].(Schema)["get"].(Schema)["deprecated"])
+	assert.NotContains(t, paths["/docs"].(Schema)["get"], "deprecated")
+}
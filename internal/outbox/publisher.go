@@ -0,0 +1,104 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+type Publisher interface {
+	Publish(ctx context.Context, event models.OutboxEvent) error
+}
+
+// Message is the published form of an event.
+type Message struct {
+	// unique per event, to deduplicate redeliveries
+	ID            uint            `json:"id"`
+	Type          string          `json:"type"`
+	AggregateType string          `json:"aggregateType"`
+	AggregateID   uint            `json:"aggregateId"`
+	Payload       json.RawMessage `json:"payload"`
+	CreatedAt     time.Time       `json:"createdAt"`
+}
+
+func NewMessage(event models.OutboxEvent) Message {
+	return Message{
+		ID:            event.ID,
+		Type:          event.Type,
+		AggregateType: event.AggregateType,
+		AggregateID:   event.AggregateID,
+		Payload:       json.RawMessage(event.Payload),
+		CreatedAt:     event.CreatedAt,
+	}
+}
+
+// LogPublisher only logs events. It's the default until a consumer exists.
+type LogPublisher struct{}
+
+func (LogPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	utils.Logger(utils.OutboxEventPublished, "", "", "", event.ID, event.Type, event.AggregateType, event.AggregateID, event.Payload)
+	return nil
+}
+
+// WebhookPublisher POSTs each event as a Message to url. A response other than 2xx fails the event.
+type WebhookPublisher struct {
+	client *resty.Client
+	url    string
+}
+
+func NewWebhookPublisher(client *resty.Client, url string) *WebhookPublisher {
+	return &WebhookPublisher{client: client, url: url}
+}
+
+func (p *WebhookPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	resp, err := p.client.R().
+		SetContext(ctx).
+		// the receiver can deduplicate redeliveries with it
+		SetHeader("Idempotency-Key", fmt.Sprintf("outbox-%d", event.ID)).
+		SetBody(NewMessage(event)).
+		Post(p.url)
+	if err != nil {
+		return utils.NewExternalAPIConnectionError("publish outbox event", err)
+	}
+	if resp.IsError() {
+		return utils.NewExternalAPIReturnsError(fmt.Sprintf("publish outbox event: status %d", resp.StatusCode()), nil)
+	}
+	return nil
+}
+
+// MemoryPublisher keeps published events in memory for tests.
+// Err is returned instead of publishing while it's set.
+type MemoryPublisher struct {
+	mu     sync.Mutex
+	events []models.OutboxEvent
+	Err    error
+}
+
+func (p *MemoryPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.Err != nil {
+		return p.Err
+	}
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *MemoryPublisher) Events() []models.OutboxEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Clone(p.events)
+}
+
+func (p *MemoryPublisher) SetErr(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.Err = err
+}
@@ -0,0 +1,55 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"flea-market/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookPublisher_Publish(t *testing.T) {
+	createdAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	event := models.OutboxEvent{
+		ID: 7, AggregateType: models.AggregateItem, AggregateID: 3, Type: models.EventItemCreated,
+		Payload: `{"id":3,"name":"book"}`, CreatedAt: createdAt,
+	}
+
+	t.Run("posts the message", func(t *testing.T) {
+		var received Message
+		var key string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key = r.Header.Get("Idempotency-Key")
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			w.WriteHeader(http.StatusAccepted)
+		}))
+		defer server.Close()
+
+		err := NewWebhookPublisher(resty.New(), server.URL).Publish(context.Background(), event)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "outbox-7", key)
+		assert.Equal(t, uint(7), received.ID)
+		assert.Equal(t, models.EventItemCreated, received.Type)
+		assert.Equal(t, models.AggregateItem, received.AggregateType)
+		assert.Equal(t, uint(3), received.AggregateID)
+		assert.JSONEq(t, event.Payload, string(received.Payload))
+		assert.True(t, createdAt.Equal(received.CreatedAt))
+	})
+
+	t.Run("fails on error status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+
+		err := NewWebhookPublisher(resty.New(), server.URL).Publish(context.Background(), event)
+
+		assert.ErrorContains(t, err, "status 503")
+	})
+}
@@ -0,0 +1,133 @@
+// Package outbox publishes the domain events written to the outbox table in the same transaction as the changes.
+//
+// Delivery is at least once: an event is published again when saving its result fails,
+// so consumers should deduplicate by Message.ID.
+package outbox
+
+import (
+	"context"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	defaultBatchSize = 100
+	retryBaseDelay   = 5 * time.Second
+	maxRetryDelay    = 10 * time.Minute
+	// longer than publishing a batch takes. Events claimed by a relay which has stopped are published again after that.
+	claimTimeout = 30 * time.Minute
+)
+
+type IOutboxRepository interface {
+	Claim(ctx context.Context, limit int, now time.Time, claimedUntil time.Time) ([]models.OutboxEvent, error)
+	Save(ctx context.Context, events []models.OutboxEvent) error
+}
+
+// Relay polls the outbox and publishes unpublished events.
+// An event which failed blocks the later events of the same aggregate until it's published,
+// so that the events of an aggregate are delivered in order. Other aggregates go on.
+type Relay struct {
+	repository IOutboxRepository
+	publisher  Publisher
+	interval   time.Duration
+	batchSize  int
+}
+
+func NewRelay(repository IOutboxRepository, publisher Publisher, interval time.Duration) *Relay {
+	return &Relay{repository: repository, publisher: publisher, interval: interval, batchSize: defaultBatchSize}
+}
+
+// Run blocks until ctx is canceled.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.relay(ctx)
+		}
+	}
+}
+
+// relay publishes batches until the outbox is drained or nothing more can be published.
+func (r *Relay) relay(ctx context.Context) {
+	for ctx.Err() == nil {
+		now := time.Now()
+		events, err := r.repository.Claim(ctx, r.batchSize, now, now.Add(claimTimeout))
+		if err != nil {
+			if ctx.Err() == nil {
+				logError(err)
+			}
+			return
+		}
+		if len(events) == 0 {
+			return
+		}
+
+		events = r.publish(ctx, events, time.Now())
+		if err := r.repository.Save(ctx, events); err != nil {
+			if ctx.Err() == nil {
+				logError(err)
+			}
+			return
+		}
+		full := len(events) == r.batchSize
+		progressed := slices.ContainsFunc(events, func(event models.OutboxEvent) bool { return event.PublishedAt != nil })
+		if !full || !progressed {
+			return
+		}
+	}
+}
+
+func logError(err error) {
+	if apiErr, ok := err.(*utils.APIError); ok {
+		utils.Logger(apiErr.MessageCode, "", "", "", err.Error())
+		return
+	}
+	utils.Logger(utils.UnknownError, "", "", "", err.Error())
+}
+
+// publish publishes events in order and returns them with the results.
+// Events after a failed one of the same aggregate are returned as they are.
+func (r *Relay) publish(ctx context.Context, events []models.OutboxEvent, now time.Time) []models.OutboxEvent {
+	blocked := map[string]bool{}
+	results := make([]models.OutboxEvent, 0, len(events))
+	for _, event := range events {
+		aggregate := fmt.Sprintf("%s/%d", event.AggregateType, event.AggregateID)
+		if blocked[aggregate] {
+			results = append(results, event)
+			continue
+		}
+
+		if err := r.publisher.Publish(ctx, event); err != nil {
+			blocked[aggregate] = true
+			event.Attempts++
+			nextAttemptAt := now.Add(backoff(event.Attempts))
+			event.NextAttemptAt = &nextAttemptAt
+			event.LastError = err.Error()
+			utils.Logger(utils.OutboxPublishFailed, "", "", "", event.ID, event.Attempts, nextAttemptAt.Format(time.RFC3339), err.Error())
+		} else {
+			event.PublishedAt = &now
+		}
+		results = append(results, event)
+	}
+	return results
+}
+
+// backoff doubles the delay every attempt up to 10 minutes.
+// Events are never given up, because it would break the order of the aggregate.
+func backoff(attempts int) time.Duration {
+	if attempts <= 1 {
+		return retryBaseDelay
+	}
+	if attempts > 16 {
+		return maxRetryDelay
+	}
+	return min(retryBaseDelay<<(attempts-1), maxRetryDelay)
+}
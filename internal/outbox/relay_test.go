@@ -0,0 +1,163 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"flea-market/internal/mocks"
+	"flea-market/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// newTestRelay returns a relay over an in-memory outbox holding events.
+func newTestRelay(events []models.OutboxEvent, publisher Publisher) (*Relay, *[]models.OutboxEvent) {
+	repo := &mocks.MockOutboxRepository{
+		// like the query of OutboxRepository.Claim
+		ClaimFunc: func(ctx context.Context, limit int, now time.Time, claimedUntil time.Time) ([]models.OutboxEvent, error) {
+			waiting := map[uint]bool{}
+			var claimed []models.OutboxEvent
+			for _, event := range events {
+				if event.PublishedAt != nil {
+					continue
+				}
+				if event.NextAttemptAt != nil && event.NextAttemptAt.After(now) {
+					waiting[event.AggregateID] = true
+				}
+				if !waiting[event.AggregateID] && len(claimed) < limit {
+					claimed = append(claimed, event)
+				}
+			}
+			return claimed, nil
+		},
+		SaveFunc: func(ctx context.Context, saved []models.OutboxEvent) error {
+			for _, event := range saved {
+				for i := range events {
+					if events[i].ID == event.ID {
+						events[i] = event
+					}
+				}
+			}
+			return nil
+		},
+	}
+	return NewRelay(repo, publisher, time.Second), &events
+}
+
+func itemEvent(id uint, itemId uint) models.OutboxEvent {
+	return models.OutboxEvent{ID: id, AggregateType: models.AggregateItem, AggregateID: itemId, Type: models.EventItemUpdated, Payload: "{}"}
+}
+
+func publishedIds(publisher *MemoryPublisher) []uint {
+	ids := []uint{}
+	for _, event := range publisher.Events() {
+		ids = append(ids, event.ID)
+	}
+	return ids
+}
+
+func TestRelay_PublishesInOrder(t *testing.T) {
+	publisher := &MemoryPublisher{}
+	relay, events := newTestRelay([]models.OutboxEvent{itemEvent(1, 1), itemEvent(2, 2), itemEvent(3, 1)}, publisher)
+
+	relay.relay(context.Background())
+
+	assert.Equal(t, []uint{1, 2, 3}, publishedIds(publisher))
+	for _, event := range *events {
+		assert.NotNil(t, event.PublishedAt)
+	}
+}
+
+func TestRelay_DrainsBatches(t *testing.T) {
+	publisher := &MemoryPublisher{}
+	relay, _ := newTestRelay([]models.OutboxEvent{itemEvent(1, 1), itemEvent(2, 1), itemEvent(3, 1)}, publisher)
+	relay.batchSize = 2
+
+	relay.relay(context.Background())
+
+	assert.Equal(t, []uint{1, 2, 3}, publishedIds(publisher))
+}
+
+func TestRelay_FailureBlocksAggregate(t *testing.T) {
+	failing := &failingPublisher{MemoryPublisher: &MemoryPublisher{}, failId: 1}
+	relay, events := newTestRelay([]models.OutboxEvent{itemEvent(1, 1), itemEvent(2, 2), itemEvent(3, 1)}, failing)
+	now := time.Now()
+
+	relay.relay(context.Background())
+
+	// item 2 goes on, and event 3 waits for event 1 of the same item
+	assert.Equal(t, []uint{2}, publishedIds(failing.MemoryPublisher))
+	failed := (*events)[0]
+	assert.Nil(t, failed.PublishedAt)
+	assert.Equal(t, 1, failed.Attempts)
+	assert.Equal(t, "unavailable", failed.LastError)
+	assert.WithinDuration(t, now.Add(retryBaseDelay), *failed.NextAttemptAt, time.Second)
+	assert.Nil(t, (*events)[2].PublishedAt)
+
+	t.Run("waits for the next attempt", func(t *testing.T) {
+		failing.failId = 0
+		relay.relay(context.Background())
+
+		assert.Equal(t, []uint{2}, publishedIds(failing.MemoryPublisher))
+	})
+
+	t.Run("publishes in order after recovery", func(t *testing.T) {
+		changed := relay.publish(context.Background(), (*events)[:1], now.Add(time.Minute))
+		assert.Len(t, changed, 1)
+		assert.NotNil(t, changed[0].PublishedAt)
+		assert.Equal(t, []uint{2, 1}, publishedIds(failing.MemoryPublisher))
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, backoff(1))
+	assert.Equal(t, 10*time.Second, backoff(2))
+	assert.Equal(t, 80*time.Second, backoff(5))
+	assert.Equal(t, maxRetryDelay, backoff(8))
+	assert.Equal(t, maxRetryDelay, backoff(100))
+}
+
+// failingPublisher fails to publish the event of failId.
+type failingPublisher struct {
+	*MemoryPublisher
+	failId uint
+}
+
+func (p *failingPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	if event.ID == p.failId {
+		return errors.New("unavailable")
+	}
+	return p.MemoryPublisher.Publish(ctx, event)
+}
+
+// A slow publisher must not hold the transaction of the claim.
+func TestRelay_PublishesOutOfClaim(t *testing.T) {
+	var calls []string
+	repo := &mocks.MockOutboxRepository{
+		ClaimFunc: func(ctx context.Context, limit int, now time.Time, claimedUntil time.Time) ([]models.OutboxEvent, error) {
+			calls = append(calls, "claim")
+			assert.Equal(t, now.Add(claimTimeout), claimedUntil)
+			return []models.OutboxEvent{itemEvent(1, 1)}, nil
+		},
+		SaveFunc: func(ctx context.Context, events []models.OutboxEvent) error {
+			calls = append(calls, "save")
+			assert.NotNil(t, events[0].PublishedAt)
+			return nil
+		},
+	}
+	publisher := &recordingPublisher{calls: &calls}
+
+	NewRelay(repo, publisher, time.Second).relay(context.Background())
+
+	assert.Equal(t, []string{"claim", "publish", "save"}, calls)
+}
+
+type recordingPublisher struct {
+	calls *[]string
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event models.OutboxEvent) error {
+	*p.calls = append(*p.calls, "publish")
+	return nil
+}
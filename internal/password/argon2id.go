@@ -0,0 +1,128 @@
+package password
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// Argon2idParams are the cost of argon2id. Memory is in KiB.
+type Argon2idParams struct {
+	Memory      uint32
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2idParams are the minimum recommended by OWASP: 19 MiB, 2 iterations and 1 thread.
+var DefaultArgon2idParams = Argon2idParams{Memory: 19456, Iterations: 2, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+// ParseArgon2idParams overrides the cost of base by a string like "m=65536,t=3,p=2". Omitted ones keep base.
+func ParseArgon2idParams(value string, base Argon2idParams) (Argon2idParams, error) {
+	params := base
+	for _, pair := range strings.Split(value, ",") {
+		name, number, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			return base, fmt.Errorf("%q isn't like m=19456", pair)
+		}
+		n, err := strconv.ParseUint(number, 10, 32)
+		if err != nil || n == 0 {
+			return base, fmt.Errorf("%s must be a positive integer: %q", name, number)
+		}
+		switch name {
+		case "m":
+			params.Memory = uint32(n)
+		case "t":
+			params.Iterations = uint32(n)
+		case "p":
+			if n > 255 {
+				return base, fmt.Errorf("p must be at most 255: %d", n)
+			}
+			params.Parallelism = uint8(n)
+		default:
+			return base, fmt.Errorf("unknown parameter %q", name)
+		}
+	}
+	return params, nil
+}
+
+type Argon2id struct {
+	params Argon2idParams
+}
+
+func NewArgon2id(params Argon2idParams) *Argon2id {
+	return &Argon2id{params: params}
+}
+
+const argon2idPrefix = "$argon2id$"
+
+var b64 = base64.RawStdEncoding
+
+func (a *Argon2id) Hash(password string) (string, error) {
+	salt := make([]byte, a.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key := argon2.IDKey([]byte(password), salt, a.params.Iterations, a.params.Memory, a.params.Parallelism, a.params.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		a.params.Memory, a.params.Iterations, a.params.Parallelism, b64.EncodeToString(salt), b64.EncodeToString(key)), nil
+}
+
+// Verify hashes password with the parameters and the salt in encoded, so that hashes with old parameters still work.
+func (a *Argon2id) Verify(password string, encoded string) (bool, error) {
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+	other := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(key, other) == 1, nil
+}
+
+func (a *Argon2id) Recognizes(encoded string) bool {
+	return strings.HasPrefix(encoded, argon2idPrefix)
+}
+
+func (a *Argon2id) NeedsRehash(encoded string) bool {
+	params, _, _, err := decodeArgon2id(encoded)
+	return err != nil || params != a.params
+}
+
+// decodeArgon2id parses "$argon2id$v=19$m=19456,t=2,p=1$<salt>$<key>".
+func decodeArgon2id(encoded string) (Argon2idParams, []byte, []byte, error) {
+	var params Argon2idParams
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != "argon2id" {
+		return params, nil, nil, fmt.Errorf("%w: not argon2id", ErrUnknownHash)
+	}
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("argon2id version %q isn't supported", parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("argon2id parameters %q are invalid: %w", parts[3], err)
+	}
+	// argon2.IDKey panics on a parallelism of 0
+	if params.Memory == 0 || params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, fmt.Errorf("argon2id parameters %q must be positive", parts[3])
+	}
+	salt, err := b64.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("argon2id salt is invalid: %w", err)
+	}
+	key, err := b64.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, fmt.Errorf("argon2id hash is invalid: %w", err)
+	}
+	if len(salt) == 0 || len(key) == 0 {
+		return params, nil, nil, fmt.Errorf("argon2id salt and hash must not be empty")
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
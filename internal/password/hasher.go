@@ -0,0 +1,67 @@
+// Package password hashes passwords and checks them against the password policy.
+package password
+
+import "errors"
+
+var (
+	// ErrTooLong is returned by a hasher which can't hash the whole password, like bcrypt over 72 bytes.
+	ErrTooLong = errors.New("password is too long for the hasher")
+	// ErrUnknownHash is returned when no hasher recognizes the encoded hash.
+	ErrUnknownHash = errors.New("hash is in an unknown format")
+)
+
+// Hasher hashes passwords into PHC strings like "$argon2id$v=19$m=19456,t=2,p=1$<salt>$<hash>".
+// bcrypt keeps its own "$2a$" format, which is the predecessor of PHC.
+type Hasher interface {
+	// Hash encodes password with a new salt.
+	Hash(password string) (string, error)
+	// Verify reports whether password matches encoded. An error means encoded is broken, not a mismatch.
+	Verify(password string, encoded string) (bool, error)
+	// Recognizes reports whether encoded is in the format of this hasher.
+	Recognizes(encoded string) bool
+	// NeedsRehash reports whether encoded isn't made with the current algorithm and parameters.
+	NeedsRehash(encoded string) bool
+}
+
+// Chain hashes with the current hasher and verifies the hashes of the others too,
+// so that the hashes of a previous algorithm work until they're rehashed on login.
+type Chain struct {
+	current Hasher
+	legacy  []Hasher
+}
+
+func NewChain(current Hasher, legacy ...Hasher) *Chain {
+	return &Chain{current: current, legacy: legacy}
+}
+
+func (c *Chain) Hash(password string) (string, error) {
+	return c.current.Hash(password)
+}
+
+func (c *Chain) Verify(password string, encoded string) (bool, error) {
+	hasher := c.find(encoded)
+	if hasher == nil {
+		return false, ErrUnknownHash
+	}
+	return hasher.Verify(password, encoded)
+}
+
+func (c *Chain) Recognizes(encoded string) bool {
+	return c.find(encoded) != nil
+}
+
+func (c *Chain) NeedsRehash(encoded string) bool {
+	return !c.current.Recognizes(encoded) || c.current.NeedsRehash(encoded)
+}
+
+func (c *Chain) find(encoded string) Hasher {
+	if c.current.Recognizes(encoded) {
+		return c.current
+	}
+	for _, hasher := range c.legacy {
+		if hasher.Recognizes(encoded) {
+			return hasher
+		}
+	}
+	return nil
+}
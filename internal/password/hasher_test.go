@@ -0,0 +1,106 @@
+package password
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// cheap enough for the tests
+var testArgon2idParams = Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+
+func TestArgon2id(t *testing.T) {
+	hasher := NewArgon2id(testArgon2idParams)
+	encoded, err := hasher.Hash("nikutaberu")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$"))
+
+	ok, err := hasher.Verify("nikutaberu", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = hasher.Verify("nikutaberu!", encoded)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	// salted
+	again, _ := hasher.Hash("nikutaberu")
+	assert.NotEqual(t, encoded, again)
+
+	assert.False(t, hasher.NeedsRehash(encoded))
+	stronger := NewArgon2id(Argon2idParams{Memory: 128, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32})
+	assert.True(t, stronger.NeedsRehash(encoded))
+	// the parameters in the hash are used to verify
+	ok, err = stronger.Verify("nikutaberu", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	_, err = hasher.Verify("nikutaberu", "$argon2id$v=19$m=64,t=1,p=1$!!$!!")
+	assert.Error(t, err)
+
+	// a broken hash in the DB must be an error, not a panic of argon2
+	salt, key, _ := strings.Cut(strings.TrimPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$"), "$")
+	for _, params := range []string{"m=0,t=1,p=1", "m=64,t=0,p=1", "m=64,t=1,p=0"} {
+		_, err = hasher.Verify("nikutaberu", "$argon2id$v=19$"+params+"$"+salt+"$"+key)
+		assert.Error(t, err, params)
+		assert.True(t, hasher.NeedsRehash("$argon2id$v=19$"+params+"$"+salt+"$"+key))
+	}
+	_, err = hasher.Verify("nikutaberu", "$argon2id$v=19$m=64,t=1,p=1$"+salt+"$")
+	assert.Error(t, err)
+}
+
+func TestParseArgon2idParams(t *testing.T) {
+	params, err := ParseArgon2idParams("m=65536, t=3", DefaultArgon2idParams)
+	assert.NoError(t, err)
+	assert.Equal(t, uint32(65536), params.Memory)
+	assert.Equal(t, uint32(3), params.Iterations)
+	assert.Equal(t, uint8(1), params.Parallelism)
+
+	for _, value := range []string{"m=0", "p=256", "x=1", "m"} {
+		_, err := ParseArgon2idParams(value, DefaultArgon2idParams)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestBcrypt(t *testing.T) {
+	hasher := NewBcrypt(bcrypt.MinCost)
+	encoded, err := hasher.Hash("nikutaberu")
+	assert.NoError(t, err)
+	assert.True(t, hasher.Recognizes(encoded))
+
+	ok, err := hasher.Verify("nikutaberu", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	ok, err = hasher.Verify("nikutaberu!", encoded)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.False(t, hasher.NeedsRehash(encoded))
+	assert.True(t, NewBcrypt(bcrypt.MinCost+1).NeedsRehash(encoded))
+
+	// not truncated silently
+	_, err = hasher.Hash(strings.Repeat("a", 73))
+	assert.ErrorIs(t, err, ErrTooLong)
+}
+
+func TestChain(t *testing.T) {
+	argon2id := NewArgon2id(testArgon2idParams)
+	legacy := NewBcrypt(bcrypt.MinCost)
+	chain := NewChain(argon2id, legacy)
+
+	old, _ := legacy.Hash("nikutaberu")
+	ok, err := chain.Verify("nikutaberu", old)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, chain.NeedsRehash(old))
+
+	encoded, err := chain.Hash("nikutaberu")
+	assert.NoError(t, err)
+	assert.True(t, argon2id.Recognizes(encoded))
+	assert.False(t, chain.NeedsRehash(encoded))
+
+	_, err = chain.Verify("nikutaberu", "plain")
+	assert.ErrorIs(t, err, ErrUnknownHash)
+	assert.False(t, chain.Recognizes("plain"))
+}
@@ -0,0 +1,89 @@
+package password
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+)
+
+//go:embed breached.txt
+var defaultBreached string
+
+var (
+	ErrLength      = errors.New("password length is out of the policy")
+	ErrBreached    = errors.New("password is in the breached passwords")
+	ErrSameAsEmail = errors.New("password is the same as the email")
+)
+
+const (
+	DefaultMinLength = 8
+	DefaultMaxLength = 64
+)
+
+// Policy is the rules of a new password. Login doesn't check it, so that a stricter policy doesn't lock users out.
+type Policy struct {
+	// in characters, not bytes
+	MinLength int
+	MaxLength int
+	// lower case
+	breached map[string]struct{}
+}
+
+func NewPolicy(minLength int, maxLength int, breached []string) *Policy {
+	set := make(map[string]struct{}, len(breached))
+	for _, password := range breached {
+		set[strings.ToLower(password)] = struct{}{}
+	}
+	return &Policy{MinLength: minLength, MaxLength: maxLength, breached: set}
+}
+
+// Validate returns an error wrapping ErrLength, ErrBreached or ErrSameAsEmail. Breached passwords and the email are matched case-insensitively.
+func (p *Policy) Validate(password string, email string) error {
+	if length := utf8.RuneCountInString(password); length < p.MinLength || length > p.MaxLength {
+		return fmt.Errorf("%w: %d characters, which must be %d to %d", ErrLength, length, p.MinLength, p.MaxLength)
+	}
+	if strings.EqualFold(password, email) {
+		return ErrSameAsEmail
+	}
+	if _, ok := p.breached[strings.ToLower(password)]; ok {
+		return ErrBreached
+	}
+	return nil
+}
+
+// DefaultBreached is the list embedded from breached.txt.
+func DefaultBreached() []string {
+	breached, err := parseBreached(strings.NewReader(defaultBreached))
+	if err != nil {
+		panic("breached.txt is invalid: " + err.Error())
+	}
+	return breached
+}
+
+// ReadBreached reads a file of a password per line. Empty lines and lines starting with # are skipped.
+func ReadBreached(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return parseBreached(file)
+}
+
+func parseBreached(r io.Reader) ([]string, error) {
+	var breached []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		breached = append(breached, line)
+	}
+	return breached, scanner.Err()
+}
@@ -0,0 +1,51 @@
+package password
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy(t *testing.T) {
+	policy := NewPolicy(DefaultMinLength, DefaultMaxLength, DefaultBreached())
+
+	cases := []struct {
+		password string
+		want     error
+	}{
+		{"nikutaberu", nil},
+		{"1234567", ErrLength},
+		{strings.Repeat("a", 65), ErrLength},
+		// characters, not bytes
+		{strings.Repeat("あ", 64), nil},
+		{"Password123", ErrBreached},
+		{"Test1@Test.com", ErrSameAsEmail},
+	}
+	for _, tc := range cases {
+		err := policy.Validate(tc.password, "test1@test.com")
+		if tc.want == nil {
+			assert.NoError(t, err, tc.password)
+		} else {
+			assert.ErrorIs(t, err, tc.want, tc.password)
+		}
+	}
+}
+
+func TestReadBreached(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "breached.txt")
+	assert.NoError(t, os.WriteFile(path, []byte("# comment\n\nnikutaberu\n  sushitaberu  \n"), 0o600))
+
+	breached, err := ReadBreached(path)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"nikutaberu", "sushitaberu"}, breached)
+
+	policy := NewPolicy(DefaultMinLength, DefaultMaxLength, breached)
+	assert.ErrorIs(t, policy.Validate("NikuTaberu", "test1@test.com"), ErrBreached)
+	assert.NoError(t, policy.Validate("password123", "test1@test.com"))
+
+	_, err = ReadBreached(filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+package seed
+
+import (
+	"context"
+	"flea-market/models"
+	"fmt"
+	"math/rand/v2"
+
+	"gorm.io/gorm"
+)
+
+const (
+	fakeItemsPerUser = 20
+	fakeUserPassword = "password"
+	fakeBatchSize    = 500
+)
+
+type fakeProduct struct {
+	name  string
+	price uint
+	tags  []string
+}
+
+var (
+	fakeProducts = []fakeProduct{
+		{name: "スマートフォン", price: 40000, tags: []string{"phone", "android"}},
+		{name: "iPhone", price: 60000, tags: []string{"phone", "apple"}},
+		{name: "ノートPC", price: 80000, tags: []string{"pc"}},
+		{name: "タブレット", price: 30000, tags: []string{"tablet"}},
+		{name: "ワイヤレスイヤホン", price: 12000, tags: []string{"audio"}},
+		{name: "一眼レフカメラ", price: 50000, tags: []string{"camera"}},
+		{name: "フィルムカメラ", price: 15000, tags: []string{"camera", "vintage"}},
+		{name: "腕時計", price: 20000, tags: []string{"watch"}},
+		{name: "スニーカー", price: 9000, tags: []string{"shoes"}},
+		{name: "レザージャケット", price: 18000, tags: []string{"leather", "vintage"}},
+		{name: "トートバッグ", price: 5000, tags: []string{"bag"}},
+		{name: "ワンピース", price: 4000, tags: []string{"dress"}},
+		{name: "小説", price: 500, tags: []string{"book"}},
+		{name: "漫画 全巻セット", price: 6000, tags: []string{"book", "comic"}},
+		{name: "ゲームソフト", price: 3500, tags: []string{"game"}},
+		{name: "ゲーム機", price: 25000, tags: []string{"game"}},
+		{name: "レコード", price: 2500, tags: []string{"music", "vintage"}},
+		{name: "ダイニングチェア", price: 7000, tags: []string{"furniture"}},
+		{name: "コーヒーメーカー", price: 6000, tags: []string{"kitchen"}},
+		{name: "フライパン", price: 2000, tags: []string{"kitchen"}},
+	}
+	fakeBrands      = []string{"Canon", "Sony", "Nike", "Apple", "Panasonic", "無印良品", "ユニクロ", "Nintendo", "SEIKO", "ノーブランド"}
+	fakeAdjectives  = []string{"美品", "新品未使用", "ほぼ新品", "訳あり", "限定モデル", "人気", "レア", "used"}
+	fakeConditions  = []models.ItemCondition{models.ConditionNew, models.ConditionLikeNew, models.ConditionGood, models.ConditionFair, models.ConditionPoor}
+	fakeDescription = []string{
+		"数回使用しただけなので状態は良いです。",
+		"自宅保管品です。細かな傷がありますが動作に問題はありません。",
+		"箱と付属品が揃っています。",
+		"引っ越しのため出品します。",
+		"ペットや喫煙者はいない環境で保管していました。",
+		"写真にあるものが全てです。ノークレームでお願いします。",
+	}
+)
+
+// Generate creates count listings of random products. They're sold by users seller0001@example.com and so on,
+// whose password is "password", and put in random leaf categories if the categories are seeded.
+// The same rand gives the same listings.
+func (s *Seeder) Generate(ctx context.Context, count int, rng *rand.Rand) error {
+	hashed, err := hash(fakeUserPassword)
+	if err != nil {
+		return err
+	}
+
+	return s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		userIds := make([]uint, 0, count/fakeItemsPerUser+1)
+		for i := range count/fakeItemsPerUser + 1 {
+			user := models.User{Email: fmt.Sprintf("seller%04d@example.com", i+1)}
+			if err := tx.Where(models.User{Email: user.Email}).
+				Assign(models.User{Password: hashed}).
+				FirstOrCreate(&user).Error; err != nil {
+				return fmt.Errorf("user %s: %w", user.Email, err)
+			}
+			userIds = append(userIds, user.ID)
+		}
+
+		var categoryIds []uint
+		if err := tx.Model(&models.Category{}).
+			Where("id NOT IN (?)", tx.Model(&models.Category{}).Select("parent_id").Where("parent_id IS NOT NULL")).
+			Pluck("id", &categoryIds).Error; err != nil {
+			return err
+		}
+
+		tags := map[string]models.Tag{}
+		for _, product := range fakeProducts {
+			found, err := findOrCreateTags(tx, product.tags)
+			if err != nil {
+				return err
+			}
+			for _, tag := range found {
+				tags[tag.Name] = tag
+			}
+		}
+
+		items := make([]models.Item, 0, count)
+		for range count {
+			items = append(items, fakeItem(rng, userIds, categoryIds, tags))
+		}
+		if err := tx.CreateInBatches(&items, fakeBatchSize).Error; err != nil {
+			return fmt.Errorf("items: %w", err)
+		}
+		return nil
+	})
+}
+
+func fakeItem(rng *rand.Rand, userIds []uint, categoryIds []uint, tags map[string]models.Tag) models.Item {
+	product := pick(rng, fakeProducts)
+	condition := pick(rng, fakeConditions)
+	item := models.Item{
+		Name:        fmt.Sprintf("【%s】%s %s", pick(rng, fakeAdjectives), pick(rng, fakeBrands), product.name),
+		Price:       fakePrice(rng, product.price, condition),
+		Description: pick(rng, fakeDescription),
+		SoldOut:     rng.IntN(100) < 15,
+		UserID:      pick(rng, userIds),
+		Condition:   condition,
+	}
+	if len(categoryIds) > 0 {
+		categoryId := pick(rng, categoryIds)
+		item.CategoryID = &categoryId
+	}
+	for _, name := range product.tags {
+		if rng.IntN(2) == 0 {
+			item.Tags = append(item.Tags, tags[name])
+		}
+	}
+	return item
+}
+
+// fakePrice discounts the base price by the condition and rounds it to 10 yen like real listings.
+func fakePrice(rng *rand.Rand, base uint, condition models.ItemCondition) uint {
+	discount := map[models.ItemCondition]float64{
+		models.ConditionNew:     1.0,
+		models.ConditionLikeNew: 0.85,
+		models.ConditionGood:    0.7,
+		models.ConditionFair:    0.5,
+		models.ConditionPoor:    0.3,
+	}[condition]
+	price := float64(base) * discount * (0.7 + rng.Float64()*0.6)
+	return max(uint(price)/10*10, 300)
+}
+
+func pick[T any](rng *rand.Rand, values []T) T {
+	return values[rng.IntN(len(values))]
+}
@@ -0,0 +1,113 @@
+package seed
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Fixtures are the rows to load. Rows refer to each other by Ref, the symbolic name of a row,
+// so that fixtures don't depend on the ids given by the DB.
+type Fixtures struct {
+	Users      []UserFixture     `json:"users"`
+	Categories []CategoryFixture `json:"categories"`
+	Items      []ItemFixture     `json:"items"`
+	Offers     []OfferFixture    `json:"offers"`
+}
+
+// Password is plaintext, and it's hashed on load. Plan defaults to free.
+type UserFixture struct {
+	Ref      string `json:"ref"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+	Plan     string `json:"plan"`
+	Admin    bool   `json:"admin"`
+}
+
+// Parent is the ref of another category in the fixtures, or the slug of a category in the DB.
+type CategoryFixture struct {
+	Ref    string `json:"ref"`
+	Name   string `json:"name"`
+	Slug   string `json:"slug"`
+	Parent string `json:"parent"`
+}
+
+// User is the ref of the seller. Category is resolved in the same way as CategoryFixture.Parent.
+// Price is in the minor unit of Currency, which defaults to JPY.
+type ItemFixture struct {
+	Ref         string   `json:"ref"`
+	Name        string   `json:"name"`
+	Price       uint     `json:"price"`
+	Currency    string   `json:"currency"`
+	Description string   `json:"description"`
+	SoldOut     bool     `json:"soldOut"`
+	User        string   `json:"user"`
+	Category    string   `json:"category"`
+	Condition   string   `json:"condition"`
+	Tags        []string `json:"tags"`
+}
+
+// Status defaults to pending, and ExpiresIn, a duration like "48h", to the TTL of offers.
+type OfferFixture struct {
+	Item      string `json:"item"`
+	Buyer     string `json:"buyer"`
+	Price     uint   `json:"price"`
+	Status    string `json:"status"`
+	ExpiresIn string `json:"expiresIn"`
+}
+
+// ReadFiles reads YAML or JSON fixtures, told by the extension, and merges them in order.
+func ReadFiles(paths ...string) (*Fixtures, error) {
+	var fixtures Fixtures
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		f, err := Parse(data, filepath.Ext(path))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		fixtures.Append(*f)
+	}
+	return &fixtures, nil
+}
+
+// Parse parses fixtures in the format of ext, ".yaml", ".yml" or ".json".
+// YAML is converted to JSON first, so that the fields are named only by the json tags.
+func Parse(data []byte, ext string) (*Fixtures, error) {
+	switch ext {
+	case ".yaml", ".yml":
+		var doc any
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return nil, err
+		}
+		var err error
+		if data, err = json.Marshal(doc); err != nil {
+			return nil, err
+		}
+	case ".json":
+	default:
+		return nil, fmt.Errorf("unsupported fixture format %q", ext)
+	}
+
+	var fixtures Fixtures
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	// a misspelled field would be loaded as the zero value silently
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fixtures); err != nil {
+		return nil, err
+	}
+	return &fixtures, nil
+}
+
+func (f *Fixtures) Append(other Fixtures) {
+	f.Users = append(f.Users, other.Users...)
+	f.Categories = append(f.Categories, other.Categories...)
+	f.Items = append(f.Items, other.Items...)
+	f.Offers = append(f.Offers, other.Offers...)
+}
@@ -0,0 +1,250 @@
+package seed
+
+import (
+	"context"
+	"errors"
+	"flea-market/internal/money"
+	"flea-market/models"
+	"flea-market/services"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const defaultOfferExpiresIn = 48 * time.Hour
+
+// Refs are the ids of the loaded rows by their refs.
+type Refs struct {
+	Users      map[string]uint
+	Categories map[string]uint
+	Items      map[string]uint
+}
+
+type Seeder struct {
+	db *gorm.DB
+}
+
+func NewSeeder(db *gorm.DB) *Seeder {
+	return &Seeder{db: db}
+}
+
+// Password hashing is slow on purpose, so each password is hashed once per process.
+// It matters for the tests, which load the same users before every test.
+var (
+	hashesMu sync.Mutex
+	hashes   = map[string]string{}
+)
+
+// Load creates the rows of fixtures in a transaction.
+// Users are matched by email and categories by slug, so that loading the same fixtures again doesn't fail on them.
+// Rows are inserted directly instead of through the services, so no outbox event or notification is made.
+func (s *Seeder) Load(ctx context.Context, fixtures Fixtures) (*Refs, error) {
+	refs := &Refs{Users: map[string]uint{}, Categories: map[string]uint{}, Items: map[string]uint{}}
+	err := s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := loadUsers(tx, fixtures.Users, refs); err != nil {
+			return err
+		}
+		if err := loadCategories(tx, fixtures.Categories, refs); err != nil {
+			return err
+		}
+		if err := loadItems(tx, fixtures.Items, refs); err != nil {
+			return err
+		}
+		return loadOffers(tx, fixtures.Offers, refs)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return refs, nil
+}
+
+func hash(password string) (string, error) {
+	hashesMu.Lock()
+	defer hashesMu.Unlock()
+	if hashed, ok := hashes[password]; ok {
+		return hashed, nil
+	}
+	hashed, err := services.HashPassword(password)
+	if err != nil {
+		return "", err
+	}
+	hashes[password] = hashed
+	return hashed, nil
+}
+
+func loadUsers(tx *gorm.DB, fixtures []UserFixture, refs *Refs) error {
+	for _, f := range fixtures {
+		hashed, err := hash(f.Password)
+		if err != nil {
+			return fmt.Errorf("user %s: %w", f.Email, err)
+		}
+		user := models.User{Email: f.Email}
+		if err := tx.Where(models.User{Email: f.Email}).
+			Assign(models.User{Password: hashed, Plan: models.Plan(f.Plan), Admin: f.Admin}).
+			FirstOrCreate(&user).Error; err != nil {
+			return fmt.Errorf("user %s: %w", f.Email, err)
+		}
+		if err := setRef(refs.Users, "user", f.Ref, user.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The parent must come before its children.
+func loadCategories(tx *gorm.DB, fixtures []CategoryFixture, refs *Refs) error {
+	for _, f := range fixtures {
+		var parentId *uint
+		if f.Parent != "" {
+			id, err := resolveCategory(tx, refs, f.Parent)
+			if err != nil {
+				return fmt.Errorf("category %s: %w", f.Slug, err)
+			}
+			parentId = &id
+		}
+		category := models.Category{Slug: f.Slug}
+		if err := tx.Where(models.Category{Slug: f.Slug}).
+			Assign(models.Category{Name: f.Name, ParentID: parentId}).
+			FirstOrCreate(&category).Error; err != nil {
+			return fmt.Errorf("category %s: %w", f.Slug, err)
+		}
+		if err := setRef(refs.Categories, "category", f.Ref, category.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadItems(tx *gorm.DB, fixtures []ItemFixture, refs *Refs) error {
+	for _, f := range fixtures {
+		userId, ok := refs.Users[f.User]
+		if !ok {
+			return fmt.Errorf("item %s: unknown user %q", f.Name, f.User)
+		}
+		item := models.Item{
+			Name:        f.Name,
+			Price:       f.Price,
+			Currency:    money.Currency(f.Currency),
+			Description: f.Description,
+			SoldOut:     f.SoldOut,
+			UserID:      userId,
+			Condition:   models.ItemCondition(f.Condition),
+		}
+		if f.Category != "" {
+			categoryId, err := resolveCategory(tx, refs, f.Category)
+			if err != nil {
+				return fmt.Errorf("item %s: %w", f.Name, err)
+			}
+			item.CategoryID = &categoryId
+		}
+		tags, err := findOrCreateTags(tx, f.Tags)
+		if err != nil {
+			return fmt.Errorf("item %s: %w", f.Name, err)
+		}
+		item.Tags = tags
+
+		if err := tx.Create(&item).Error; err != nil {
+			return fmt.Errorf("item %s: %w", f.Name, err)
+		}
+		if err := setRef(refs.Items, "item", f.Ref, item.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadOffers(tx *gorm.DB, fixtures []OfferFixture, refs *Refs) error {
+	for _, f := range fixtures {
+		itemId, ok := refs.Items[f.Item]
+		if !ok {
+			return fmt.Errorf("offer: unknown item %q", f.Item)
+		}
+		buyerId, ok := refs.Users[f.Buyer]
+		if !ok {
+			return fmt.Errorf("offer on %s: unknown user %q", f.Item, f.Buyer)
+		}
+		var item models.Item
+		if err := tx.First(&item, itemId).Error; err != nil {
+			return fmt.Errorf("offer on %s: %w", f.Item, err)
+		}
+
+		expiresIn := defaultOfferExpiresIn
+		if f.ExpiresIn != "" {
+			var err error
+			if expiresIn, err = time.ParseDuration(f.ExpiresIn); err != nil {
+				return fmt.Errorf("offer on %s: %w", f.Item, err)
+			}
+		}
+		status := models.OfferPending
+		if f.Status != "" {
+			status = models.OfferStatus(f.Status)
+		}
+
+		offer := models.Offer{
+			ItemID:     itemId,
+			BuyerID:    buyerId,
+			SellerID:   item.UserID,
+			ProposerID: buyerId,
+			Price:      f.Price,
+			Status:     status,
+			ExpiresAt:  time.Now().Add(expiresIn),
+		}
+		if err := tx.Create(&offer).Error; err != nil {
+			return fmt.Errorf("offer on %s: %w", f.Item, err)
+		}
+	}
+	return nil
+}
+
+// resolveCategory looks up the refs first, and then the categories already in the DB by slug.
+func resolveCategory(tx *gorm.DB, refs *Refs, name string) (uint, error) {
+	if id, ok := refs.Categories[name]; ok {
+		return id, nil
+	}
+	var category models.Category
+	if err := tx.Where(models.Category{Slug: name}).First(&category).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, fmt.Errorf("unknown category %q", name)
+		}
+		return 0, err
+	}
+	refs.Categories[name] = category.ID
+	return category.ID, nil
+}
+
+// findOrCreateTags returns the tags with their ids, so that creating items only links them.
+func findOrCreateTags(tx *gorm.DB, names []string) ([]models.Tag, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	normalized := make([]string, 0, len(names))
+	newTags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		normalized = append(normalized, name)
+		newTags = append(newTags, models.Tag{Name: name})
+	}
+	if err := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(&newTags).Error; err != nil {
+		return nil, err
+	}
+	var tags []models.Tag
+	if err := tx.Where("name IN ?", normalized).Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func setRef(refs map[string]uint, kind string, ref string, id uint) error {
+	if ref == "" {
+		return nil
+	}
+	if _, ok := refs[ref]; ok {
+		return fmt.Errorf("duplicated %s ref %q", kind, ref)
+	}
+	refs[ref] = id
+	return nil
+}
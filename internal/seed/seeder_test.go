@@ -0,0 +1,178 @@
+package seed
+
+import (
+	"context"
+	"flea-market/infra"
+	"flea-market/internal/password"
+	"flea-market/models"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+const testFixtures = `
+users:
+  - ref: alice
+    email: alice@example.com
+    password: secret123
+  - ref: bob
+    email: bob@example.com
+    password: secret123
+categories:
+  - ref: electronics
+    name: Electronics
+    slug: electronics
+  - ref: cameras
+    name: Cameras
+    slug: cameras
+    parent: electronics
+items:
+  - ref: camera
+    name: Vintage camera
+    price: 5000
+    user: alice
+    category: cameras
+    condition: good
+    tags: [Camera, vintage]
+  - name: Film
+    price: 800
+    user: bob
+    category: electronics
+    tags: [camera]
+offers:
+  - item: camera
+    buyer: bob
+    price: 4000
+`
+
+func setupSeederTest(t *testing.T) *gorm.DB {
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_NAME", ":memory:")
+	db := infra.SetupDB()
+	assert.NoError(t, infra.Migrate(db))
+	t.Cleanup(func() {
+		pool, _ := db.DB()
+		pool.Close()
+	})
+	return db
+}
+
+func TestParse(t *testing.T) {
+	fromYAML, err := Parse([]byte(testFixtures), ".yaml")
+	assert.NoError(t, err)
+	assert.Len(t, fromYAML.Users, 2)
+	assert.Equal(t, ItemFixture{Ref: "camera", Name: "Vintage camera", Price: 5000, User: "alice", Category: "cameras", Condition: "good", Tags: []string{"Camera", "vintage"}}, fromYAML.Items[0])
+
+	fromJSON, err := Parse([]byte(`{"users": [{"ref": "alice", "email": "alice@example.com", "password": "secret123"}]}`), ".json")
+	assert.NoError(t, err)
+	assert.Equal(t, fromYAML.Users[0], fromJSON.Users[0])
+
+	_, err = Parse([]byte("users:\n  - emial: alice@example.com\n"), ".yml")
+	assert.ErrorContains(t, err, "emial")
+
+	_, err = Parse([]byte("users: []"), ".toml")
+	assert.Error(t, err)
+}
+
+func TestSeeder_Load(t *testing.T) {
+	db := setupSeederTest(t)
+	fixtures, err := Parse([]byte(testFixtures), ".yaml")
+	assert.NoError(t, err)
+
+	refs, err := NewSeeder(db).Load(context.Background(), *fixtures)
+	assert.NoError(t, err)
+
+	var alice models.User
+	assert.NoError(t, db.First(&alice, refs.Users["alice"]).Error)
+	ok, err := password.NewArgon2id(password.DefaultArgon2idParams).Verify("secret123", alice.Password)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	var cameras models.Category
+	assert.NoError(t, db.First(&cameras, refs.Categories["cameras"]).Error)
+	assert.Equal(t, refs.Categories["electronics"], *cameras.ParentID)
+
+	var camera models.Item
+	assert.NoError(t, db.Preload("Tags").First(&camera, refs.Items["camera"]).Error)
+	assert.Equal(t, alice.ID, camera.UserID)
+	assert.Equal(t, cameras.ID, *camera.CategoryID)
+	assert.ElementsMatch(t, []string{"camera", "vintage"}, []string{camera.Tags[0].Name, camera.Tags[1].Name})
+
+	var offer models.Offer
+	assert.NoError(t, db.First(&offer, "item_id = ?", camera.ID).Error)
+	assert.Equal(t, refs.Users["bob"], offer.BuyerID)
+	assert.Equal(t, alice.ID, offer.SellerID)
+	assert.Equal(t, models.OfferPending, offer.Status)
+
+	// users and categories are matched on the second load
+	_, err = NewSeeder(db).Load(context.Background(), *fixtures)
+	assert.NoError(t, err)
+	var count int64
+	db.Model(&models.User{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+	db.Model(&models.Tag{}).Count(&count)
+	assert.Equal(t, int64(2), count)
+}
+
+func TestSeeder_Load_ExistingCategory(t *testing.T) {
+	db := setupSeederTest(t)
+	books := models.Category{Name: "Books", Slug: "books"}
+	assert.NoError(t, db.Create(&books).Error)
+
+	refs, err := NewSeeder(db).Load(context.Background(), Fixtures{
+		Users: []UserFixture{{Ref: "alice", Email: "alice@example.com", Password: "secret123"}},
+		Items: []ItemFixture{{Ref: "novel", Name: "Novel", Price: 300, User: "alice", Category: "books"}},
+	})
+
+	assert.NoError(t, err)
+	var novel models.Item
+	assert.NoError(t, db.First(&novel, refs.Items["novel"]).Error)
+	assert.Equal(t, books.ID, *novel.CategoryID)
+}
+
+func TestSeeder_Load_UnknownRef(t *testing.T) {
+	db := setupSeederTest(t)
+
+	_, err := NewSeeder(db).Load(context.Background(), Fixtures{
+		Users: []UserFixture{{Ref: "alice", Email: "alice@example.com", Password: "secret123"}},
+		Items: []ItemFixture{{Name: "Novel", Price: 300, User: "carol"}},
+	})
+
+	assert.EqualError(t, err, `item Novel: unknown user "carol"`)
+	// rolled back
+	var count int64
+	db.Model(&models.User{}).Count(&count)
+	assert.Zero(t, count)
+}
+
+func TestSeeder_Generate(t *testing.T) {
+	db := setupSeederTest(t)
+	parent := models.Category{Name: "Electronics", Slug: "electronics"}
+	assert.NoError(t, db.Create(&parent).Error)
+	leaf := models.Category{Name: "Cameras", Slug: "cameras", ParentID: &parent.ID}
+	assert.NoError(t, db.Create(&leaf).Error)
+
+	assert.NoError(t, NewSeeder(db).Generate(context.Background(), 1000, rand.New(rand.NewPCG(1, 0))))
+
+	var items []models.Item
+	assert.NoError(t, db.Find(&items).Error)
+	assert.Len(t, items, 1000)
+	for _, item := range items {
+		assert.NotEmpty(t, item.Name)
+		assert.GreaterOrEqual(t, item.Price, uint(300))
+		// only in leaf categories
+		assert.Equal(t, leaf.ID, *item.CategoryID)
+	}
+	var users int64
+	db.Model(&models.User{}).Count(&users)
+	assert.Equal(t, int64(51), users)
+
+	// the same rand gives the same listings
+	var first models.Item
+	assert.NoError(t, db.First(&first).Error)
+	again := fakeItem(rand.New(rand.NewPCG(1, 0)), []uint{first.UserID}, []uint{leaf.ID}, map[string]models.Tag{})
+	assert.Equal(t, first.Name, again.Name)
+	assert.Equal(t, first.Price, again.Price)
+}
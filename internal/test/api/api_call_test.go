@@ -74,18 +74,16 @@ func TestGetAllPosts(t *testing.T) {
 
 	router := setupAPICallTest()
 
-	req := httptest.NewRequest("GET", "/external", nil)
+	req := httptest.NewRequest("GET", "/v2/external", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var res map[string][]repositories.Post
-	err := json.Unmarshal(w.Body.Bytes(), &res)
-	assert.NoError(t, err)
-	assert.Equal(t, len(dummyPosts), len(res["data"]))
-	assert.Equal(t, dummyPosts[0].Title, res["data"][0].Title)
-	assert.Equal(t, dummyPosts[0].UserId, res["data"][0].UserId)
+	posts := decodeData[[]repositories.Post](t, w)
+	assert.Equal(t, len(dummyPosts), len(posts))
+	assert.Equal(t, dummyPosts[0].Title, posts[0].Title)
+	assert.Equal(t, dummyPosts[0].UserId, posts[0].UserId)
 }
 
 func TestGetAllPosts_Timeout(t *testing.T) {
@@ -98,7 +96,7 @@ func TestGetAllPosts_Timeout(t *testing.T) {
 
 	router := setupAPICallTest()
 
-	req := httptest.NewRequest("GET", "/external", nil)
+	req := httptest.NewRequest("GET", "/v2/external", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -120,7 +118,7 @@ func TestGetAllPosts_Request_URL_Wrong(t *testing.T) {
 
 	router := setupAPICallTest()
 
-	req := httptest.NewRequest("GET", "/external", nil)
+	req := httptest.NewRequest("GET", "/v2/external", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -142,7 +140,7 @@ func TestGetAllPosts_Get_HTTPStatus_400(t *testing.T) {
 
 	router := setupAPICallTest()
 
-	req := httptest.NewRequest("GET", "/external", nil)
+	req := httptest.NewRequest("GET", "/v2/external", nil)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"flea-market/dto"
 	"flea-market/internal/app"
+	"flea-market/internal/password"
+	"flea-market/internal/test/fixtures"
 	test_utils "flea-market/internal/test/utils"
 	"flea-market/models"
 	"flea-market/utils"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -23,16 +26,7 @@ import (
 
 func setupAuthTestData(db *gorm.DB) {
 	test_utils.DeleteTables(db)
-
-	//　if you write like "db.Create(&test_utils.UserData)",
-	// it may cause a problem. &test_utils.UserData means pass pointer to gorm,
-	// and gorm can rewrite "&test_utils.UserData".
-	// So ID and other fields can be set and cause a problem following.
-	// duplicate key value violates unique constraint "users_pkey"
-	for _, user := range test_utils.UserData {
-		db.Create(&user)
-	}
-
+	fixtures.SeedUsers(db)
 }
 
 func setupAuthTest() *gin.Engine {
@@ -65,20 +59,21 @@ func TestSignupAndLogin(t *testing.T) {
 	var userResult models.User
 	db.First(&userResult, "email = ?", signUpInput.Email)
 	assert.Equal(t, userResult.Email, signUpInput.Email)
-	err := bcrypt.CompareHashAndPassword([]byte(userResult.Password), []byte(signUpInput.Password))
-	assert.Equal(t, err, nil)
+	assert.True(t, strings.HasPrefix(userResult.Password, "$argon2id$"))
+	ok, err := password.NewArgon2id(password.DefaultArgon2idParams).Verify(signUpInput.Password, userResult.Password)
+	assert.NoError(t, err)
+	assert.True(t, ok)
 
 	// 2. Request to /auth/login to check if the created user can login soon after signing u
 	loginW := httptest.NewRecorder()
-	loginReq, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(reqBody))
+	loginReq, _ := http.NewRequest("POST", "/v2/auth/login", bytes.NewBuffer(reqBody))
 	router.ServeHTTP(loginW, loginReq)
 
 	// Check /auth/signup response(token) and token can have the email used to login
 	assert.Equal(t, http.StatusOK, loginW.Code)
 
-	var res map[string]string
-	json.Unmarshal(loginW.Body.Bytes(), &res)
-	token, _, _ := jwt.NewParser().ParseUnverified(res["token"], jwt.MapClaims{})
+	res := decodeData[dto.TokenResponse](t, loginW)
+	token, _, _ := jwt.NewParser().ParseUnverified(res.Token, jwt.MapClaims{})
 
 	claims, _ := token.Claims.(jwt.MapClaims)
 
@@ -113,7 +108,7 @@ func TestSignupAndLoginWithWrongInput(t *testing.T) {
 			assert.Equal(t, tc.wantStatus, signupW.Code)
 
 			loginW := httptest.NewRecorder()
-			loginReq, _ := http.NewRequest("POST", "/auth/login", strings.NewReader(tc.body))
+			loginReq, _ := http.NewRequest("POST", "/v2/auth/login", strings.NewReader(tc.body))
 			router.ServeHTTP(loginW, loginReq)
 			assert.Equal(t, tc.wantStatus, loginW.Code)
 
@@ -128,8 +123,8 @@ func TestSignupWithDuplicatedEmail(t *testing.T) {
 	time.Sleep(1000 * time.Millisecond)
 
 	input := dto.SignupInput{
-		Email:    test_utils.UserData[0].Email,
-		Password: "12345678",
+		Email:    fixtures.UserData[0].Email,
+		Password: "nikutaberu",
 	}
 
 	w := httptest.NewRecorder()
@@ -145,10 +140,22 @@ func TestSignupWithDuplicatedEmail(t *testing.T) {
 	assert.Equal(t, res["error"], string(utils.DuplicateKeyError))
 }
 
+// The passwords of the fixtures are hashed on load, so the users can log in with them.
+func TestLoginWithFixtureUser(t *testing.T) {
+	router := setupAuthTest()
+
+	input := dto.LoginInput{Email: fixtures.UserData[0].Email, Password: fixtures.UserData[0].Password}
+	reqBody, _ := json.Marshal(input)
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v2/auth/login", bytes.NewBuffer(reqBody))
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEmpty(t, decodeData[dto.TokenResponse](t, w).Token)
+}
+
 func TestLoginWithWrongPassword(t *testing.T) {
 	// 1. Request to /auth/signup to register User
-	// At setup, users are already registered but password is not hashed.
-	// So use /auth/signup to register a user in a proper way.
 	router := setupAuthTest()
 
 	signupW := httptest.NewRecorder()
@@ -175,7 +182,7 @@ func TestLoginWithWrongPassword(t *testing.T) {
 
 	loginW := httptest.NewRecorder()
 	loginReqBody, _ := json.Marshal(loginInput)
-	loginReq, _ := http.NewRequest("POST", "/auth/login", bytes.NewBuffer(loginReqBody))
+	loginReq, _ := http.NewRequest("POST", "/v2/auth/login", bytes.NewBuffer(loginReqBody))
 	router.ServeHTTP(loginW, loginReq)
 
 	assert.Equal(t, http.StatusUnauthorized, loginW.Code)
@@ -185,3 +192,88 @@ func TestLoginWithWrongPassword(t *testing.T) {
 
 	assert.Equal(t, res["error"], string(utils.UnAuthorized))
 }
+
+// An unknown email fails like a wrong password, so that login doesn't tell which emails are registered.
+func TestLoginWithUnknownEmailLooksLikeWrongPassword(t *testing.T) {
+	router := setupAuthTest()
+
+	for _, path := range []string{"/v1/auth/login", "/v2/auth/login"} {
+		t.Run(path, func(t *testing.T) {
+			wrongPassword := doJSON(router, "POST", path, "", fmt.Sprintf(`{"email":%q,"password":"wrongpassword"}`, fixtures.UserData[0].Email))
+			unknownEmail := doJSON(router, "POST", path, "", `{"email":"nobody@example.com","password":"wrongpassword"}`)
+
+			assert.Equal(t, http.StatusUnauthorized, wrongPassword.Code)
+			assert.Equal(t, wrongPassword.Code, unknownEmail.Code)
+			assert.Equal(t, wrongPassword.Body.String(), unknownEmail.Body.String())
+		})
+	}
+}
+
+func TestSignupWithPasswordAgainstPolicy(t *testing.T) {
+	router := setupAuthTest()
+
+	cases := []struct {
+		name     string
+		body     string
+		wantCode utils.MessageCode
+	}{
+		{
+			name:     "Password is breached",
+			body:     `{"email":"policy@test.com","password":"Password123"}`,
+			wantCode: utils.PasswordBreached,
+		},
+		{
+			name:     "Password is the email",
+			body:     `{"email":"policy@test.com","password":"POLICY@test.com"}`,
+			wantCode: utils.PasswordSameAsEmail,
+		},
+		{
+			name:     "Password is longer than 64",
+			body:     `{"email":"policy@test.com","password":"` + strings.Repeat("a", 65) + `"}`,
+			wantCode: utils.PasswordLengthInvalid,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("POST", "/auth/signup", strings.NewReader(tc.body))
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var res map[string]any
+			json.Unmarshal(w.Body.Bytes(), &res)
+			assert.Equal(t, string(tc.wantCode), res["error"])
+
+			var count int64
+			testDB.Model(&models.User{}).Where("email = ?", "policy@test.com").Count(&count)
+			assert.Zero(t, count)
+		})
+	}
+}
+
+// A hash of the previous hasher works, and is upgraded to the current one on login.
+func TestLoginRehashesLegacyPassword(t *testing.T) {
+	router := setupAuthTest()
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte("nikutaberu"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	user := models.User{Email: "legacy@test.com", Password: string(hashed)}
+	assert.NoError(t, testDB.Create(&user).Error)
+
+	reqBody, _ := json.Marshal(dto.LoginInput{Email: user.Email, Password: "nikutaberu"})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v2/auth/login", bytes.NewBuffer(reqBody))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var rehashed models.User
+	assert.NoError(t, testDB.First(&rehashed, user.ID).Error)
+	assert.True(t, strings.HasPrefix(rehashed.Password, "$argon2id$"))
+
+	// the upgraded hash works too
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/v2/auth/login", bytes.NewBuffer(reqBody))
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,98 @@
+package api_test
+
+import (
+	"flea-market/dto"
+	"flea-market/internal/test/fixtures"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func countItems(t *testing.T) int64 {
+	t.Helper()
+	var count int64
+	testDB.Model(&models.Item{}).Count(&count)
+	return count
+}
+
+func TestBulkCreate(t *testing.T) {
+	router := setupItemTest()
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+	body := `{"items":[{"name":"lamp","price":100},{"name":"x","price":100},{"name":"desk","price":200,"tags":["wood"]}]}`
+
+	// atomic: nothing is created because of the invalid entry
+	w := doJSON(router, "POST", "/v1/items/bulk?atomic=true", token, body)
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	results := decodeData[[]dto.BulkItemResultResponse](t, w)
+	assert.Equal(t, http.StatusFailedDependency, results[0].Status)
+	assert.Equal(t, string(utils.BulkEntryRolledBack), results[0].Error)
+	assert.Equal(t, http.StatusBadRequest, results[1].Status)
+	assert.Equal(t, string(utils.BadRequest), results[1].Error)
+	assert.Equal(t, int64(3), countItems(t))
+
+	// best effort: valid entries are created
+	w = doJSON(router, "POST", "/v1/items/bulk", token, body)
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	results = decodeData[[]dto.BulkItemResultResponse](t, w)
+	assert.Equal(t, http.StatusCreated, results[0].Status)
+	assert.Equal(t, "lamp", results[0].Item.Name)
+	assert.Equal(t, http.StatusBadRequest, results[1].Status)
+	assert.Nil(t, results[1].Item)
+	assert.Equal(t, []string{"wood"}, results[2].Item.Tags)
+	assert.Equal(t, int64(5), countItems(t))
+}
+
+func TestBulkUpdateAndDelete(t *testing.T) {
+	router := setupItemTest()
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+
+	// item 3 belongs to user 2
+	w := doJSON(router, "PATCH", "/v1/items/bulk?atomic=true", token, `{"items":[{"id":1,"price":50},{"id":3,"soldOut":true}]}`)
+	assert.Equal(t, http.StatusMultiStatus, w.Code)
+	results := decodeData[[]dto.BulkItemResultResponse](t, w)
+	assert.Equal(t, http.StatusFailedDependency, results[0].Status)
+	assert.Equal(t, http.StatusNotFound, results[1].Status)
+	assert.Equal(t, uint(3), *results[1].ID)
+	var item models.Item
+	testDB.First(&item, 1)
+	assert.Equal(t, uint(100), item.Price)
+
+	w = doJSON(router, "PATCH", "/v1/items/bulk", token, `{"items":[{"id":1,"price":50},{"id":2,"soldOut":false},{"id":1,"price":0}]}`)
+	results = decodeData[[]dto.BulkItemResultResponse](t, w)
+	assert.Equal(t, http.StatusOK, results[0].Status)
+	assert.Equal(t, uint(50), results[0].Item.Price)
+	assert.False(t, results[1].Item.SoldOut)
+	assert.Equal(t, http.StatusBadRequest, results[2].Status)
+
+	w = doJSON(router, "DELETE", "/v1/items/bulk", token, `{"ids":[1,3,2]}`)
+	results = decodeData[[]dto.BulkItemResultResponse](t, w)
+	assert.Equal(t, []int{http.StatusOK, http.StatusNotFound, http.StatusOK}, []int{results[0].Status, results[1].Status, results[2].Status})
+	assert.Equal(t, int64(1), countItems(t))
+}
+
+func TestBulk_InvalidRequest(t *testing.T) {
+	router := setupItemTest()
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+
+	tooMany := `{"ids":[` + strings.TrimSuffix(strings.Repeat("1,", 51), ",") + `]}`
+	cases := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{name: "no entries", method: "POST", path: "/v1/items/bulk", body: `{"items":[]}`},
+		{name: "too many entries", method: "DELETE", path: "/v1/items/bulk", body: tooMany},
+		{name: "invalid flag", method: "PATCH", path: "/v1/items/bulk?atomic=maybe", body: `{"items":[{"id":1}]}`},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := doJSON(router, tc.method, tc.path, token, tc.body)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
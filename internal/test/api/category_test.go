@@ -0,0 +1,147 @@
+package api_test
+
+import (
+	"flea-market/dto"
+	"flea-market/internal/app"
+	"flea-market/internal/test/fixtures"
+	test_utils "flea-market/internal/test/utils"
+	"flea-market/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func uintPtrOf(v uint) *uint { return &v }
+
+// electronics(1) ─ phones(2) ─ used phones(3)
+// books(4)
+func setupCategoryTest() *gin.Engine {
+	db := testDB
+	test_utils.DeleteTables(db)
+
+	fixtures.SeedUsers(db)
+
+	categories := []models.Category{
+		{Name: "Electronics", Slug: "electronics"},
+		{Name: "Phones", Slug: "phones", ParentID: uintPtrOf(1)},
+		{Name: "Used phones", Slug: "used-phones", ParentID: uintPtrOf(2)},
+		{Name: "Books", Slug: "books"},
+	}
+	for _, category := range categories {
+		db.Create(&category)
+	}
+
+	items := []models.Item{
+		{Name: "phone", Price: 100, UserID: 1, CategoryID: uintPtrOf(3), Condition: models.ConditionGood, Tags: []models.Tag{{Name: "apple"}}},
+		{Name: "charger", Price: 200, UserID: 1, CategoryID: uintPtrOf(1), SoldOut: true},
+		{Name: "novel", Price: 300, UserID: 2, CategoryID: uintPtrOf(4), Tags: []models.Tag{{Name: "fiction"}}},
+	}
+	for _, item := range items {
+		db.Create(&item)
+	}
+
+	return app.NewRouter(db)
+}
+
+func TestFindAll_FilterByCategoryAndTag(t *testing.T) {
+	router := setupCategoryTest()
+
+	cases := []struct {
+		name      string
+		query     string
+		wantNames []string
+	}{
+		{name: "subtree of root", query: "?category=1", wantNames: []string{"phone", "charger"}},
+		{name: "leaf", query: "?category=3", wantNames: []string{"phone"}},
+		{name: "tag", query: "?tag=Fiction", wantNames: []string{"novel"}},
+		{name: "category and tag", query: "?category=1&tag=fiction", wantNames: []string{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/v2/items"+tc.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			names := []string{}
+			for _, item := range decodeData[[]dto.ItemResponse](t, w) {
+				names = append(names, item.Name)
+			}
+			assert.ElementsMatch(t, tc.wantNames, names)
+		})
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v2/items?category=abc", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestFindCategoryTree(t *testing.T) {
+	router := setupCategoryTest()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v2/categories", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	tree := decodeData[[]dto.CategoryTreeNode](t, w)
+	assert.Len(t, tree, 2)
+	// the sold out charger isn't counted
+	assert.Equal(t, "electronics", tree[0].Slug)
+	assert.Equal(t, int64(1), tree[0].ItemCount)
+	assert.Equal(t, "phones", tree[0].Children[0].Slug)
+	assert.Equal(t, int64(1), tree[0].Children[0].ItemCount)
+	assert.Equal(t, "used-phones", tree[0].Children[0].Children[0].Slug)
+	assert.Equal(t, "books", tree[1].Slug)
+	assert.Equal(t, int64(1), tree[1].ItemCount)
+}
+
+func TestCreateAndUpdate_CategoryConditionTags(t *testing.T) {
+	router := setupCategoryTest()
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+
+	w := doJSON(router, "POST", "/v2/items", token, `{"name":"tablet","price":500,"categoryId":2,"condition":"like-new","tags":["Apple","tablet"]}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	created := decodeData[dto.ItemResponse](t, w)
+	assert.Equal(t, uint(2), *created.CategoryID)
+	assert.Equal(t, string(models.ConditionLikeNew), created.Condition)
+	// "apple" already exists and is shared
+	assert.ElementsMatch(t, []string{"apple", "tablet"}, created.Tags)
+
+	var appleCount int64
+	testDB.Model(&models.Tag{}).Where("name = ?", "apple").Count(&appleCount)
+	assert.Equal(t, int64(1), appleCount)
+
+	w = doJSON(router, "PUT", "/v2/items/1", token, `{"condition":"poor","tags":["broken"]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	updated := decodeData[dto.ItemResponse](t, w)
+	assert.Equal(t, string(models.ConditionPoor), updated.Condition)
+	assert.Equal(t, []string{"broken"}, updated.Tags)
+
+	cases := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "unknown condition", method: "POST", path: "/v2/items", body: `{"name":"x1","price":1,"condition":"broken"}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown category", method: "POST", path: "/v2/items", body: `{"name":"x1","price":1,"categoryId":999}`, wantStatus: http.StatusBadRequest},
+		{name: "empty tag", method: "POST", path: "/v2/items", body: `{"name":"x1","price":1,"tags":[""]}`, wantStatus: http.StatusBadRequest},
+		{name: "unknown condition on update", method: "PUT", path: "/v2/items/1", body: `{"condition":"mint"}`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := doJSON(router, tc.method, tc.path, token, tc.body)
+			assert.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package api_test
+
+import (
+	"flea-market/dto"
+	"flea-market/internal/test/fixtures"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemCurrency(t *testing.T) {
+	router := setupItemTest()
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+
+	w := doJSON(router, "POST", "/v2/items", token, `{"name":"jeans","price":8500,"currency":"USD"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	jeans := decodeData[dto.ItemResponse](t, w)
+	assert.Equal(t, "USD", jeans.Currency)
+	assert.Equal(t, "$85.00", jeans.FormattedPrice)
+	assert.Nil(t, jeans.DisplayPrice)
+
+	t.Run("limits depend on the currency", func(t *testing.T) {
+		// 49 cents
+		w := doJSON(router, "POST", "/v2/items", token, `{"name":"pen","price":49,"currency":"USD"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = doJSON(router, "POST", "/v2/items", token, `{"name":"pen","price":49}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		w = doJSON(router, "POST", "/v2/items", token, `{"name":"pen","price":100,"currency":"XYZ"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = doJSON(router, "PUT", "/v2/items/1", token, `{"price":10,"currency":"USD"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("list with converted prices", func(t *testing.T) {
+		w := doJSON(router, "GET", "/v2/items?currency=USD", "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		items := decodeData[[]dto.ItemResponse](t, w)
+		assert.NotEmpty(t, items)
+		byName := map[string]dto.ItemResponse{}
+		for _, item := range items {
+			assert.NotNil(t, item.DisplayPrice, item.Name)
+			byName[item.Name] = item
+		}
+		// ¥100 at the default rate of 0.0067
+		assert.Equal(t, uint(100), byName["test1"].Price)
+		assert.Equal(t, "JPY", byName["test1"].Currency)
+		assert.Equal(t, &dto.PriceResponse{Amount: 67, Currency: "USD", Formatted: "$0.67"}, byName["test1"].DisplayPrice)
+		assert.Equal(t, &dto.PriceResponse{Amount: 8500, Currency: "USD", Formatted: "$85.00"}, byName["jeans"].DisplayPrice)
+
+		w = doJSON(router, "GET", "/v2/items", "", "")
+		for _, item := range decodeData[[]dto.ItemResponse](t, w) {
+			assert.Nil(t, item.DisplayPrice)
+		}
+
+		w = doJSON(router, "GET", "/v2/items?currency=BTC", "", "")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,35 @@
+package api_test
+
+import (
+	"flea-market/dto"
+	"flea-market/internal/app"
+	"flea-market/internal/test/fixtures"
+	"flea-market/models"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiagnosticsDB(t *testing.T) {
+	setupItemTestData(testDB)
+	router := app.NewRouter(testDB)
+	user := tokenFor(t, 1, fixtures.UserData[0].Email)
+	admin := tokenFor(t, 2, fixtures.UserData[1].Email)
+	assert.NoError(t, testDB.Model(&models.User{}).Where("id = ?", 2).Update("admin", true).Error)
+
+	// the stats tell the topology of the DBs, so only admins can see them
+	w := doJSON(router, "GET", "/diagnostics/db", "", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = doJSON(router, "GET", "/diagnostics/db", user, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doJSON(router, "GET", "/diagnostics/db", admin, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	stats := decodeData[[]dto.DBPoolStatsResponse](t, w)
+	assert.Len(t, stats, 1)
+	assert.Equal(t, "primary", stats[0].Name)
+	assert.True(t, stats[0].Healthy)
+	assert.Positive(t, stats[0].MaxOpenConnections)
+	assert.GreaterOrEqual(t, stats[0].OpenConnections, 1)
+}
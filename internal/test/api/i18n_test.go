@@ -0,0 +1,65 @@
+package api_test
+
+import (
+	"encoding/json"
+	"flea-market/internal/i18n"
+	"flea-market/internal/test/fixtures"
+	"flea-market/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type errorBody struct {
+	Error   string            `json:"error"`
+	Message string            `json:"message"`
+	Details []i18n.FieldError `json:"details"`
+}
+
+func TestErrorResponse_AcceptLanguage(t *testing.T) {
+	router := setupItemTest()
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+
+	request := func(method string, path string, body string, acceptLanguage string) (*httptest.ResponseRecorder, errorBody) {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		router.ServeHTTP(w, req)
+		var res errorBody
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &res))
+		return w, res
+	}
+
+	w, res := request("GET", "/items/999", "", "ja-JP,en;q=0.8")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, "ja", w.Header().Get("Content-Language"))
+	assert.Equal(t, errorBody{Error: string(utils.NotFound), Message: "対象が見つかりません。"}, res)
+
+	w, res = request("GET", "/items/999", "", "fr")
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+	assert.Equal(t, "The resource was not found.", res.Message)
+
+	t.Run("validation errors have the failed fields", func(t *testing.T) {
+		w, res := request("POST", "/items", `{"name":"x","tags":[""]}`, "ja")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Equal(t, "リクエストが正しくありません。", res.Message)
+		assert.Equal(t, []i18n.FieldError{
+			{Field: "name", Message: "nameの長さは少なくとも2文字はなければなりません"},
+			{Field: "price", Message: "priceは必須フィールドです"},
+			{Field: "tags[0]", Message: "tags[0]の長さは少なくとも1文字はなければなりません"},
+		}, res.Details)
+
+		_, res = request("POST", "/items", `{"name":"lamp"}`, "en")
+		assert.Equal(t, []i18n.FieldError{{Field: "price", Message: "price is a required field"}}, res.Details)
+	})
+
+	t.Run("other errors don't have details", func(t *testing.T) {
+		_, res := request("POST", "/items", `{`, "en")
+		assert.Equal(t, string(utils.BadRequest), res.Error)
+		assert.Nil(t, res.Details)
+	})
+}
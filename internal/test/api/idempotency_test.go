@@ -0,0 +1,55 @@
+package api_test
+
+import (
+	"flea-market/internal/test/fixtures"
+	"flea-market/middlewares"
+	"flea-market/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func postWithKey(router *gin.Engine, path string, token string, key string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(middlewares.IdempotencyKeyHeader, key)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestIdempotencyKey(t *testing.T) {
+	router := setupItemTest()
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+	body := `{"name":"lamp","price":100}`
+
+	first := postWithKey(router, "/v1/items", token, "create-lamp", body)
+	assert.Equal(t, http.StatusCreated, first.Code)
+	retry := postWithKey(router, "/v1/items", token, "create-lamp", body)
+	assert.Equal(t, http.StatusCreated, retry.Code)
+	assert.Equal(t, "true", retry.Header().Get(middlewares.IdempotentReplayedHeader))
+	assert.JSONEq(t, first.Body.String(), retry.Body.String())
+	assert.Equal(t, int64(4), countItems(t))
+
+	w := postWithKey(router, "/v1/items", token, "create-lamp", `{"name":"desk","price":100}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, string(utils.IdempotencyKeyReused), errorCode(w))
+
+	// keys are scoped per user
+	other := tokenFor(t, 2, fixtures.UserData[1].Email)
+	w = postWithKey(router, "/v1/items", other, "create-lamp", body)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Empty(t, w.Header().Get(middlewares.IdempotentReplayedHeader))
+	assert.Equal(t, int64(5), countItems(t))
+
+	// a failed request can be retried with the same key
+	w = postWithKey(router, "/v1/items", token, "invalid", `{"name":"x"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = postWithKey(router, "/v1/items", token, "invalid", `{"name":"x"}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Empty(t, w.Header().Get(middlewares.IdempotentReplayedHeader))
+}
@@ -9,6 +9,8 @@ import (
 	"flea-market/dto"
 	"flea-market/internal/app"
 	"flea-market/internal/mocks"
+	"flea-market/internal/money"
+	"flea-market/internal/test/fixtures"
 	test_utils "flea-market/internal/test/utils"
 	"flea-market/middlewares"
 	"flea-market/models"
@@ -30,13 +32,7 @@ import (
 func setupItemTestData(db *gorm.DB) {
 	test_utils.DeleteTables(db)
 
-	for _, user := range test_utils.UserData {
-		db.Create(&user)
-	}
-
-	for _, item := range test_utils.ItemData {
-		db.Create(&item)
-	}
+	fixtures.SeedUsersAndItems(db)
 
 }
 func setupItemTest() *gin.Engine {
@@ -48,7 +44,7 @@ func setupItemTest() *gin.Engine {
 }
 
 func setUpRouterWithItemRepo(itemRepo services.IItemRepository) *gin.Engine {
-	itemService := services.NewItemService(itemRepo)
+	itemService := services.NewItemService(itemRepo, &mocks.MockTxManager{}, &mocks.MockItemLimiter{}, &mocks.MockModerator{}, money.DefaultRates())
 	itemController := controllers.NewItemController(itemService)
 
 	router := gin.New()
@@ -101,7 +97,7 @@ func TestFindAll(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, 3, len(res["data"]))
 
-	assert.ElementsMatch(t, toTestItems(test_utils.ItemData), toTestItems(res["data"]))
+	assert.ElementsMatch(t, toTestItems(fixtures.ItemData), toTestItems(res["data"]))
 
 	// Reflect Ver little bit too complicated
 
@@ -121,12 +117,12 @@ func TestFindAll(t *testing.T) {
 func TestFindById(t *testing.T) {
 	router := setupItemTest()
 
-	token, _ := services.CreateToken(1, test_utils.UserData[0].Email)
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
 
 	w := httptest.NewRecorder()
 
 	req, _ := http.NewRequest("GET", "/items/1", nil)
-	req.Header.Set("Authorization", "Bearer "+*token)
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	router.ServeHTTP(w, req)
 
@@ -136,14 +132,13 @@ func TestFindById(t *testing.T) {
 	assert.Equal(t, http.StatusOK, w.Code)
 	assert.Equal(t, uint(1), res["data"].ID)
 
-	assert.Equal(t, toTestItem(test_utils.ItemData[0]), toTestItem(res["data"]))
+	assert.Equal(t, toTestItem(fixtures.ItemData[0]), toTestItem(res["data"]))
 }
 
 func TestCreate(t *testing.T) {
 	router := setupItemTest()
 
-	token, err := services.CreateToken(1, test_utils.UserData[0].Email)
-	assert.Equal(t, nil, err)
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
 
 	createItemInput := dto.CreateItemInput{
 		Name:        "test item New",
@@ -156,7 +151,7 @@ func TestCreate(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	req, _ := http.NewRequest("POST", "/items", bytes.NewBuffer(reqBody))
-	req.Header.Set("Authorization", "Bearer "+*token)
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	router.ServeHTTP(w, req)
 
@@ -179,14 +174,13 @@ func TestCreate(t *testing.T) {
 func TestUpdate(t *testing.T) {
 	router := setupItemTest()
 
-	token, err := services.CreateToken(1, test_utils.UserData[0].Email)
-	assert.Equal(t, nil, err)
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
 	reqBody := `{"name":"12","price":999,"description":"updated","soldOut":true}`
 
 	w := httptest.NewRecorder()
 
 	req, _ := http.NewRequest("PUT", "/items/1", bytes.NewBuffer([]byte(reqBody)))
-	req.Header.Set("Authorization", "Bearer "+*token)
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	router.ServeHTTP(w, req)
 
@@ -213,13 +207,12 @@ func TestUpdate(t *testing.T) {
 func Test_Delete(t *testing.T) {
 	router := setupItemTest()
 
-	token, err := services.CreateToken(1, test_utils.UserData[0].Email)
-	assert.Equal(t, nil, err)
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
 
 	w := httptest.NewRecorder()
 
 	req, _ := http.NewRequest("DELETE", "/items/1", nil)
-	req.Header.Set("Authorization", "Bearer "+*token)
+	req.Header.Set("Authorization", "Bearer "+token)
 
 	router.ServeHTTP(w, req)
 
@@ -231,7 +224,7 @@ func Test_Delete(t *testing.T) {
 	// Check if the record is deleted.
 	w2 := httptest.NewRecorder()
 	reqGet, _ := http.NewRequest("GET", "/items/1", nil)
-	reqGet.Header.Set("Authorization", "Bearer "+*token)
+	reqGet.Header.Set("Authorization", "Bearer "+token)
 	router.ServeHTTP(w2, reqGet)
 
 	assert.Equal(t, http.StatusNotFound, w2.Code)
@@ -241,8 +234,7 @@ func Test_Delete(t *testing.T) {
 func Test_FindById_Wrong_ID(t *testing.T) {
 	router := setupItemTest()
 
-	token, err := services.CreateToken(1, test_utils.UserData[0].Email)
-	assert.Equal(t, nil, err)
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
 
 	cases := []struct {
 		name       string
@@ -271,7 +263,7 @@ func Test_FindById_Wrong_ID(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			req, _ := http.NewRequest("GET", fmt.Sprintf("/items/%v", tc.param), nil)
-			req.Header.Set("Authorization", "Bearer "+*token)
+			req.Header.Set("Authorization", "Bearer "+token)
 
 			router.ServeHTTP(w, req)
 
@@ -284,8 +276,7 @@ func Test_FindById_Wrong_ID(t *testing.T) {
 func Test_Delete_Wrong_ID(t *testing.T) {
 	router := setupItemTest()
 
-	token, err := services.CreateToken(1, test_utils.UserData[0].Email)
-	assert.Equal(t, nil, err)
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
 
 	cases := []struct {
 		name       string
@@ -313,7 +304,7 @@ func Test_Delete_Wrong_ID(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			req, _ := http.NewRequest("DELETE", "/items/"+tc.param, nil)
-			req.Header.Set("Authorization", "Bearer "+*token)
+			req.Header.Set("Authorization", "Bearer "+token)
 
 			router.ServeHTTP(w, req)
 
@@ -326,8 +317,7 @@ func Test_Delete_Wrong_ID(t *testing.T) {
 func Test_Create_Wrong_Input(t *testing.T) {
 	router := setupItemTest()
 
-	token, err := services.CreateToken(1, test_utils.UserData[0].Email)
-	assert.Equal(t, nil, err)
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
 
 	cases := []struct {
 		name       string
@@ -360,7 +350,7 @@ func Test_Create_Wrong_Input(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			req, _ := http.NewRequest("POST", "/items", strings.NewReader(tc.body))
-			req.Header.Set("Authorization", "Bearer "+*token)
+			req.Header.Set("Authorization", "Bearer "+token)
 
 			router.ServeHTTP(w, req)
 
@@ -373,8 +363,7 @@ func Test_Create_Wrong_Input(t *testing.T) {
 func Test_Update_Wrong_Input(t *testing.T) {
 	router := setupItemTest()
 
-	token, err := services.CreateToken(1, test_utils.UserData[0].Email)
-	assert.Equal(t, nil, err)
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
 
 	cases := []struct {
 		name       string
@@ -407,7 +396,7 @@ func Test_Update_Wrong_Input(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			w := httptest.NewRecorder()
 			req, _ := http.NewRequest("PUT", "/items/1", strings.NewReader(tc.body))
-			req.Header.Set("Authorization", "Bearer "+*token)
+			req.Header.Set("Authorization", "Bearer "+token)
 
 			router.ServeHTTP(w, req)
 
@@ -480,7 +469,7 @@ func Test_Unauthorized(t *testing.T) {
 
 func TestItems_FindAll_DBError_Non_CustomError(t *testing.T) {
 	mockRepo := &mocks.MockItemRepository{
-		FindAllFunc: func(ctx context.Context) (*[]models.Item, error) {
+		FindAllFunc: func(ctx context.Context, query dto.ItemQuery) (*[]models.Item, error) {
 			return nil, errors.New("mock db error")
 		},
 	}
@@ -497,7 +486,7 @@ func TestItems_FindAll_DBError_Non_CustomError(t *testing.T) {
 
 func TestItems_FindAll_DBError_CustomError(t *testing.T) {
 	mockRepo := &mocks.MockItemRepository{
-		FindAllFunc: func(ctx context.Context) (*[]models.Item, error) {
+		FindAllFunc: func(ctx context.Context, query dto.ItemQuery) (*[]models.Item, error) {
 			return nil, utils.NewDBError("Mock", errors.New("Mock"))
 		},
 	}
@@ -524,9 +513,9 @@ func Test_Unauthorized_Invalid_Token(t *testing.T) {
 func Test_Forbidden_Access_OtherUserItem(t *testing.T) {
 	router := setupItemTest()
 
-	token, _ := services.CreateToken(2, test_utils.UserData[1].Email)
+	token := tokenFor(t, 2, fixtures.UserData[1].Email)
 	req, _ := http.NewRequest("DELETE", "/items/1", nil)
-	req.Header.Set("Authorization", "Bearer "+*token)
+	req.Header.Set("Authorization", "Bearer "+token)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 
@@ -535,10 +524,10 @@ func Test_Forbidden_Access_OtherUserItem(t *testing.T) {
 
 func Test_Forbidden_Update_OtherUserItem(t *testing.T) {
 	router := setupItemTest()
-	token, _ := services.CreateToken(2, test_utils.UserData[1].Email)
+	token := tokenFor(t, 2, fixtures.UserData[1].Email)
 	reqBody := `{"name":"test update","price":111,"description":"try update"}`
 	req, _ := http.NewRequest("PUT", "/items/1", strings.NewReader(reqBody))
-	req.Header.Set("Authorization", "Bearer "+*token)
+	req.Header.Set("Authorization", "Bearer "+token)
 	w := httptest.NewRecorder()
 	router.ServeHTTP(w, req)
 	assert.Equal(t, http.StatusNotFound, w.Code)
@@ -546,16 +535,16 @@ func Test_Forbidden_Update_OtherUserItem(t *testing.T) {
 
 func Test_Update_DeletedItem(t *testing.T) {
 	router := setupItemTest()
-	token, _ := services.CreateToken(1, test_utils.UserData[0].Email)
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
 	// まず削除
 	reqDel, _ := http.NewRequest("DELETE", "/items/1", nil)
-	reqDel.Header.Set("Authorization", "Bearer "+*token)
+	reqDel.Header.Set("Authorization", "Bearer "+token)
 	wDel := httptest.NewRecorder()
 	router.ServeHTTP(wDel, reqDel)
 
 	// 削除済みIDで更新
 	reqUpd, _ := http.NewRequest("PUT", "/items/1", strings.NewReader(`{"name":"test","price":123,"description":""}`))
-	reqUpd.Header.Set("Authorization", "Bearer "+*token)
+	reqUpd.Header.Set("Authorization", "Bearer "+token)
 	wUpd := httptest.NewRecorder()
 	router.ServeHTTP(wUpd, reqUpd)
 	assert.Equal(t, http.StatusNotFound, wUpd.Code)
@@ -566,8 +555,7 @@ func Test_AllEndpoints_Concurrent(t *testing.T) {
 		t.Skip("skip: race detector not enabled")
 	}
 	router := setupItemTest()
-	token, err := services.CreateToken(1, test_utils.UserData[0].Email)
-	assert.NoError(t, err)
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
 
 	var wg sync.WaitGroup
 	threadNum := 10
@@ -584,7 +572,7 @@ func Test_AllEndpoints_Concurrent(t *testing.T) {
 			}
 			bodyBytes, _ := json.Marshal(body)
 			req, _ := http.NewRequest("POST", "/items", bytes.NewBuffer(bodyBytes))
-			req.Header.Set("Authorization", "Bearer "+*token)
+			req.Header.Set("Authorization", "Bearer "+token)
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
 			assert.Equal(t, http.StatusCreated, w.Code)
@@ -608,7 +596,7 @@ func Test_AllEndpoints_Concurrent(t *testing.T) {
 			}
 			bodyBytes, _ := json.Marshal(body)
 			req, _ := http.NewRequest("PUT", "/items/1", bytes.NewBuffer(bodyBytes))
-			req.Header.Set("Authorization", "Bearer "+*token)
+			req.Header.Set("Authorization", "Bearer "+token)
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
 			// 既に消されてる場合も考慮して2xx/404でも許可
@@ -622,7 +610,7 @@ func Test_AllEndpoints_Concurrent(t *testing.T) {
 		go func() {
 			defer wg.Done()
 			req, _ := http.NewRequest("DELETE", "/items/1", nil)
-			req.Header.Set("Authorization", "Bearer "+*token)
+			req.Header.Set("Authorization", "Bearer "+token)
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
 			// 既に消されてる場合も考慮して2xx/404でも許可
@@ -648,7 +636,7 @@ func Test_AllEndpoints_Concurrent(t *testing.T) {
 		go func() {
 			defer wg.Done()
 			req, _ := http.NewRequest("GET", "/items/1", nil)
-			req.Header.Set("Authorization", "Bearer "+*token)
+			req.Header.Set("Authorization", "Bearer "+token)
 			w := httptest.NewRecorder()
 			router.ServeHTTP(w, req)
 			// 更新/削除が同時なのでOK/404どちらでも
@@ -0,0 +1,81 @@
+package api_test
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"flea-market/dto"
+	"flea-market/internal/app"
+	"flea-market/internal/jwtauth"
+	"flea-market/internal/test/fixtures"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupKeySet sets JWT_KEYS_FILE to a keyset of a new Ed25519 key with the id kid.
+func setupKeySet(t *testing.T, kid string) {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, kid+".pem"), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600))
+	manifest := "signing: " + kid + "\nkeys: [{id: " + kid + ", file: " + kid + ".pem}]\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "keys.yaml"), []byte(manifest), 0o600))
+	t.Setenv("JWT_KEYS_FILE", filepath.Join(dir, "keys.yaml"))
+}
+
+// Another service verifies the token by the public key in the JWKS, without the secret.
+func TestJWKS(t *testing.T) {
+	setupKeySet(t, "2026-10")
+	router := setupAuthTest()
+
+	reqBody, _ := json.Marshal(dto.LoginInput{Email: fixtures.UserData[0].Email, Password: fixtures.UserData[0].Password})
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/v2/auth/login", bytes.NewBuffer(reqBody))
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	token := decodeData[dto.TokenResponse](t, w).Token
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+	var jwks jwtauth.JWKS
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &jwks))
+	require.Len(t, jwks.Keys, 1)
+	assert.Equal(t, "2026-10", jwks.Keys[0].Kid)
+	assert.Equal(t, "OKP", jwks.Keys[0].Kty)
+
+	parsed, err := jwt.Parse(token, func(t *jwt.Token) (any, error) {
+		x, err := base64.RawURLEncoding.DecodeString(jwks.Keys[0].X)
+		return ed25519.PublicKey(x), err
+	}, jwt.WithValidMethods([]string{"EdDSA"}), jwt.WithIssuer("flea-market"), jwt.WithAudience("flea-market"))
+	require.NoError(t, err)
+	assert.Equal(t, "2026-10", parsed.Header["kid"])
+
+	assert.Equal(t, http.StatusOK, doJSON(router, "GET", "/me/limits", token, "").Code)
+}
+
+func TestTokenOfAnotherKeySet(t *testing.T) {
+	setupKeySet(t, "a")
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+
+	setupKeySet(t, "b")
+	router := app.NewRouter(testDB)
+	assert.Equal(t, http.StatusUnauthorized, doJSON(router, "GET", "/me/limits", token, "").Code)
+}
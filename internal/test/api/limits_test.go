@@ -0,0 +1,156 @@
+package api_test
+
+import (
+	"flea-market/dto"
+	"flea-market/internal/test/fixtures"
+	"flea-market/models"
+	"flea-market/services"
+	"flea-market/utils"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemLimits(t *testing.T) {
+	router := setupItemTest()
+	seller := tokenFor(t, 1, fixtures.UserData[0].Email)
+	admin := tokenFor(t, 2, fixtures.UserData[1].Email)
+	assert.NoError(t, testDB.Model(&models.User{}).Where("id = ?", 2).Update("admin", true).Error)
+	free := services.DefaultPlanLimits[models.PlanFree]
+
+	w := doJSON(router, "GET", "/me/limits", seller, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	status := decodeData[dto.ItemLimitStatusResponse](t, w)
+	assert.Equal(t, models.PlanFree, status.Plan)
+	assert.False(t, status.Overridden)
+	assert.Equal(t, free.MaxPrice, status.Limits.MaxPrice)
+	// user1 has an unsold item and a sold one
+	assert.Equal(t, dto.ItemUsageResponse{ActiveListings: 1, CreatesPerDay: 2}, status.Usage)
+
+	t.Run("price over the limit is 403", func(t *testing.T) {
+		w := doJSON(router, "POST", "/v2/items", seller, fmt.Sprintf(`{"name":"car","price":%d}`, free.MaxPrice+1))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), string(utils.ItemLimitExceeded))
+	})
+
+	t.Run("only admins can override", func(t *testing.T) {
+		w := doJSON(router, "PUT", "/admin/users/1/limits", seller, `{"maxCreatesPerDay":100}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = doJSON(router, "GET", "/admin/users/999/limits", admin, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("creates per day over the override are 429", func(t *testing.T) {
+		w := doJSON(router, "PUT", "/admin/users/1/limits", admin, `{"maxCreatesPerDay":3}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		status := decodeData[dto.ItemLimitStatusResponse](t, w)
+		assert.True(t, status.Overridden)
+		assert.Equal(t, uint(3), status.Limits.MaxCreatesPerDay)
+		assert.Equal(t, free.MaxPrice, status.Limits.MaxPrice)
+
+		w = doJSON(router, "POST", "/v2/items", seller, `{"name":"lamp","price":100}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		created := decodeData[dto.ItemResponse](t, w)
+		// deleting doesn't give the create back
+		w = doJSON(router, "DELETE", fmt.Sprintf("/v2/items/%d", created.ID), seller, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = doJSON(router, "POST", "/v2/items", seller, `{"name":"lamp","price":100}`)
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Contains(t, w.Body.String(), string(utils.ItemCreateRateLimited))
+
+		w = doJSON(router, "POST", "/v2/items/bulk", seller, `{"items":[{"name":"lamp","price":100}]}`)
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		assert.Contains(t, w.Body.String(), string(utils.ItemCreateRateLimited))
+	})
+
+	t.Run("plan change and reset", func(t *testing.T) {
+		w := doJSON(router, "PUT", "/admin/users/1/limits", admin, `{"plan":"pro"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		status := decodeData[dto.ItemLimitStatusResponse](t, w)
+		assert.Equal(t, models.PlanPro, status.Plan)
+		assert.False(t, status.Overridden)
+		assert.Equal(t, services.DefaultPlanLimits[models.PlanPro], models.ItemLimits{
+			MaxActiveListings: status.Limits.MaxActiveListings,
+			MaxCreatesPerDay:  status.Limits.MaxCreatesPerDay,
+			MaxPrice:          status.Limits.MaxPrice,
+		})
+
+		w = doJSON(router, "PUT", "/admin/users/1/limits", admin, `{"plan":"gold"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		w = doJSON(router, "PUT", "/admin/users/1/limits", admin, `{"maxActiveListings":0}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = doJSON(router, "POST", "/v2/items", seller, `{"name":"lamp","price":100}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = doJSON(router, "DELETE", "/admin/users/1/limits", admin, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = doJSON(router, "POST", "/v2/items", seller, `{"name":"lamp","price":100}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+	})
+	t.Run("updates can't bypass the limits", func(t *testing.T) {
+		w := doJSON(router, "GET", "/me/limits", seller, "")
+		status := decodeData[dto.ItemLimitStatusResponse](t, w)
+		w = doJSON(router, "PUT", "/admin/users/1/limits", admin, fmt.Sprintf(`{"maxPrice":1000,"maxActiveListings":%d}`, status.Usage.ActiveListings))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = doJSON(router, "PUT", "/v2/items/1", seller, `{"price":1001}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), string(utils.ItemLimitExceeded))
+		w = doJSON(router, "PATCH", "/v1/items/bulk", seller, `{"items":[{"id":1,"price":1001}]}`)
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		assert.Contains(t, w.Body.String(), string(utils.ItemLimitExceeded))
+
+		// item 2 is sold out, and putting it back on sale makes one more active listing
+		w = doJSON(router, "PUT", "/v2/items/2", seller, `{"soldOut":false}`)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		assert.Contains(t, w.Body.String(), string(utils.ItemLimitExceeded))
+		w = doJSON(router, "PATCH", "/v1/items/bulk", seller, `{"items":[{"id":2,"soldOut":false}]}`)
+		assert.Equal(t, http.StatusMultiStatus, w.Code)
+		assert.Contains(t, w.Body.String(), string(utils.ItemLimitExceeded))
+
+		// the other changes are still allowed
+		w = doJSON(router, "PUT", "/v2/items/2", seller, `{"name":"renamed"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+func TestItemLimits_ConcurrentCreates(t *testing.T) {
+	router := setupItemTest()
+	seller := tokenFor(t, 1, fixtures.UserData[0].Email)
+	admin := tokenFor(t, 2, fixtures.UserData[1].Email)
+	assert.NoError(t, testDB.Model(&models.User{}).Where("id = ?", 2).Update("admin", true).Error)
+
+	// user1 has already created 2 items today, so 3 more are allowed
+	w := doJSON(router, "PUT", "/admin/users/1/limits", admin, `{"maxCreatesPerDay":5}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var wg sync.WaitGroup
+	codes := make([]int, 10)
+	for i := range codes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			codes[i] = doJSON(router, "POST", "/v2/items", seller, fmt.Sprintf(`{"name":"lamp%d","price":100}`, i)).Code
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, code := range codes {
+		if code == http.StatusCreated {
+			created++
+		} else {
+			assert.Equal(t, http.StatusTooManyRequests, code)
+		}
+	}
+	assert.Equal(t, 3, created)
+
+	w = doJSON(router, "GET", "/me/limits", seller, "")
+	status := decodeData[dto.ItemLimitStatusResponse](t, w)
+	assert.Equal(t, uint(5), status.Usage.CreatesPerDay)
+}
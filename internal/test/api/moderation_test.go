@@ -0,0 +1,111 @@
+package api_test
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/internal/seed"
+	"flea-market/internal/test/fixtures"
+	"flea-market/models"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestModeration(t *testing.T) {
+	router := setupItemTest()
+	seller := tokenFor(t, 1, fixtures.UserData[0].Email)
+	admin := tokenFor(t, 2, fixtures.UserData[1].Email)
+	assert.NoError(t, testDB.Model(&models.User{}).Where("id = ?", 2).Update("admin", true).Error)
+	refs, err := seed.NewSeeder(testDB).Load(context.Background(), seed.Fixtures{Users: []seed.UserFixture{
+		{Ref: "carol", Email: "carol@example.com", Password: "password"},
+		{Ref: "dave", Email: "dave@example.com", Password: "password"},
+	}})
+	assert.NoError(t, err)
+	reporters := []string{admin, tokenFor(t, refs.Users["carol"], "carol@example.com"), tokenFor(t, refs.Users["dave"], "dave@example.com")}
+
+	listed := func(itemId uint) bool {
+		w := doJSON(router, "GET", "/v2/items", "", "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		for _, item := range decodeData[[]dto.ItemResponse](t, w) {
+			if item.ID == itemId {
+				return true
+			}
+		}
+		return false
+	}
+
+	t.Run("rejected item is seen only by the seller", func(t *testing.T) {
+		w := doJSON(router, "POST", "/v2/items", seller, `{"name":"Counterfeit watch","price":5000}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		item := decodeData[dto.ItemResponse](t, w)
+		assert.Equal(t, string(models.ModerationRejected), item.ModerationStatus)
+		assert.NotEmpty(t, item.ModerationReason)
+
+		assert.False(t, listed(item.ID))
+		w = doJSON(router, "GET", fmt.Sprintf("/v2/items/%d", item.ID), seller, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = doJSON(router, "POST", fmt.Sprintf("/v2/items/%d/offers", item.ID), reporters[0], `{"price":4000}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("reports hide the item until an admin approves it", func(t *testing.T) {
+		assert.True(t, listed(1))
+		w := doJSON(router, "POST", "/v2/items/1/report", seller, `{"reason":"fraud"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = doJSON(router, "POST", "/v2/items/1/report", reporters[0], `{"reason":"spam"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		for i, reporter := range reporters {
+			w := doJSON(router, "POST", "/v2/items/1/report", reporter, `{"reason":"fraud","comment":"too cheap"}`)
+			assert.Equal(t, http.StatusCreated, w.Code)
+			// hidden by the third report
+			assert.Equal(t, i < 2, listed(1))
+		}
+		w = doJSON(router, "POST", "/v2/items/1/report", reporters[0], `{"reason":"fraud"}`)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+
+		w = doJSON(router, "GET", "/admin/moderation/queue", seller, "")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = doJSON(router, "GET", "/admin/moderation/queue", admin, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		queue := decodeData[[]dto.ModerationQueueEntryResponse](t, w)
+		// the rejected item has no reports, so it isn't in the queue
+		if assert.Len(t, queue, 1) {
+			assert.Equal(t, uint(1), queue[0].Item.ID)
+			assert.Equal(t, "reported by 3 users", queue[0].Item.ModerationReason)
+			assert.Len(t, queue[0].Reports, 3)
+		}
+
+		w = doJSON(router, "POST", "/admin/moderation/items/1", admin, `{"status":"rejected"}`)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		w = doJSON(router, "POST", "/admin/moderation/items/1", admin, `{"status":"approved"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, string(models.ModerationApproved), decodeData[dto.ItemResponse](t, w).ModerationStatus)
+		assert.True(t, listed(1))
+
+		w = doJSON(router, "GET", "/admin/moderation/queue", admin, "")
+		assert.Empty(t, decodeData[[]dto.ModerationQueueEntryResponse](t, w))
+	})
+
+	t.Run("pending item waits in the queue", func(t *testing.T) {
+		w := doJSON(router, "POST", "/v2/items", seller, `{"name":"ライブチケット 2枚","price":9000}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+		item := decodeData[dto.ItemResponse](t, w)
+		assert.Equal(t, string(models.ModerationPending), item.ModerationStatus)
+		assert.False(t, listed(item.ID))
+
+		w = doJSON(router, "GET", "/admin/moderation/queue", admin, "")
+		queue := decodeData[[]dto.ModerationQueueEntryResponse](t, w)
+		if assert.Len(t, queue, 1) {
+			assert.Equal(t, item.ID, queue[0].Item.ID)
+			assert.Empty(t, queue[0].Reports)
+		}
+
+		w = doJSON(router, "POST", fmt.Sprintf("/admin/moderation/items/%d", item.ID), admin, `{"status":"rejected","reason":"resale of tickets"}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		w = doJSON(router, "GET", fmt.Sprintf("/v2/items/%d", item.ID), seller, "")
+		assert.Equal(t, "resale of tickets", decodeData[dto.ItemResponse](t, w).ModerationReason)
+	})
+}
@@ -0,0 +1,171 @@
+package api_test
+
+import (
+	"bufio"
+	"context"
+	"flea-market/internal/app"
+	"flea-market/internal/test/fixtures"
+	"flea-market/models"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sseEvent struct {
+	id    string
+	event string
+	data  string
+}
+
+// readEvent reads lines until a blank line, skipping heartbeat comments.
+func readEvent(t *testing.T, reader *bufio.Reader) sseEvent {
+	t.Helper()
+	var ev sseEvent
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("reading stream failed: %v", err)
+		}
+		line = strings.TrimRight(line, "\n")
+		switch {
+		case line == "":
+			if ev.id != "" || ev.event != "" {
+				return ev
+			}
+		case strings.HasPrefix(line, ":"):
+		case strings.HasPrefix(line, "id:"):
+			ev.id = strings.TrimSpace(strings.TrimPrefix(line, "id:"))
+		case strings.HasPrefix(line, "event:"):
+			ev.event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			ev.data = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+}
+
+func setupNotificationTest() *httptest.Server {
+	db := testDB
+	setupItemTestData(db)
+
+	notifications := []models.Notification{
+		{UserID: 1, Type: models.NotificationOfferReceived, Payload: `{"n":1}`},
+		{UserID: 1, Type: models.NotificationItemSold, Payload: `{"n":2}`},
+		{UserID: 2, Type: models.NotificationOfferReceived, Payload: `{"n":3}`},
+		{UserID: 1, Type: models.NotificationOfferReceived, Payload: `{"n":4}`},
+	}
+	for _, n := range notifications {
+		db.Create(&n)
+	}
+
+	return httptest.NewServer(app.NewRouter(db))
+}
+
+func openStream(t *testing.T, ctx context.Context, url string, token string, lastEventId string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequestWithContext(ctx, "GET", url+"/me/events", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	if lastEventId != "" {
+		req.Header.Set("Last-Event-ID", lastEventId)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	return res
+}
+
+func TestNotificationStream_ResumeAndLive(t *testing.T) {
+	server := setupNotificationTest()
+	defer server.Close()
+
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	res := openStream(t, ctx, server.URL, token, "1")
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(res.Body)
+
+	// id 1 is already received, id 3 belongs to another user
+	ev := readEvent(t, reader)
+	assert.Equal(t, "2", ev.id)
+	assert.Equal(t, string(models.NotificationItemSold), ev.event)
+	ev = readEvent(t, reader)
+	assert.Equal(t, "4", ev.id)
+
+	// live notification triggered by user2 purchasing the item
+	purchaseReq, _ := http.NewRequest("POST", server.URL+"/items/1/purchase", nil)
+	purchaseReq.Header.Set("Authorization", "Bearer "+tokenFor(t, 2, fixtures.UserData[1].Email))
+	purchaseRes, err := http.DefaultClient.Do(purchaseReq)
+	assert.NoError(t, err)
+	purchaseRes.Body.Close()
+	assert.Equal(t, http.StatusCreated, purchaseRes.StatusCode)
+
+	ev = readEvent(t, reader)
+	assert.Equal(t, "5", ev.id)
+	assert.Equal(t, string(models.NotificationItemSold), ev.event)
+	assert.Contains(t, ev.data, `"itemId":1`)
+}
+
+func TestNotificationStream_InvalidLastEventID(t *testing.T) {
+	server := setupNotificationTest()
+	defer server.Close()
+
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+
+	res := openStream(t, context.Background(), server.URL, token, "abc")
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusBadRequest, res.StatusCode)
+}
+
+func TestNotificationStream_Unauthorized(t *testing.T) {
+	router := setupItemTest()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/me/events", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+// Only the recent notifications are replayed, so that a client without Last-Event-ID doesn't get the whole history.
+func TestNotificationStream_ReplayIsCapped(t *testing.T) {
+	server := setupNotificationTest()
+	defer server.Close()
+
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+
+	old := models.Notification{UserID: 1, Type: models.NotificationItemSold, Payload: `{"n":5}`}
+	old.CreatedAt = time.Now().Add(-48 * time.Hour)
+	testDB.Create(&old)
+	testDB.Create(&models.Notification{UserID: 1, Type: models.NotificationItemSold, Payload: `{"n":6}`})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	// id 5 is older than the window
+	res := openStream(t, ctx, server.URL, token, "4")
+	assert.Equal(t, "6", readEvent(t, bufio.NewReader(res.Body)).id)
+	res.Body.Close()
+
+	recent := make([]models.Notification, 100)
+	for i := range recent {
+		recent[i] = models.Notification{UserID: 1, Type: models.NotificationOfferReceived, Payload: `{}`}
+	}
+	testDB.Create(&recent)
+
+	// the latest 100 are 7 to 106
+	res = openStream(t, ctx, server.URL, token, "")
+	defer res.Body.Close()
+	assert.Equal(t, "7", readEvent(t, bufio.NewReader(res.Body)).id)
+}
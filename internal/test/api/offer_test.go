@@ -0,0 +1,191 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"flea-market/dto"
+	"flea-market/internal/app"
+	"flea-market/internal/test/fixtures"
+	"flea-market/models"
+	"flea-market/repositories"
+	"flea-market/utils"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const thirdUserEmail = "test3@test.com"
+
+func setupOfferTest() *gin.Engine {
+	db := testDB
+	setupItemTestData(db)
+	db.Create(&models.User{Email: thirdUserEmail, Password: "testpass"})
+	return app.NewRouter(db)
+}
+
+func tokenFor(t *testing.T, userId uint, email string) string {
+	t.Helper()
+	token, err := app.NewTokenIssuer().Issue(userId, email)
+	assert.NoError(t, err)
+	return token
+}
+
+func doJSON(router *gin.Engine, method string, path string, token string, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+token)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decodeData decodes the {data, meta} envelope.
+func decodeData[T any](t *testing.T, w *httptest.ResponseRecorder) T {
+	t.Helper()
+	var res struct {
+		Data T        `json:"data"`
+		Meta dto.Meta `json:"meta"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &res); err != nil {
+		t.Fatalf("json.Unmarshal failed %v: %s", err, w.Body.String())
+	}
+	return res.Data
+}
+
+func decodeOffer(t *testing.T, w *httptest.ResponseRecorder) dto.OfferResponse {
+	t.Helper()
+	return decodeData[dto.OfferResponse](t, w)
+}
+
+func errorCode(w *httptest.ResponseRecorder) string {
+	var res map[string]string
+	json.Unmarshal(w.Body.Bytes(), &res)
+	return res["error"]
+}
+
+func TestOffer_CounterAcceptAndPurchase(t *testing.T) {
+	router := setupOfferTest()
+	seller := tokenFor(t, 1, fixtures.UserData[0].Email)
+	buyer := tokenFor(t, 2, fixtures.UserData[1].Email)
+	other := tokenFor(t, 3, thirdUserEmail)
+
+	// buyer offers 80 for item 1(price 100)
+	w := doJSON(router, "POST", "/v2/items/1/offers", buyer, `{"price":80}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	offer := decodeOffer(t, w)
+	assert.Equal(t, string(models.OfferPending), offer.Status)
+	assert.Equal(t, uint(1), offer.SellerID)
+
+	// the buyer can't accept their own offer
+	w = doJSON(router, "POST", fmt.Sprintf("/v2/offers/%d/accept", offer.ID), buyer, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	// seller counters with 90
+	w = doJSON(router, "POST", fmt.Sprintf("/v2/offers/%d/counter", offer.ID), seller, `{"price":90}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	counter := decodeOffer(t, w)
+	assert.Equal(t, uint(1), counter.ProposerID)
+	assert.Equal(t, offer.ID, *counter.ParentID)
+
+	// countered offer can't be accepted anymore
+	w = doJSON(router, "POST", fmt.Sprintf("/v2/offers/%d/accept", offer.ID), seller, "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, string(utils.OfferInvalidTransition), errorCode(w))
+
+	// buyer accepts the counter-offer and the item is reserved
+	w = doJSON(router, "POST", fmt.Sprintf("/v2/offers/%d/accept", counter.ID), buyer, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	accepted := decodeOffer(t, w)
+	assert.Equal(t, string(models.OfferAccepted), accepted.Status)
+	assert.NotNil(t, accepted.ReservedUntil)
+
+	// another user can't purchase the reserved item
+	w = doJSON(router, "POST", "/v2/items/1/purchase", other, "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, string(utils.ItemReserved), errorCode(w))
+
+	// the buyer purchases at the agreed price
+	w = doJSON(router, "POST", "/v2/items/1/purchase", buyer, "")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	purchase := decodeData[dto.PurchaseResponse](t, w)
+	assert.Equal(t, uint(90), purchase.Price)
+	assert.Equal(t, counter.ID, *purchase.OfferID)
+
+	w = doJSON(router, "POST", "/v2/items/1/purchase", other, "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, string(utils.ItemSoldOut), errorCode(w))
+}
+
+func TestOffer_DeclineAndWithdraw(t *testing.T) {
+	router := setupOfferTest()
+	seller := tokenFor(t, 1, fixtures.UserData[0].Email)
+	buyer := tokenFor(t, 2, fixtures.UserData[1].Email)
+
+	offer := decodeOffer(t, doJSON(router, "POST", "/v2/items/1/offers", buyer, `{"price":50}`))
+
+	// only the proposer can withdraw
+	w := doJSON(router, "POST", fmt.Sprintf("/v2/offers/%d/withdraw", offer.ID), seller, "")
+	assert.Equal(t, http.StatusForbidden, w.Code)
+
+	w = doJSON(router, "POST", fmt.Sprintf("/v2/offers/%d/decline", offer.ID), seller, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, string(models.OfferDeclined), decodeOffer(t, w).Status)
+
+	w = doJSON(router, "POST", fmt.Sprintf("/v2/offers/%d/withdraw", offer.ID), buyer, "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, string(utils.OfferInvalidTransition), errorCode(w))
+}
+
+func TestOffer_Expiry(t *testing.T) {
+	router := setupOfferTest()
+	seller := tokenFor(t, 1, fixtures.UserData[0].Email)
+	buyer := tokenFor(t, 2, fixtures.UserData[1].Email)
+
+	offer := decodeOffer(t, doJSON(router, "POST", "/v2/items/1/offers", buyer, `{"price":50}`))
+	testDB.Model(&models.Offer{}).Where("id = ?", offer.ID).Update("expires_at", time.Now().Add(-time.Minute))
+
+	// expired by time even before the sweeper runs
+	w := doJSON(router, "POST", fmt.Sprintf("/v2/offers/%d/accept", offer.ID), seller, "")
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	count, err := repositories.NewOfferRepository(testDB).ExpirePending(context.Background(), time.Now())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+
+	w = doJSON(router, "GET", "/v2/items/1/offers", buyer, "")
+	offers := decodeData[[]dto.OfferResponse](t, w)
+	assert.Equal(t, string(models.OfferExpired), offers[0].Status)
+}
+
+func TestOffer_Invalid(t *testing.T) {
+	router := setupOfferTest()
+	seller := tokenFor(t, 1, fixtures.UserData[0].Email)
+	other := tokenFor(t, 3, thirdUserEmail)
+	buyer := tokenFor(t, 2, fixtures.UserData[1].Email)
+
+	cases := []struct {
+		name       string
+		token      string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{name: "own item", token: seller, path: "/v2/items/1/offers", body: `{"price":50}`, wantStatus: http.StatusBadRequest},
+		{name: "own sold out item", token: seller, path: "/v2/items/2/offers", body: `{"price":50}`, wantStatus: http.StatusBadRequest},
+		{name: "price is 0", token: buyer, path: "/v2/items/1/offers", body: `{"price":0}`, wantStatus: http.StatusBadRequest},
+		{name: "item not found", token: buyer, path: "/v2/items/999/offers", body: `{"price":50}`, wantStatus: http.StatusNotFound},
+		{name: "sold out item by other user", token: other, path: "/v2/items/2/offers", body: `{"price":50}`, wantStatus: http.StatusConflict},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := doJSON(router, "POST", tc.path, tc.token, tc.body)
+			assert.Equal(t, tc.wantStatus, w.Code)
+		})
+	}
+}
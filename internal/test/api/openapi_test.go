@@ -0,0 +1,65 @@
+package api_test
+
+import (
+	"encoding/json"
+	"flea-market/internal/app"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Fails when a route is added to the router without documenting it.
+func TestOpenAPI_CoversAllRoutes(t *testing.T) {
+	router := app.NewRouter(testDB)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/openapi.json", nil)
+	router.ServeHTTP(w, req)
+	if !assert.Equal(t, http.StatusOK, w.Code) {
+		return
+	}
+
+	var spec struct {
+		OpenAPI string                                `json:"openapi"`
+		Paths   map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &spec))
+	assert.Equal(t, "3.1.0", spec.OpenAPI)
+
+	for _, route := range router.Routes() {
+		path := route.Path
+		for _, segment := range strings.Split(route.Path, "/") {
+			if name, ok := strings.CutPrefix(segment, ":"); ok {
+				path = strings.Replace(path, segment, "{"+name+"}", 1)
+			}
+		}
+		assert.Contains(t, spec.Paths[path], strings.ToLower(route.Method), "%s %s isn't documented", route.Method, route.Path)
+	}
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/docs", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), "/openapi.json")
+	assert.NotContains(t, w.Body.String(), "https://")
+
+	// Swagger UI is bundled, so /docs works without a CDN
+	for path, contentType := range map[string]string{
+		"/docs/assets/swagger-ui-bundle.js": "text/javascript",
+		"/docs/assets/swagger-ui.css":       "text/css",
+	} {
+		w = httptest.NewRecorder()
+		req, _ = http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Contains(t, w.Header().Get("Content-Type"), contentType, path)
+		assert.NotEmpty(t, w.Body.Bytes(), path)
+	}
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/docs/assets/README.md", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
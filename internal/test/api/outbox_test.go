@@ -0,0 +1,58 @@
+package api_test
+
+import (
+	"context"
+	"flea-market/internal/outbox"
+	"flea-market/internal/test/fixtures"
+	"flea-market/models"
+	"flea-market/repositories"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutbox_ItemEvents(t *testing.T) {
+	router := setupItemTest()
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+
+	w := doJSON(router, "POST", "/v2/items", token, `{"name":"lamp","price":100}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	itemId := decodeData[struct {
+		ID uint `json:"id"`
+	}](t, w).ID
+	path := fmt.Sprintf("/v2/items/%d", itemId)
+	w = doJSON(router, "PUT", path, token, `{"price":200}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(router, "DELETE", path, token, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	// a failed mutation writes no event
+	w = doJSON(router, "DELETE", path, token, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	publisher := &outbox.MemoryPublisher{}
+	relay := outbox.NewRelay(repositories.NewOutboxRepository(testDB), publisher, 10*time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		relay.Run(ctx)
+		close(done)
+	}()
+	assert.Eventually(t, func() bool { return len(publisher.Events()) == 3 }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+	<-done
+
+	var types []string
+	for _, event := range publisher.Events() {
+		assert.Equal(t, models.AggregateItem, event.AggregateType)
+		assert.Equal(t, itemId, event.AggregateID)
+		types = append(types, event.Type)
+	}
+	assert.Equal(t, []string{models.EventItemCreated, models.EventItemUpdated, models.EventItemDeleted}, types)
+
+	var unpublished int64
+	testDB.Model(&models.OutboxEvent{}).Where("published_at IS NULL").Count(&unpublished)
+	assert.Equal(t, int64(0), unpublished)
+}
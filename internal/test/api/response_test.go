@@ -0,0 +1,90 @@
+package api_test
+
+import (
+	"encoding/json"
+	"flea-market/dto"
+	"flea-market/internal/app"
+	"flea-market/internal/test/fixtures"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// GORM internals must not appear in v2 responses.
+func TestResponse_Envelope(t *testing.T) {
+	db := testDB
+	setupItemTestData(db)
+	router := app.NewRouter(db)
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+
+	w := doJSON(router, "GET", "/v2/items/1", token, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var item struct {
+		Data map[string]any `json:"data"`
+		Meta map[string]any `json:"meta"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &item))
+	keys := []string{}
+	for key := range item.Data {
+		keys = append(keys, key)
+	}
+	assert.ElementsMatch(t, []string{
+		"id", "name", "price", "currency", "formattedPrice", "description", "soldOut", "userId", "categoryId", "condition", "tags", "createdAt", "updatedAt", "moderationStatus",
+	}, keys)
+	assert.NotEmpty(t, item.Meta["requestId"])
+	assert.NotContains(t, item.Meta, "count")
+
+	w = doJSON(router, "GET", "/v2/items", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	var list struct {
+		Data []map[string]any `json:"data"`
+		Meta map[string]any   `json:"meta"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, float64(len(list.Data)), list.Meta["count"])
+	assert.NotContains(t, list.Data[0], "DeletedAt")
+}
+
+// v1 and the legacy aliases keep responding with the models in {"data": ...}, as before the envelope.
+func TestResponse_V1Shape(t *testing.T) {
+	db := testDB
+	setupItemTestData(db)
+	router := app.NewRouter(db)
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+
+	for _, prefix := range []string{"/v1", ""} {
+		t.Run(fmt.Sprintf("prefix %q", prefix), func(t *testing.T) {
+			w := doJSON(router, "GET", prefix+"/items/1", token, "")
+			assert.Equal(t, http.StatusOK, w.Code)
+			var item map[string]map[string]any
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &item))
+			assert.Len(t, item, 1)
+			assert.Equal(t, float64(1), item["data"]["ID"])
+			assert.Equal(t, fixtures.ItemData[0].Name, item["data"]["Name"])
+			assert.Contains(t, item["data"], "DeletedAt")
+			// the columns added after v2 are only in v2
+			for _, column := range []string{"Currency", "ModerationStatus", "ModerationReason", "DisplayPrice"} {
+				assert.NotContains(t, item["data"], column)
+			}
+
+			w = doJSON(router, "GET", prefix+"/items", "", "")
+			assert.Equal(t, http.StatusOK, w.Code)
+			var list map[string][]map[string]any
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+			assert.Len(t, list, 1)
+			assert.NotEmpty(t, list["data"])
+			assert.Contains(t, list["data"][0], "ID")
+
+			body, _ := json.Marshal(dto.LoginInput{Email: fixtures.UserData[0].Email, Password: fixtures.UserData[0].Password})
+			w = doJSON(router, "POST", prefix+"/auth/login", "", string(body))
+			assert.Equal(t, http.StatusOK, w.Code)
+			var login map[string]any
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &login))
+			assert.Len(t, login, 1)
+			assert.NotEmpty(t, login["token"])
+		})
+	}
+}
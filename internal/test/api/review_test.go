@@ -0,0 +1,97 @@
+package api_test
+
+import (
+	"flea-market/dto"
+	"flea-market/internal/test/fixtures"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// setupReviewTest returns the router and the id of a purchase of item 1 (seller 1, buyer 2).
+func setupReviewTest(t *testing.T) (*gin.Engine, uint) {
+	t.Helper()
+	router := setupOfferTest()
+	buyer := tokenFor(t, 2, fixtures.UserData[1].Email)
+
+	w := doJSON(router, "POST", "/v2/items/1/purchase", buyer, "")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	return router, decodeData[dto.PurchaseResponse](t, w).ID
+}
+
+func findProfile(t *testing.T, router *gin.Engine, userId uint) dto.UserPublicResponse {
+	t.Helper()
+	w := doJSON(router, "GET", fmt.Sprintf("/v2/users/%d", userId), "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	return decodeData[dto.UserPublicResponse](t, w)
+}
+
+func TestReview_BothSidesReview(t *testing.T) {
+	router, purchaseId := setupReviewTest(t)
+	seller := tokenFor(t, 1, fixtures.UserData[0].Email)
+	buyer := tokenFor(t, 2, fixtures.UserData[1].Email)
+	other := tokenFor(t, 3, thirdUserEmail)
+	path := fmt.Sprintf("/v2/purchases/%d/reviews", purchaseId)
+
+	w := doJSON(router, "POST", path, buyer, `{"rating":4,"comment":"good seller"}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	created := decodeData[dto.ReviewResponse](t, w)
+	assert.Equal(t, uint(1), created.RevieweeID)
+	assert.Equal(t, string(models.ReviewerBuyer), created.ReviewerRole)
+
+	w = doJSON(router, "POST", path, seller, `{"rating":5}`)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// one review per side
+	w = doJSON(router, "POST", path, buyer, `{"rating":1}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, string(utils.DuplicateKeyError), errorCode(w))
+
+	w = doJSON(router, "POST", path, other, `{"rating":1}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doJSON(router, "POST", path, buyer, `{"rating":6}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	profile := findProfile(t, router, 1)
+	assert.Equal(t, uint(1), profile.ReviewCount)
+	assert.Equal(t, 4.0, profile.AverageRating)
+
+	w = doJSON(router, "GET", "/v2/users/1/reviews", "", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	reviews := decodeData[[]dto.ReviewResponse](t, w)
+	assert.Len(t, reviews, 1)
+	assert.Equal(t, "good seller", reviews[0].Comment)
+}
+
+func TestReview_Update(t *testing.T) {
+	router, purchaseId := setupReviewTest(t)
+	seller := tokenFor(t, 1, fixtures.UserData[0].Email)
+	buyer := tokenFor(t, 2, fixtures.UserData[1].Email)
+
+	w := doJSON(router, "POST", fmt.Sprintf("/v2/purchases/%d/reviews", purchaseId), buyer, `{"rating":2}`)
+	created := decodeData[dto.ReviewResponse](t, w)
+	path := fmt.Sprintf("/v2/reviews/%d", created.ID)
+
+	// only the author can edit
+	w = doJSON(router, "PUT", path, seller, `{"rating":5}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	w = doJSON(router, "PUT", path, buyer, `{"rating":5}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	profile := findProfile(t, router, 1)
+	assert.Equal(t, uint(1), profile.ReviewCount)
+	assert.Equal(t, 5.0, profile.AverageRating)
+
+	testDB.Model(&models.Review{}).Where("id = ?", created.ID).Update("created_at", time.Now().Add(-8*24*time.Hour))
+	w = doJSON(router, "PUT", path, buyer, `{"rating":1}`)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.Equal(t, string(utils.ReviewNotEditable), errorCode(w))
+}
@@ -0,0 +1,111 @@
+package api_test
+
+import (
+	"flea-market/dto"
+	"flea-market/internal/app"
+	"flea-market/internal/test/fixtures"
+	test_utils "flea-market/internal/test/utils"
+	"flea-market/models"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupSearchTest() *gin.Engine {
+	db := testDB
+	test_utils.DeleteTables(db)
+
+	fixtures.SeedUsers(db)
+
+	items := []models.Item{
+		{Name: "Vintage camera", Price: 5000, Description: "film camera from 1970s", UserID: 1},
+		{Name: "Camera strap", Price: 500, Description: "leather", UserID: 1},
+		{Name: "Novel", Price: 300, Description: "vintage paperback", UserID: 2},
+	}
+	for _, item := range items {
+		db.Create(&item)
+	}
+	return app.NewRouter(db)
+}
+
+func TestSearch(t *testing.T) {
+	router := setupSearchTest()
+
+	cases := []struct {
+		name      string
+		query     string
+		wantNames []string
+	}{
+		// name is weighted higher than description
+		{name: "ranked by weight", query: "vintage", wantNames: []string{"Vintage camera", "Novel"}},
+		{name: "prefix match", query: "cam", wantNames: []string{"Vintage camera", "Camera strap"}},
+		{name: "all words must match", query: "vintage camera", wantNames: []string{"Vintage camera"}},
+		{name: "operators are ignored", query: "vintage | !camera", wantNames: []string{"Vintage camera"}},
+		{name: "no match", query: "bicycle", wantNames: []string{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/v2/items/search?q="+tc.query, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			names := []string{}
+			for _, r := range decodeData[[]dto.ItemSearchResultResponse](t, w) {
+				names = append(names, r.Name)
+			}
+			if tc.name == "prefix match" {
+				assert.ElementsMatch(t, tc.wantNames, names)
+			} else {
+				assert.Equal(t, tc.wantNames, names)
+			}
+		})
+	}
+}
+
+func TestSearch_Headline(t *testing.T) {
+	router := setupSearchTest()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v2/items/search?q=film", nil)
+	router.ServeHTTP(w, req)
+
+	results := decodeData[[]dto.ItemSearchResultResponse](t, w)
+	assert.Len(t, results, 1)
+	assert.Contains(t, results[0].Headline, "<mark>film</mark>")
+	assert.Greater(t, results[0].Rank, float64(0))
+}
+
+// The headline is rendered as HTML, so the user's text must be escaped.
+func TestSearch_HeadlineIsEscaped(t *testing.T) {
+	router := setupSearchTest()
+	testDB.Create(&models.Item{Name: "Tripod", Price: 800, Description: `<script>alert("tripod")</script>`, UserID: 2})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/v2/items/search?q=tripod", nil)
+	router.ServeHTTP(w, req)
+
+	results := decodeData[[]dto.ItemSearchResultResponse](t, w)
+	assert.Len(t, results, 1)
+	assert.NotContains(t, results[0].Headline, "<script>")
+	assert.Contains(t, results[0].Headline, "<mark>Tripod</mark>")
+	assert.Contains(t, results[0].Headline, "&lt;script&gt;")
+}
+
+func TestSearch_InvalidQuery(t *testing.T) {
+	router := setupSearchTest()
+
+	for _, query := range []string{"", "?q=", "?q=%26%7C", "?q=a&limit=101"} {
+		t.Run(query, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", "/v2/items/search"+query, nil)
+			router.ServeHTTP(w, req)
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+		})
+	}
+}
@@ -3,6 +3,7 @@ package api_test
 import (
 	"flea-market/infra"
 	test_utils "flea-market/internal/test/utils"
+	"log"
 	"os"
 	"testing"
 
@@ -14,6 +15,10 @@ var testDB *gorm.DB
 func TestMain(m *testing.M) {
 	test_utils.ReadEnv()
 	testDB = infra.SetupDB()
+	// an in-memory SQLite DB is empty on every run
+	if err := infra.Migrate(testDB); err != nil {
+		log.Fatalf("Failed to migrate: %v", err)
+	}
 
 	code := m.Run()
 
@@ -0,0 +1,99 @@
+package api_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"flea-market/dto"
+	"flea-market/internal/test/fixtures"
+	"flea-market/utils"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func doUpload(router *gin.Engine, path string, token string, file string) *httptest.ResponseRecorder {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, _ := writer.CreateFormFile("file", "items.csv")
+	_, _ = part.Write([]byte(file))
+	_ = writer.Close()
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", path, &body)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestExportItems(t *testing.T) {
+	router := setupItemTest()
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+
+	w := doJSON(router, "GET", "/v1/me/items/export", token, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `attachment; filename="items.csv"`, w.Header().Get("Content-Disposition"))
+	records, err := csv.NewReader(w.Body).ReadAll()
+	assert.NoError(t, err)
+	// item 3 belongs to user 2
+	assert.Len(t, records, 3)
+	assert.Equal(t, dto.ItemExportColumns, records[0])
+	assert.Equal(t, []string{"1", "test1", "100"}, records[1][:3])
+
+	w = doJSON(router, "GET", "/v1/me/items/export?format=jsonl", token, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	var names []string
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var item dto.ItemResponse
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &item))
+		names = append(names, item.Name)
+	}
+	assert.Equal(t, []string{"test1", "test2"}, names)
+
+	w = doJSON(router, "GET", "/v1/me/items/export?format=xml", token, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestImportItems(t *testing.T) {
+	router := setupItemTest()
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+	invalid := "name,price,condition\nlamp,100,good\nx,abc,broken\n"
+
+	w := doUpload(router, "/v1/me/items/import?dryRun=true", token, invalid)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	report := decodeData[dto.ItemImportReport](t, w)
+	assert.True(t, report.DryRun)
+	assert.Equal(t, []dto.ItemImportError{
+		{Row: 3, Field: "price", Reason: "integer"},
+		{Row: 3, Field: "name", Reason: "min"},
+		{Row: 3, Field: "condition", Reason: "oneof"},
+	}, report.Errors)
+
+	w = doUpload(router, "/v1/me/items/import?report=csv", token, invalid)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Equal(t, "row,field,reason\n3,price,integer\n3,name,min\n3,condition,oneof\n", w.Body.String())
+	assert.Equal(t, int64(3), countItems(t))
+
+	// an exported file can be imported again
+	exported := doJSON(router, "GET", "/v1/me/items/export", token, "").Body.String()
+	w = doUpload(router, "/v1/me/items/import?dryRun=true", token, exported)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, int64(3), countItems(t))
+
+	w = doUpload(router, "/v1/me/items/import", token, "name,price,tags\nlamp,100,light|desk\ndesk,200,\n")
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, 2, decodeData[dto.ItemImportReport](t, w).Imported)
+	assert.Equal(t, int64(5), countItems(t))
+
+	w = doJSON(router, "POST", "/v1/me/items/import", token, "")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Equal(t, string(utils.BadRequest), errorCode(w))
+}
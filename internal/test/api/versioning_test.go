@@ -0,0 +1,51 @@
+package api_test
+
+import (
+	"flea-market/internal/app"
+	"flea-market/internal/test/fixtures"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersioning_LegacyAliases(t *testing.T) {
+	db := testDB
+	setupItemTestData(db)
+	router := app.NewRouter(db)
+
+	cases := []struct {
+		name           string
+		path           string
+		wantDeprecated bool
+	}{
+		{name: "v1", path: "/v1/items", wantDeprecated: false},
+		{name: "v2 falls through to v1", path: "/v2/items", wantDeprecated: false},
+		{name: "legacy alias", path: "/items", wantDeprecated: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req, _ := http.NewRequest("GET", tc.path, nil)
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			if tc.wantDeprecated {
+				assert.Regexp(t, `^@\d+$`, w.Header().Get("Deprecation"))
+				assert.NotEmpty(t, w.Header().Get("Sunset"))
+				assert.Equal(t, `</v1/items>; rel="successor-version"`, w.Header().Get("Link"))
+			} else {
+				assert.Empty(t, w.Header().Get("Deprecation"))
+				assert.Empty(t, w.Header().Get("Sunset"))
+			}
+		})
+	}
+
+	// auth is applied to every version
+	token := tokenFor(t, 1, fixtures.UserData[0].Email)
+	w := doJSON(router, "GET", "/v1/items/1", "", "")
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	w = doJSON(router, "GET", "/v1/items/1", token, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+}
@@ -0,0 +1,113 @@
+package api_test
+
+import (
+	"flea-market/dto"
+	"flea-market/infra"
+	"flea-market/internal/test/fixtures"
+	"flea-market/models"
+	"flea-market/services"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhooks(t *testing.T) {
+	// the receiver runs on the loopback
+	t.Setenv("WEBHOOK_ALLOW_PRIVATE_NETWORKS", "true")
+	router := setupItemTest()
+	seller := tokenFor(t, 1, fixtures.UserData[0].Email)
+	buyer := tokenFor(t, 2, fixtures.UserData[1].Email)
+
+	var received http.Header
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	w := doJSON(router, "POST", "/me/webhooks", seller, `{"url":"ftp://example.com","events":["item_sold"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	w = doJSON(router, "POST", "/me/webhooks", seller, `{"url":"https://example.com","events":["unknown"]}`)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = doJSON(router, "POST", "/me/webhooks", seller, fmt.Sprintf(`{"url":%q,"events":["offer_received","item_sold"]}`, server.URL))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	created := decodeData[dto.WebhookResponse](t, w)
+	assert.NotEmpty(t, created.Secret)
+	path := fmt.Sprintf("/me/webhooks/%d", created.ID)
+
+	t.Run("secret is shown only on creation", func(t *testing.T) {
+		w := doJSON(router, "GET", "/me/webhooks", seller, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		webhooks := decodeData[[]dto.WebhookResponse](t, w)
+		assert.Len(t, webhooks, 1)
+		assert.Empty(t, webhooks[0].Secret)
+	})
+
+	t.Run("test delivery is signed and logged", func(t *testing.T) {
+		w := doJSON(router, "POST", path+"/test", seller, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		delivery := decodeData[dto.WebhookDeliveryResponse](t, w)
+		assert.True(t, delivery.Succeeded)
+		assert.Equal(t, http.StatusNoContent, delivery.StatusCode)
+		timestamp := received.Get(services.WebhookTimestampHeader)
+		assert.Equal(t, services.SignWebhook(created.Secret, timestamp, body), received.Get(services.WebhookSignatureHeader))
+
+		w = doJSON(router, "GET", path+"/deliveries", seller, "")
+		assert.Equal(t, http.StatusOK, w.Code)
+		deliveries := decodeData[[]dto.WebhookDeliveryResponse](t, w)
+		assert.Len(t, deliveries, 1)
+		assert.Equal(t, string(models.WebhookTestEvent), deliveries[0].Event)
+	})
+
+	t.Run("offer queues a delivery", func(t *testing.T) {
+		w := doJSON(router, "POST", "/v2/items/1/offers", buyer, `{"price":80}`)
+		assert.Equal(t, http.StatusCreated, w.Code)
+
+		var count int64
+		testDB.Model(&models.Job{}).Where("kind = ?", services.DeliverWebhookJob.Kind).Count(&count)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("another user's webhook is not found", func(t *testing.T) {
+		w := doJSON(router, "POST", path+"/test", buyer, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		w = doJSON(router, "DELETE", path, buyer, "")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	w = doJSON(router, "DELETE", path, seller, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = doJSON(router, "GET", path+"/deliveries", seller, "")
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// The URLs are given by users, so the server must not call itself or the internal network.
+func TestWebhooks_PrivateNetworkRefused(t *testing.T) {
+	router := setupItemTest()
+	seller := tokenFor(t, 1, fixtures.UserData[0].Email)
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		_, _ = w.Write([]byte("internal secret"))
+	}))
+	defer server.Close()
+
+	w := doJSON(router, "POST", "/me/webhooks", seller, fmt.Sprintf(`{"url":%q,"events":["item_sold"]}`, server.URL))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	created := decodeData[dto.WebhookResponse](t, w)
+
+	w = doJSON(router, "POST", fmt.Sprintf("/me/webhooks/%d/test", created.ID), seller, "")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "internal secret")
+	delivery := decodeData[dto.WebhookDeliveryResponse](t, w)
+	assert.False(t, delivery.Succeeded)
+	assert.Contains(t, delivery.Error, infra.ErrNonPublicAddress.Error())
+	assert.False(t, called)
+}
@@ -0,0 +1,87 @@
+// Package fixtures loads the users and items shared by the API tests.
+// It's apart from test_utils, which the service tests import, because loading fixtures depends on services.
+package fixtures
+
+import (
+	"context"
+	"embed"
+	"flea-market/internal/seed"
+	"flea-market/models"
+	"log"
+	"path/filepath"
+
+	"gorm.io/gorm"
+)
+
+//go:embed *.yaml
+var fixtureFiles embed.FS
+
+var (
+	userFixtures = readFixtures("users.yaml")
+	itemFixtures = readFixtures("items.yaml")
+)
+
+// UserData and ItemData are the rows of the fixtures, to be compared with responses.
+// Passwords are plaintext. The owners of ItemData are set from the refs on SeedUsersAndItems.
+var (
+	UserData = toUsers(userFixtures)
+	ItemData = toItems(itemFixtures)
+)
+
+// SeedUsers loads the users of users.yaml.
+func SeedUsers(db *gorm.DB) {
+	load(db, userFixtures)
+}
+
+// SeedUsersAndItems loads users.yaml and items.yaml.
+func SeedUsersAndItems(db *gorm.DB) {
+	var fixtures seed.Fixtures
+	fixtures.Append(userFixtures)
+	fixtures.Append(itemFixtures)
+	refs := load(db, fixtures)
+	for i, f := range itemFixtures.Items {
+		ItemData[i].UserID = refs.Users[f.User]
+	}
+}
+
+func load(db *gorm.DB, fixtures seed.Fixtures) *seed.Refs {
+	refs, err := seed.NewSeeder(db).Load(context.Background(), fixtures)
+	if err != nil {
+		log.Fatalf("Failed to load fixtures: %v\n", err)
+	}
+	return refs
+}
+
+func readFixtures(path string) seed.Fixtures {
+	data, err := fixtureFiles.ReadFile(path)
+	if err != nil {
+		log.Fatalf("Failed to read %s: %v\n", path, err)
+	}
+	fixtures, err := seed.Parse(data, filepath.Ext(path))
+	if err != nil {
+		log.Fatalf("Failed to parse %s: %v\n", path, err)
+	}
+	return *fixtures
+}
+
+func toUsers(fixtures seed.Fixtures) []models.User {
+	users := make([]models.User, 0, len(fixtures.Users))
+	for _, f := range fixtures.Users {
+		users = append(users, models.User{Email: f.Email, Password: f.Password})
+	}
+	return users
+}
+
+func toItems(fixtures seed.Fixtures) []models.Item {
+	items := make([]models.Item, 0, len(fixtures.Items))
+	for _, f := range fixtures.Items {
+		items = append(items, models.Item{
+			Name:        f.Name,
+			Price:       f.Price,
+			Description: f.Description,
+			SoldOut:     f.SoldOut,
+			Condition:   models.ItemCondition(f.Condition),
+		})
+	}
+	return items
+}
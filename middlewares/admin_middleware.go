@@ -0,0 +1,23 @@
+package middlewares
+
+import (
+	"errors"
+	"flea-market/models"
+	"flea-market/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminMiddleware lets only admins through. It must come after AuthMiddleware.
+func AdminMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		value, _ := ctx.Get("user")
+		user, ok := value.(*models.User)
+		if !ok || !user.Admin {
+			_ = ctx.Error(utils.NewForbiddenError("admin only", errors.New("not an admin")))
+			ctx.Abort()
+			return
+		}
+		ctx.Next()
+	}
+}
@@ -2,6 +2,7 @@ package middlewares
 
 import (
 	"flea-market/controllers"
+	"flea-market/utils"
 	"net/http"
 	"strings"
 
@@ -32,6 +33,8 @@ func AuthMiddleware(authService controllers.IAuthService) gin.HandlerFunc {
 		}
 
 		ctx.Set("user", user)
+		// copied to the context of services and repositories by utils.GinToGoContext
+		utils.SetGinContext(ctx, utils.ContextUser, user)
 
 		ctx.Next()
 	}
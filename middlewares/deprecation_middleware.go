@@ -0,0 +1,29 @@
+package middlewares
+
+import (
+	"flea-market/utils"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DeprecationMiddleware marks the legacy unversioned routes, which are aliases of successorPrefix + path.
+// Deprecation follows RFC 9745 and Sunset follows RFC 8594.
+func DeprecationMiddleware(successorPrefix string, deprecatedAt time.Time, sunset time.Time) gin.HandlerFunc {
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+
+	return func(ctx *gin.Context) {
+		successor := successorPrefix + ctx.Request.URL.Path
+		ctx.Header("Deprecation", deprecation)
+		ctx.Header("Sunset", sunsetDate)
+		ctx.Header("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+
+		ip, reqID, methodPath := utils.GetGinLogContext(ctx)
+		utils.Logger(utils.DeprecatedRouteCalled, methodPath, reqID, ip, successor, sunsetDate)
+
+		ctx.Next()
+	}
+}
@@ -1,26 +1,36 @@
 package middlewares
 
 import (
+	"flea-market/internal/i18n"
 	// カスタムエラー型のパッケージ
 	"flea-market/utils"
 
 	"github.com/gin-gonic/gin"
 )
 
+// APIErrorHandler responds the last error with its code and the message in the locale of Accept-Language.
+// Validation errors have the message of each failed field in details.
 func APIErrorHandler() gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		ctx.Next()
 
 		if len(ctx.Errors) > 0 {
 			err := ctx.Errors.Last().Err
+			locale := i18n.Match(ctx.GetHeader("Accept-Language"))
+			ctx.Header("Content-Language", string(locale))
+			ctx.Writer.Header().Add("Vary", "Accept-Language")
 
 			if apiErr, ok := err.(*utils.APIError); ok {
 				utils.Logger(apiErr.MessageCode, "", "", "", err.Error())
-				ctx.JSON(apiErr.StatusCode, gin.H{"error": apiErr.MessageCode})
+				body := gin.H{"error": apiErr.MessageCode, "message": utils.APIMessage(locale, apiErr.MessageCode)}
+				if details := i18n.TranslateValidation(apiErr.Err, locale); details != nil {
+					body["details"] = details
+				}
+				ctx.JSON(apiErr.StatusCode, body)
 				return
 			} else {
 				utils.Logger(utils.UnknownError, "", "", "", err.Error())
-				ctx.JSON(500, gin.H{"error": "Internal server error"})
+				ctx.JSON(500, gin.H{"error": "Internal server error", "message": utils.APIMessage(locale, utils.UnknownError)})
 				return
 			}
 		}
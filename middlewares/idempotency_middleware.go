@@ -0,0 +1,149 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// set to the replayed responses
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+	// the body is read into memory to take the fingerprint
+	maxIdempotentBodySize = 2 << 20
+)
+
+type IIdempotencyService interface {
+	Begin(ctx context.Context, userId uint, key string, fingerprint string) (*models.IdempotencyKey, bool, error)
+	Complete(ctx context.Context, id uint, status int, headers http.Header, body []byte) error
+	Release(ctx context.Context, id uint) error
+}
+
+// IdempotencyMiddleware replays the stored response to a POST request retried with the same Idempotency-Key header.
+// Keys are scoped per user, so it has to be placed after AuthMiddleware.
+// Errors are responded by APIErrorHandler after this middleware returns, so they aren't stored and the key is released for a retry.
+func IdempotencyMiddleware(service IIdempotencyService) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(IdempotencyKeyHeader)
+		if ctx.Request.Method != http.MethodPost || key == "" {
+			ctx.Next()
+			return
+		}
+		user, _ := ctx.Get("user")
+		usr, ok := user.(*models.User)
+		if !ok {
+			ctx.Next()
+			return
+		}
+		userId := usr.ID
+
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(ctx, utils.NewBadRequestError(fmt.Sprintf("%s is longer than %d", IdempotencyKeyHeader, maxIdempotencyKeyLength), nil))
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, maxIdempotentBodySize+1))
+		if err != nil {
+			abortWithError(ctx, utils.NewBadRequestError("can't read body", err))
+			return
+		}
+		if len(body) > maxIdempotentBodySize {
+			abortWithError(ctx, utils.NewBadRequestError(fmt.Sprintf("body is too large for %s", IdempotencyKeyHeader), nil))
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		reqCtx := utils.GinToGoContext(ctx)
+		record, replay, err := service.Begin(reqCtx, userId, key, fingerprint(ctx.Request, body))
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+		ip, reqID, methodPath := utils.GetGinLogContext(ctx)
+		if replay {
+			utils.Logger(utils.IdempotentReplay, methodPath, reqID, ip, key)
+			for name, values := range record.ResponseHeaders {
+				ctx.Writer.Header()[name] = values
+			}
+			ctx.Header(IdempotentReplayedHeader, "true")
+			ctx.Writer.WriteHeader(record.ResponseStatus)
+			_, _ = ctx.Writer.Write(record.ResponseBody)
+			ctx.Abort()
+			return
+		}
+
+		recorder := &bodyRecorder{ResponseWriter: ctx.Writer}
+		ctx.Writer = recorder
+		// the response must be stored even if the client has gone
+		storeCtx := context.WithoutCancel(reqCtx)
+		completed := false
+		// runs on panic too
+		defer func() {
+			if completed {
+				return
+			}
+			if err := service.Release(storeCtx, record.ID); err != nil {
+				logError(methodPath, reqID, ip, err)
+			}
+		}()
+
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		if len(ctx.Errors) > 0 || !ctx.Writer.Written() || status >= http.StatusInternalServerError {
+			return
+		}
+		if err := service.Complete(storeCtx, record.ID, status, ctx.Writer.Header().Clone(), recorder.body.Bytes()); err != nil {
+			logError(methodPath, reqID, ip, err)
+			return
+		}
+		completed = true
+	}
+}
+
+// fingerprint identifies the request sent with a key. The query is included, because it can change the behavior like ?atomic=true.
+func fingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.RequestURI() + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func abortWithError(ctx *gin.Context, err error) {
+	_ = ctx.Error(err)
+	ctx.Abort()
+}
+
+func logError(methodPath string, reqID string, ip string, err error) {
+	if apiErr, ok := err.(*utils.APIError); ok {
+		utils.Logger(apiErr.MessageCode, methodPath, reqID, ip, err.Error())
+		return
+	}
+	utils.Logger(utils.UnknownError, methodPath, reqID, ip, err.Error())
+}
+
+// bodyRecorder keeps a copy of the response body.
+type bodyRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bodyRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *bodyRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
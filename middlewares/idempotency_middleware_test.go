@@ -0,0 +1,131 @@
+package middlewares
+
+import (
+	"context"
+	"flea-market/internal/mocks"
+	"flea-market/models"
+	"flea-market/services"
+	"flea-market/utils"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// newMemoryIdempotencyRepository stores keys in a map, ignoring expiry.
+func newMemoryIdempotencyRepository() (*mocks.MockIdempotencyRepository, map[string]*models.IdempotencyKey) {
+	keys := map[string]*models.IdempotencyKey{}
+	byId := func(id uint) *models.IdempotencyKey {
+		for _, key := range keys {
+			if key.ID == id {
+				return key
+			}
+		}
+		return nil
+	}
+	return &mocks.MockIdempotencyRepository{
+		ReserveFunc: func(ctx context.Context, key models.IdempotencyKey, now time.Time, staleBefore time.Time) (*models.IdempotencyKey, bool, error) {
+			if stored, ok := keys[key.Key]; ok {
+				return stored, false, nil
+			}
+			key.ID = uint(len(keys) + 1)
+			keys[key.Key] = &key
+			return &key, true, nil
+		},
+		CompleteFunc: func(ctx context.Context, id uint, status int, headers http.Header, body []byte) error {
+			key := byId(id)
+			key.ResponseStatus, key.ResponseHeaders, key.ResponseBody = status, headers, body
+			return nil
+		},
+		DeleteFunc: func(ctx context.Context, id uint) error {
+			delete(keys, byId(id).Key)
+			return nil
+		},
+	}, keys
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	repo, keys := newMemoryIdempotencyRepository()
+	calls := 0
+	router := gin.New()
+	router.Use(LoggerMiddleware(), APIErrorHandler())
+	router.Use(func(ctx *gin.Context) {
+		ctx.Set("user", &models.User{Model: gorm.Model{ID: 1}})
+	}, IdempotencyMiddleware(services.NewIdempotencyService(repo)))
+	router.POST("/items", func(ctx *gin.Context) {
+		calls++
+		if ctx.Query("fail") != "" {
+			_ = ctx.Error(utils.NewBadRequestError("fail", nil))
+			return
+		}
+		ctx.Header("Location", "/items/1")
+		ctx.String(http.StatusCreated, "created %d", calls)
+	})
+
+	post := func(path string, key string, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("POST", path, strings.NewReader(body))
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("replays the stored response", func(t *testing.T) {
+		first := post("/items", "a", `{"name":"lamp"}`)
+		retry := post("/items", "a", `{"name":"lamp"}`)
+
+		assert.Equal(t, 1, calls)
+		assert.Equal(t, http.StatusCreated, retry.Code)
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, "/items/1", retry.Header().Get("Location"))
+		assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+		assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+	})
+
+	t.Run("another body with the same key", func(t *testing.T) {
+		w := post("/items", "a", `{"name":"desk"}`)
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), string(utils.IdempotencyKeyReused))
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("in flight", func(t *testing.T) {
+		keys["b"] = &models.IdempotencyKey{ID: 100, Key: "b", Fingerprint: fingerprint(httptest.NewRequest("POST", "/items", nil), nil)}
+		w := post("/items", "b", "")
+
+		assert.Equal(t, http.StatusTooEarly, w.Code)
+		assert.Contains(t, w.Body.String(), string(utils.IdempotencyKeyInFlight))
+	})
+
+	t.Run("errors are not stored", func(t *testing.T) {
+		calls = 0
+		assert.Equal(t, http.StatusBadRequest, post("/items?fail=1", "c", "").Code)
+		assert.Equal(t, http.StatusBadRequest, post("/items?fail=1", "c", "").Code)
+
+		assert.Equal(t, 2, calls)
+		assert.NotContains(t, keys, "c")
+	})
+
+	t.Run("without key", func(t *testing.T) {
+		calls = 0
+		post("/items", "", "")
+		post("/items", "", "")
+
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("too long key", func(t *testing.T) {
+		w := post("/items", strings.Repeat("k", maxIdempotencyKeyLength+1), "")
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -1,6 +1,7 @@
 package middlewares
 
 import (
+	"flea-market/internal/i18n"
 	"flea-market/utils"
 	"fmt"
 	"runtime"
@@ -24,7 +25,7 @@ func CustomRecovery() gin.HandlerFunc {
 				utils.Logger(utils.PanicThrownError, methodPath, reqID, ip, errObj)
 				c.JSON(500, gin.H{
 					"code":    500,
-					"message": "Internal Server Error",
+					"message": utils.APIMessage(i18n.Match(c.GetHeader("Accept-Language")), utils.UnknownError),
 				})
 				c.Abort()
 			}
@@ -0,0 +1,12 @@
+package models
+
+import "gorm.io/gorm"
+
+// Category is a node of the category tree. Root categories have no ParentID.
+type Category struct {
+	gorm.Model
+	Name     string     `gorm:"not null"`
+	Slug     string     `gorm:"not null;uniqueIndex"`
+	ParentID *uint      `gorm:"index"`
+	Children []Category `gorm:"foreignKey:ParentID" json:",omitempty"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdempotencyKey is a POST request sent with an Idempotency-Key header and its response, which is replayed to the retries.
+// ResponseStatus is 0 while the first request is in flight.
+// Expired rows are replaced when the same key is used again.
+type IdempotencyKey struct {
+	ID     uint   `gorm:"primarykey"`
+	UserID uint   `gorm:"not null;uniqueIndex:idx_idempotency_keys_user_id_key"`
+	Key    string `gorm:"not null;size:255;uniqueIndex:idx_idempotency_keys_user_id_key"`
+	// sha256 of the method, the URI and the body
+	Fingerprint     string `gorm:"not null"`
+	ResponseStatus  int
+	ResponseHeaders http.Header `gorm:"type:text;serializer:json"`
+	ResponseBody    []byte
+	CreatedAt       time.Time
+	ExpiresAt       time.Time `gorm:"not null;index"`
+}
+
+func (k *IdempotencyKey) Completed() bool {
+	return k.ResponseStatus != 0
+}
@@ -1,12 +1,71 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"flea-market/internal/money"
+
+	"gorm.io/gorm"
+)
+
+type ItemCondition string
+
+const (
+	ConditionNew     ItemCondition = "new"
+	ConditionLikeNew ItemCondition = "like-new"
+	ConditionGood    ItemCondition = "good"
+	ConditionFair    ItemCondition = "fair"
+	ConditionPoor    ItemCondition = "poor"
+)
+
+// ModerationStatus of an item. Only approved items are listed and can be offered or purchased.
+type ModerationStatus string
+
+const (
+	ModerationPending  ModerationStatus = "pending"
+	ModerationApproved ModerationStatus = "approved"
+	ModerationRejected ModerationStatus = "rejected"
+)
 
 type Item struct {
 	gorm.Model
-	Name        string `gorm:"not null"`
-	Price       uint   `gorm:"not null"`
+	Name string `gorm:"not null"`
+	// in the minor unit of Currency, e.g. cents for USD
+	Price uint `gorm:"not null"`
+	// items created before multi-currency are in yen
+	Currency    money.Currency `gorm:"size:3;not null;default:JPY"`
 	Description string
-	SoldOut     bool `gorm:"not null;default:false"`
-	UserID      uint `gorm:"not null"`
+	SoldOut     bool      `gorm:"not null;default:false"`
+	UserID      uint      `gorm:"not null;index"`
+	CategoryID  *uint     `gorm:"index"`
+	Category    *Category `json:",omitempty"`
+	Condition   ItemCondition
+	Tags        []Tag `gorm:"many2many:item_tags"`
+	// items created before moderation are approved by the default
+	ModerationStatus ModerationStatus `gorm:"not null;default:approved;index"`
+	// why the item isn't approved, for the seller and admins
+	ModerationReason string
+	// Price converted into the currency asked by the client. It isn't stored
+	DisplayPrice *money.Money `gorm:"-"`
+}
+
+// Money is the price with its currency. An item without a currency is in money.DefaultCurrency.
+func (i Item) Money() money.Money {
+	if i.Currency == "" {
+		return money.New(uint64(i.Price), money.DefaultCurrency)
+	}
+	return money.New(uint64(i.Price), i.Currency)
+}
+
+// ItemBulkResult is the result of an entry of bulk operations. Item is nil when Err is set or the item is deleted.
+type ItemBulkResult struct {
+	ID   uint
+	Item *Item
+	Err  error
+}
+
+// ItemSearchResult is a row of full-text search. It isn't a table.
+type ItemSearchResult struct {
+	Item
+	Rank float64
+	// matched words are wrapped with <mark></mark>
+	Headline string
 }
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+type Plan string
+
+const (
+	PlanFree Plan = "free"
+	PlanPro  Plan = "pro"
+)
+
+// ItemLimits limit the listings of a user. They're set per Plan and can be overridden per user by UserLimitOverride.
+type ItemLimits struct {
+	MaxActiveListings uint
+	MaxCreatesPerDay  uint
+	MaxPrice          uint
+}
+
+// UserLimitOverride is set by an admin to change the limits of a user from those of the plan.
+// A nil limit is taken from the plan.
+type UserLimitOverride struct {
+	UserID            uint `gorm:"primaryKey;autoIncrement:false"`
+	User              User `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	MaxActiveListings *uint
+	MaxCreatesPerDay  *uint
+	MaxPrice          *uint
+	UpdatedAt         time.Time
+}
+
+func (o *UserLimitOverride) Apply(limits ItemLimits) ItemLimits {
+	if o.MaxActiveListings != nil {
+		limits.MaxActiveListings = *o.MaxActiveListings
+	}
+	if o.MaxCreatesPerDay != nil {
+		limits.MaxCreatesPerDay = *o.MaxCreatesPerDay
+	}
+	if o.MaxPrice != nil {
+		limits.MaxPrice = *o.MaxPrice
+	}
+	return limits
+}
+
+// ItemUsage is counted against ItemLimits. It isn't a table.
+// Active listings are the unsold items which aren't deleted. Creates are counted over the last 24 hours
+// including deleted items, so that deleting items doesn't allow creating more.
+type ItemUsage struct {
+	ActiveListings uint
+	CreatesPerDay  uint
+}
+
+// ItemLimitStatus is the limits of a user and how much of them is used.
+type ItemLimitStatus struct {
+	Plan       Plan
+	Limits     ItemLimits
+	Overridden bool
+	Usage      ItemUsage
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type ReportReason string
+
+const (
+	ReportProhibited ReportReason = "prohibited"
+	ReportOffensive  ReportReason = "offensive"
+	ReportFraud      ReportReason = "fraud"
+	ReportOther      ReportReason = "other"
+)
+
+// ItemReport flags an item for admins. A user can report an item only once.
+type ItemReport struct {
+	ID         uint         `gorm:"primarykey"`
+	ItemID     uint         `gorm:"not null;uniqueIndex:idx_item_reports_item_reporter,priority:1"`
+	Item       Item         `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	ReporterID uint         `gorm:"not null;uniqueIndex:idx_item_reports_item_reporter,priority:2"`
+	Reporter   User         `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Reason     ReportReason `gorm:"not null"`
+	Comment    string
+	CreatedAt  time.Time
+	// set when an admin decides on the item
+	ResolvedAt *time.Time `gorm:"index"`
+}
+
+// ModerationQueueEntry is an item waiting for an admin with its unresolved reports. It isn't a table.
+type ModerationQueueEntry struct {
+	Item    Item
+	Reports []ItemReport
+}
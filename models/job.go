@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type JobState string
+
+const (
+	JobQueued  JobState = "queued"
+	JobRunning JobState = "running"
+	JobDone    JobState = "done"
+	// failed MaxAttempts times. It's kept for investigation and never run again.
+	JobDead JobState = "dead"
+	// failed while another job with the same UniqueKey was queued, which runs instead of its retry
+	JobSuperseded JobState = "superseded"
+)
+
+// Job is a unit of background work claimed by workers with SELECT ... FOR UPDATE SKIP LOCKED.
+type Job struct {
+	ID   uint   `gorm:"primarykey"`
+	Kind string `gorm:"not null"`
+	// JSON of the payload type registered for Kind
+	Payload     string    `gorm:"type:text;not null"`
+	State       JobState  `gorm:"not null;default:queued;index:idx_jobs_state_run_at,priority:1"`
+	RunAt       time.Time `gorm:"not null;index:idx_jobs_state_run_at,priority:2"`
+	Attempts    int       `gorm:"not null;default:0"`
+	MaxAttempts int       `gorm:"not null"`
+	// only one queued job can have the same key
+	UniqueKey *string `gorm:"index:idx_jobs_unique_key,unique,where:unique_key IS NOT NULL AND state = 'queued'"`
+	// a running job whose lock has expired is claimed again, because its worker has stopped
+	LockedUntil *time.Time
+	LastError   string `gorm:"type:text"`
+	FinishedAt  *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
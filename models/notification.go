@@ -0,0 +1,18 @@
+package models
+
+import "gorm.io/gorm"
+
+type NotificationType string
+
+const (
+	NotificationItemSold      NotificationType = "item_sold"
+	NotificationOfferReceived NotificationType = "offer_received"
+)
+
+// ID is used as the SSE event id, so that clients can resume with Last-Event-ID.
+type Notification struct {
+	gorm.Model
+	UserID  uint             `gorm:"not null;index"`
+	Type    NotificationType `gorm:"not null"`
+	Payload string           `gorm:"type:text"`
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type OfferStatus string
+
+const (
+	OfferPending   OfferStatus = "pending"
+	OfferAccepted  OfferStatus = "accepted"
+	OfferDeclined  OfferStatus = "declined"
+	OfferCountered OfferStatus = "countered"
+	OfferExpired   OfferStatus = "expired"
+	OfferWithdrawn OfferStatus = "withdrawn"
+)
+
+// Only pending offers can be changed. Every other status is final.
+var offerTransitions = map[OfferStatus][]OfferStatus{
+	OfferPending: {OfferAccepted, OfferDeclined, OfferCountered, OfferExpired, OfferWithdrawn},
+}
+
+func (s OfferStatus) CanTransitionTo(next OfferStatus) bool {
+	for _, status := range offerTransitions[s] {
+		if status == next {
+			return true
+		}
+	}
+	return false
+}
+
+// A counter-offer is a new Offer whose ParentID is the countered one,
+// so ProposerID is the buyer for an offer and the seller for a counter-offer.
+type Offer struct {
+	gorm.Model
+	ItemID     uint        `gorm:"not null;index"`
+	BuyerID    uint        `gorm:"not null;index"`
+	SellerID   uint        `gorm:"not null;index"`
+	ProposerID uint        `gorm:"not null"`
+	Price      uint        `gorm:"not null"`
+	Status     OfferStatus `gorm:"not null;default:pending;index"`
+	ParentID   *uint
+	ExpiresAt  time.Time `gorm:"not null"`
+	// set when accepted. until then, only the buyer can purchase the item at Price.
+	ReservedUntil *time.Time
+}
+
+// The party who can accept, decline or counter the offer.
+func (o *Offer) ResponderID() uint {
+	if o.ProposerID == o.BuyerID {
+		return o.SellerID
+	}
+	return o.BuyerID
+}
+
+func (o *Offer) IsParticipant(userId uint) bool {
+	return o.BuyerID == userId || o.SellerID == userId
+}
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOfferStatus_CanTransitionTo(t *testing.T) {
+	for _, next := range []OfferStatus{OfferAccepted, OfferDeclined, OfferCountered, OfferExpired, OfferWithdrawn} {
+		assert.True(t, OfferPending.CanTransitionTo(next), next)
+	}
+
+	for _, final := range []OfferStatus{OfferAccepted, OfferDeclined, OfferCountered, OfferExpired, OfferWithdrawn} {
+		for _, next := range []OfferStatus{OfferPending, OfferAccepted, OfferDeclined, OfferCountered, OfferExpired, OfferWithdrawn} {
+			assert.False(t, final.CanTransitionTo(next), "%s -> %s", final, next)
+		}
+	}
+}
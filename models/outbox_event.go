@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+// types of OutboxEvent
+const (
+	EventItemCreated = "item.created"
+	EventItemUpdated = "item.updated"
+	EventItemDeleted = "item.deleted"
+)
+
+const AggregateItem = "item"
+
+// OutboxEvent is a domain event written in the same transaction as the change, and published later by the outbox relay.
+// Events of the same aggregate are published in the order of ID.
+type OutboxEvent struct {
+	ID            uint   `gorm:"primarykey"`
+	AggregateType string `gorm:"not null;index:idx_outbox_aggregate,priority:1"`
+	AggregateID   uint   `gorm:"not null;index:idx_outbox_aggregate,priority:2"`
+	Type          string `gorm:"not null"`
+	// JSON
+	Payload   string `gorm:"type:text;not null"`
+	CreatedAt time.Time
+	// nil until published
+	PublishedAt   *time.Time `gorm:"index"`
+	Attempts      int        `gorm:"not null;default:0"`
+	NextAttemptAt *time.Time
+	LastError     string `gorm:"type:text"`
+	// a relay is publishing the event until then. The event of a relay which has stopped is claimed again after that.
+	ClaimedUntil *time.Time
+}
+
+func (OutboxEvent) TableName() string {
+	return "outbox"
+}
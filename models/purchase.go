@@ -0,0 +1,13 @@
+package models
+
+import "gorm.io/gorm"
+
+// Purchase is created when an item is sold to a buyer. OfferID is set when bought at an offered price.
+type Purchase struct {
+	gorm.Model
+	ItemID   uint `gorm:"not null;uniqueIndex"`
+	BuyerID  uint `gorm:"not null;index"`
+	SellerID uint `gorm:"not null;index"`
+	Price    uint `gorm:"not null"`
+	OfferID  *uint
+}
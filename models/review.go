@@ -0,0 +1,21 @@
+package models
+
+import "gorm.io/gorm"
+
+type ReviewerRole string
+
+const (
+	ReviewerBuyer  ReviewerRole = "buyer"
+	ReviewerSeller ReviewerRole = "seller"
+)
+
+// Each side of a purchase can write one review about the other side.
+type Review struct {
+	gorm.Model
+	PurchaseID   uint         `gorm:"not null;uniqueIndex:idx_reviews_purchase_reviewer"`
+	ReviewerID   uint         `gorm:"not null;uniqueIndex:idx_reviews_purchase_reviewer"`
+	RevieweeID   uint         `gorm:"not null;index"`
+	ReviewerRole ReviewerRole `gorm:"not null"`
+	Rating       uint         `gorm:"not null"`
+	Comment      string
+}
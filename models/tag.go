@@ -0,0 +1,9 @@
+package models
+
+import "gorm.io/gorm"
+
+// Tag is free-form and shared between items. Name is normalized to lower case.
+type Tag struct {
+	gorm.Model
+	Name string `gorm:"not null;uniqueIndex"`
+}
@@ -5,6 +5,19 @@ import "gorm.io/gorm"
 type User struct {
 	gorm.Model
 	Email    string `gorm:"not null;unique"`
-	Password string `gorm:"not null"`
+	Password string `gorm:"not null" json:"-"`
 	Items    []Item `gorm:"constraint:OnDelete:CASCADE"`
+	// updated incrementally whenever the user receives a review, so profiles don't aggregate reviews.
+	ReviewCount uint `gorm:"not null;default:0"`
+	RatingSum   uint `gorm:"not null;default:0"`
+	Plan        Plan `gorm:"not null;default:free"`
+	// admins can override the limits of users
+	Admin bool `gorm:"not null;default:false"`
+}
+
+func (u *User) AverageRating() float64 {
+	if u.ReviewCount == 0 {
+		return 0
+	}
+	return float64(u.RatingSum) / float64(u.ReviewCount)
 }
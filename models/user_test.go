@@ -0,0 +1,12 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUser_AverageRating(t *testing.T) {
+	assert.Equal(t, 0.0, (&User{}).AverageRating())
+	assert.Equal(t, 4.5, (&User{ReviewCount: 2, RatingSum: 9}).AverageRating())
+}
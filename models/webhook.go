@@ -0,0 +1,47 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WebhookEvents are the notifications which can be subscribed to by webhooks.
+var WebhookEvents = []NotificationType{NotificationOfferReceived, NotificationItemSold}
+
+// WebhookTestEvent is delivered by POST /me/webhooks/:id/test.
+const WebhookTestEvent NotificationType = "ping"
+
+// Webhook is a URL of a user to which the subscribed notifications are POSTed.
+type Webhook struct {
+	gorm.Model
+	UserID uint               `gorm:"not null;index"`
+	URL    string             `gorm:"not null"`
+	Events []NotificationType `gorm:"type:text;serializer:json;not null"`
+	// key of the HMAC-SHA256 signature of deliveries. It's shown to the user only when the webhook is created.
+	Secret string `gorm:"not null"`
+}
+
+func (w Webhook) Subscribes(event NotificationType) bool {
+	return slices.Contains(w.Events, event)
+}
+
+// WebhookDelivery is the log of an attempt to deliver an event to a webhook.
+type WebhookDelivery struct {
+	ID        uint `gorm:"primarykey"`
+	WebhookID uint `gorm:"not null;index"`
+	// the same for every attempt of an event, so that the receiver can deduplicate them
+	EventID string           `gorm:"not null"`
+	Event   NotificationType `gorm:"not null"`
+	Attempt int              `gorm:"not null"`
+	// 0 when no response was received
+	StatusCode int
+	Error      string `gorm:"type:text"`
+	DurationMs int64
+	CreatedAt  time.Time
+}
+
+func (d WebhookDelivery) Succeeded() bool {
+	return d.StatusCode >= 200 && d.StatusCode < 300
+}
@@ -19,7 +19,7 @@ func NewAuthRepository(db *gorm.DB) *AuthRepository {
 }
 
 func (r *AuthRepository) CreateUser(ctx context.Context, user models.User) error {
-	result := r.db.Create(&user)
+	result := dbFrom(ctx, r.db).Create(&user)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
 			return utils.NewDuplicateKeyError(fmt.Sprintf("Duplicated key %s", user.Email), result.Error)
@@ -32,7 +32,7 @@ func (r *AuthRepository) CreateUser(ctx context.Context, user models.User) error
 func (r *AuthRepository) FindUser(ctx context.Context, email string) (*models.User, error) {
 
 	var user models.User
-	result := r.db.First(&user, "email = ?", email)
+	result := dbFrom(ctx, r.db).First(&user, "email = ?", email)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, utils.NewNotFoundError(fmt.Sprintf("user %v not found", email), result.Error)
@@ -41,3 +41,23 @@ func (r *AuthRepository) FindUser(ctx context.Context, email string) (*models.Us
 	}
 	return &user, nil
 }
+
+func (r *AuthRepository) FindUserById(ctx context.Context, userId uint) (*models.User, error) {
+	var user models.User
+	result := dbFrom(ctx, r.db).First(&user, "id = ?", userId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, utils.NewNotFoundError(fmt.Sprintf("user %d not found", userId), result.Error)
+		}
+		return nil, utils.NewDBError("Find user failed", result.Error)
+	}
+	return &user, nil
+}
+
+func (r *AuthRepository) UpdatePassword(ctx context.Context, userId uint, hashed string) error {
+	result := dbFrom(ctx, r.db).Model(&models.User{}).Where("id = ?", userId).Update("password", hashed)
+	if result.Error != nil {
+		return utils.NewDBError("Update password failed", result.Error)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"flea-market/models"
+	"flea-market/utils"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	return &CategoryRepository{db: db}
+}
+
+func (r *CategoryRepository) FindAll(ctx context.Context) (*[]models.Category, error) {
+	var categories []models.Category
+	result := dbFrom(ctx, r.db).Order("id").Find(&categories)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Find categories failed", result.Error)
+	}
+	return &categories, nil
+}
+
+// CountItems returns the number of items on sale directly in each category.
+func (r *CategoryRepository) CountItems(ctx context.Context) (map[uint]int64, error) {
+	var rows []struct {
+		CategoryID uint
+		Count      int64
+	}
+	result := dbFrom(ctx, r.db).
+		Model(&models.Item{}).
+		Select("category_id, count(*) AS count").
+		Where("category_id IS NOT NULL AND sold_out = ? AND moderation_status = ?", false, models.ModerationApproved).
+		Group("category_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Count items failed", result.Error)
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.CategoryID] = row.Count
+	}
+	return counts, nil
+}
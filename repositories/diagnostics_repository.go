@@ -0,0 +1,28 @@
+package repositories
+
+import (
+	"context"
+	"flea-market/infra"
+	"flea-market/utils"
+
+	"gorm.io/gorm"
+)
+
+// PoolStats is the connection pool stats of the primary or a replica.
+type PoolStats = infra.PoolStats
+
+type DiagnosticsRepository struct {
+	db *gorm.DB
+}
+
+func NewDiagnosticsRepository(db *gorm.DB) *DiagnosticsRepository {
+	return &DiagnosticsRepository{db: db}
+}
+
+func (r *DiagnosticsRepository) PoolStats(ctx context.Context) ([]PoolStats, error) {
+	stats, err := infra.PoolStatsOf(ctx, r.db)
+	if err != nil {
+		return nil, utils.NewDBError("Getting pool stats failed", err)
+	}
+	return stats, nil
+}
@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"context"
+	"flea-market/infra"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) *IdempotencyRepository {
+	return &IdempotencyRepository{db: db}
+}
+
+// Reserve inserts key and returns true, or returns the user's stored key with the same value and false.
+// A stored key is replaced when it has expired, or when it has been in flight since before staleBefore,
+// which means the server stopped while handling the request.
+func (r *IdempotencyRepository) Reserve(ctx context.Context, key models.IdempotencyKey, now time.Time, staleBefore time.Time) (*models.IdempotencyKey, bool, error) {
+	db := dbFrom(ctx, r.db)
+	result := db.
+		Where("user_id = ? AND key = ?", key.UserID, key.Key).
+		Where("expires_at <= ? OR (response_status = 0 AND created_at <= ?)", now, staleBefore).
+		Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		return nil, false, utils.NewDBError("Delete expired idempotency key failed", result.Error)
+	}
+
+	// the same key may be sent concurrently, so only one of them can insert it.
+	result = db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "user_id"}, {Name: "key"}}, DoNothing: true}).Create(&key)
+	if result.Error != nil {
+		return nil, false, utils.NewDBError("Create idempotency key failed", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return &key, true, nil
+	}
+
+	// the key may have been inserted just now by another process, which a replica may not have yet
+	var stored models.IdempotencyKey
+	if err := dbFrom(infra.UsePrimary(ctx), r.db).Where("user_id = ? AND key = ?", key.UserID, key.Key).First(&stored).Error; err != nil {
+		return nil, false, utils.NewDBError("Find idempotency key failed", err)
+	}
+	return &stored, false, nil
+}
+
+// Complete stores the response of the request.
+func (r *IdempotencyRepository) Complete(ctx context.Context, id uint, status int, headers http.Header, body []byte) error {
+	result := dbFrom(ctx, r.db).
+		Model(&models.IdempotencyKey{ID: id}).
+		Updates(models.IdempotencyKey{ResponseStatus: status, ResponseHeaders: headers, ResponseBody: body})
+	if result.Error != nil {
+		return utils.NewDBError("Update idempotency key failed", result.Error)
+	}
+	return nil
+}
+
+// Delete releases the key, so that the request can be retried with it.
+func (r *IdempotencyRepository) Delete(ctx context.Context, id uint) error {
+	result := dbFrom(ctx, r.db).Delete(&models.IdempotencyKey{}, id)
+	if result.Error != nil {
+		return utils.NewDBError("Delete idempotency key failed", result.Error)
+	}
+	return nil
+}
+
+// DeleteExpired deletes the keys expired at now and returns the number of them.
+func (r *IdempotencyRepository) DeleteExpired(ctx context.Context, now time.Time) (int64, error) {
+	result := dbFrom(ctx, r.db).Where("expires_at <= ?", now).Delete(&models.IdempotencyKey{})
+	if result.Error != nil {
+		return 0, utils.NewDBError("Delete expired idempotency keys failed", result.Error)
+	}
+	return result.RowsAffected, nil
+}
@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"context"
+	"flea-market/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyRepository_Reserve(t *testing.T) {
+	now := time.Now()
+	staleBefore := now.Add(-time.Minute)
+	deleteSQL := regexp.QuoteMeta(`DELETE FROM "idempotency_keys" WHERE (user_id = $1 AND key = $2) AND (expires_at <= $3 OR (response_status = 0 AND created_at <= $4))`)
+	insertSQL := regexp.QuoteMeta(`INSERT INTO "idempotency_keys"`) + `.*` + regexp.QuoteMeta(`ON CONFLICT ("user_id","key") DO NOTHING RETURNING "id"`)
+
+	t.Run("inserted", func(t *testing.T) {
+		gdb, mock, _ := setupTestDB(t)
+		repo := NewIdempotencyRepository(gdb)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteSQL).WithArgs(1, "k", now, staleBefore).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
+		mock.ExpectCommit()
+
+		key, reserved, err := repo.Reserve(context.Background(), models.IdempotencyKey{UserID: 1, Key: "k"}, now, staleBefore)
+
+		assert.NoError(t, err)
+		assert.True(t, reserved)
+		assert.Equal(t, uint(5), key.ID)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("already used", func(t *testing.T) {
+		gdb, mock, _ := setupTestDB(t)
+		repo := NewIdempotencyRepository(gdb)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "idempotency_keys" WHERE user_id = $1 AND key = $2 ORDER BY "idempotency_keys"."id" LIMIT $3`)).
+			WithArgs(1, "k", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "fingerprint", "response_status", "response_headers"}).
+				AddRow(3, "fp", 201, `{"Location":["/items/1"]}`))
+
+		key, reserved, err := repo.Reserve(context.Background(), models.IdempotencyKey{UserID: 1, Key: "k"}, now, staleBefore)
+
+		assert.NoError(t, err)
+		assert.False(t, reserved)
+		assert.Equal(t, uint(3), key.ID)
+		assert.Equal(t, "/items/1", key.ResponseHeaders.Get("Location"))
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
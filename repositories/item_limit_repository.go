@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"flea-market/models"
+	"flea-market/utils"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ItemLimitRepository struct {
+	db *gorm.DB
+}
+
+func NewItemLimitRepository(db *gorm.DB) *ItemLimitRepository {
+	return &ItemLimitRepository{db: db}
+}
+
+// CountUsage counts both of the usage in one query over the items of the user, which is indexed by user_id.
+// Deleted items are included only when they're created since since.
+func (r *ItemLimitRepository) CountUsage(ctx context.Context, userId uint, since time.Time) (*models.ItemUsage, error) {
+	var usage models.ItemUsage
+	result := dbFrom(ctx, r.db).Unscoped().Model(&models.Item{}).
+		Select("COUNT(CASE WHEN deleted_at IS NULL AND sold_out = ? THEN 1 END) AS active_listings, COUNT(CASE WHEN created_at >= ? THEN 1 END) AS creates_per_day", false, since).
+		Where("user_id = ? AND (deleted_at IS NULL OR created_at >= ?)", userId, since).
+		Scan(&usage)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Count item usage failed", result.Error)
+	}
+	return &usage, nil
+}
+
+// FindOverride returns nil without an error when the user has no override.
+func (r *ItemLimitRepository) FindOverride(ctx context.Context, userId uint) (*models.UserLimitOverride, error) {
+	var override models.UserLimitOverride
+	result := dbFrom(ctx, r.db).First(&override, "user_id = ?", userId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, utils.NewDBError("Find limit override failed", result.Error)
+	}
+	return &override, nil
+}
+
+// SaveOverride replaces the override of the user, including the limits which are nil.
+func (r *ItemLimitRepository) SaveOverride(ctx context.Context, override models.UserLimitOverride) (*models.UserLimitOverride, error) {
+	result := dbFrom(ctx, r.db).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_active_listings", "max_creates_per_day", "max_price", "updated_at"}),
+	}).Create(&override)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Save limit override failed", result.Error)
+	}
+	return &override, nil
+}
+
+// DeleteOverride resets the limits of the user to those of the plan. It doesn't fail when there's no override.
+func (r *ItemLimitRepository) DeleteOverride(ctx context.Context, userId uint) error {
+	result := dbFrom(ctx, r.db).Where("user_id = ?", userId).Delete(&models.UserLimitOverride{})
+	if result.Error != nil {
+		return utils.NewDBError("Delete limit override failed", result.Error)
+	}
+	return nil
+}
@@ -3,13 +3,17 @@ package repositories
 import (
 	"context"
 	"errors"
+	"flea-market/dto"
+	"flea-market/infra"
 	"flea-market/models"
 	"flea-market/utils"
 	"fmt"
+	"html"
 	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ItemRepository struct {
@@ -21,11 +25,27 @@ func (r *ItemRepository) Create(ctx context.Context, newItem models.Item) (*mode
 	apiReqCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
-	result := r.db.WithContext(apiReqCtx).Create(&newItem)
-	if result.Error != nil {
+	err := dbFrom(apiReqCtx, r.db).Transaction(func(tx *gorm.DB) error {
+		tags, err := resolveTags(tx, newItem.Tags)
+		if err != nil {
+			return err
+		}
+		newItem.Tags = tags
+		if err := tx.Create(&newItem).Error; err != nil {
+			return err
+		}
+		return addItemEvent(tx, models.EventItemCreated, newItem)
+	})
+	if err != nil {
+		if _, ok := err.(*utils.APIError); ok {
+			return nil, err
+		}
+		if errors.Is(err, gorm.ErrForeignKeyViolated) {
+			return nil, utils.NewBadRequestError(fmt.Sprintf("category %v not found", newItem.CategoryID), err)
+		}
 		// "strings.Contains" completely is a workaround.
 		// But as far as searched, a better way is not found.
-		errMsg := result.Error.Error()
+		errMsg := err.Error()
 		if strings.Contains(errMsg, "context deadline exceeded") {
 			return nil, errors.New("DB処理がタイムアウトしました:" + errMsg)
 		}
@@ -33,7 +53,7 @@ func (r *ItemRepository) Create(ctx context.Context, newItem models.Item) (*mode
 			return nil, errors.New("DB処理がキャンセルされました:" + errMsg)
 		}
 		// 他のエラー
-		return nil, utils.NewDBError("Create item failed", result.Error)
+		return nil, utils.NewDBError("Create item failed", err)
 	}
 
 	return &newItem, nil
@@ -49,17 +69,28 @@ func (r *ItemRepository) Delete(ctx context.Context, itemId uint, userId uint) e
 		)
 	}
 
-	result := r.db.Delete(&deleteItem)
-	if result.Error != nil {
-		return utils.NewDBError("Delete from item failed", result.Error)
-	}
-	return nil
+	return dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(deleteItem).Error; err != nil {
+			return utils.NewDBError("Delete from item failed", err)
+		}
+		return addItemDeletedEvent(tx, deleteItem.ID, userId)
+	})
 }
 
 // FindAll implements IItemRepository.
-func (r *ItemRepository) FindAll(ctx context.Context) (*[]models.Item, error) {
+func (r *ItemRepository) FindAll(ctx context.Context, query dto.ItemQuery) (*[]models.Item, error) {
 	var items []models.Item
-	result := r.db.Find(&items)
+	db := dbFrom(ctx, r.db).Preload("Tags").Where("moderation_status = ?", models.ModerationApproved)
+	if query.CategoryID != nil {
+		db = db.Where("category_id IN (?)", r.db.Raw(categorySubtreeSQL, *query.CategoryID))
+	}
+	if query.Tag != "" {
+		db = db.Where("id IN (?)", r.db.Table("item_tags").
+			Select("item_tags.item_id").
+			Joins("JOIN tags ON tags.id = item_tags.tag_id").
+			Where("tags.name = ?", normalizeTag(query.Tag)))
+	}
+	result := db.Find(&items)
 	if result.Error != nil {
 		return nil, utils.NewDBError("DB Error", result.Error)
 	}
@@ -67,10 +98,69 @@ func (r *ItemRepository) FindAll(ctx context.Context) (*[]models.Item, error) {
 	return &items, nil
 }
 
+// Search implements IItemRepository.
+// tsQuery must be a valid to_tsquery input. search_vector is a generated column created by cmd/migrations.
+func (r *ItemRepository) Search(ctx context.Context, tsQuery string, limit int) (*[]models.ItemSearchResult, error) {
+	if infra.IsSQLite(r.db) {
+		return r.searchSQLite(ctx, tsQuery, limit)
+	}
+
+	var results []models.ItemSearchResult
+	result := dbFrom(ctx, r.db).
+		Model(&models.Item{}).
+		Select(
+			"items.*, ts_rank(search_vector, to_tsquery('simple', ?)) AS rank, "+
+				"ts_headline('simple', name || ' ' || coalesce(description, ''), to_tsquery('simple', ?), ?) AS headline",
+			tsQuery, tsQuery, headlineOptions,
+		).
+		Where("search_vector @@ to_tsquery('simple', ?) AND moderation_status = ?", tsQuery, models.ModerationApproved).
+		Order("rank DESC, id").
+		Limit(limit).
+		Scan(&results)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Search items failed", result.Error)
+	}
+	markHeadlines(results)
+	return &results, nil
+}
+
+// searchSQLite searches the FTS5 index items_search instead, with the same weights as ts_rank.
+// The rank is negated bm25, so that a better match is greater as in Postgres.
+func (r *ItemRepository) searchSQLite(ctx context.Context, tsQuery string, limit int) (*[]models.ItemSearchResult, error) {
+	var results []models.ItemSearchResult
+	result := dbFrom(ctx, r.db).
+		Model(&models.Item{}).
+		Select(
+			"items.*, -bm25(items_search, 1.0, 0.4) AS rank, "+
+				"highlight(items_search, 0, ?, ?) || ' ' || coalesce(highlight(items_search, 1, ?, ?), '') AS headline",
+			headlineStart, headlineStop, headlineStart, headlineStop,
+		).
+		Joins("JOIN items_search ON items_search.rowid = items.id").
+		Where("items_search MATCH ? AND items.moderation_status = ?", toFTS5Query(tsQuery), models.ModerationApproved).
+		Order("rank DESC, items.id").
+		Limit(limit).
+		Scan(&results)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Search items failed", result.Error)
+	}
+	markHeadlines(results)
+	return &results, nil
+}
+
+// toFTS5Query converts "word1:* & word2:*" into `"word1"* AND "word2"*`.
+// The words have only letters and digits, so they don't have to be escaped.
+func toFTS5Query(tsQuery string) string {
+	words := strings.Split(tsQuery, " & ")
+	for i, word := range words {
+		words[i] = fmt.Sprintf(`"%s"*`, strings.TrimSuffix(word, ":*"))
+	}
+	return strings.Join(words, " AND ")
+}
+
 // FindById implements IItemRepository.
 func (r *ItemRepository) FindById(ctx context.Context, itemId uint, userId uint) (*models.Item, error) {
 	var item models.Item
-	result := r.db.First(&item, "id = ? AND user_id = ?", itemId, userId)
+	result := dbFrom(ctx, r.db).Preload("Tags").First(&item, "id = ? AND user_id = ?", itemId, userId)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, utils.NewNotFoundError("Not Found From DB", result.Error)
@@ -80,15 +170,227 @@ func (r *ItemRepository) FindById(ctx context.Context, itemId uint, userId uint)
 	return &item, nil
 }
 
-// Update implements IItemRepository.
-func (r *ItemRepository) Update(ctx context.Context, updateItem models.Item) (*models.Item, error) {
-	result := r.db.Save(&updateItem)
+// FindByIdForUpdate finds the user's item like FindById and locks it until the transaction of ctx ends,
+// so that it isn't changed between reading and writing it back.
+func (r *ItemRepository) FindByIdForUpdate(ctx context.Context, itemId uint, userId uint) (*models.Item, error) {
+	var item models.Item
+	result := dbFrom(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Tags").First(&item, "id = ? AND user_id = ?", itemId, userId)
 	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, utils.NewNotFoundError("Not Found From DB", result.Error)
+		}
+		return nil, utils.NewDBError("DB Error", result.Error)
+	}
+	return &item, nil
+}
+
+// FindPublicById finds an item regardless of its owner. It's used when a user acts on another user's item,
+// so items which aren't approved by moderation are not found.
+func (r *ItemRepository) FindPublicById(ctx context.Context, itemId uint) (*models.Item, error) {
+	var item models.Item
+	result := dbFrom(ctx, r.db).First(&item, "id = ? AND moderation_status = ?", itemId, models.ModerationApproved)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, utils.NewNotFoundError("Not Found From DB", result.Error)
+		}
 		return nil, utils.NewDBError("DB Error", result.Error)
 	}
+	return &item, nil
+}
+
+// Update implements IItemRepository.
+// updateItem.Tags replaces the current tags.
+func (r *ItemRepository) Update(ctx context.Context, updateItem models.Item) (*models.Item, error) {
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := saveItem(tx, &updateItem); err != nil {
+			return err
+		}
+		return addItemEvent(tx, models.EventItemUpdated, updateItem)
+	})
+	if err != nil {
+		return nil, itemWriteError(err, updateItem, "DB Error")
+	}
 	return &updateItem, nil
 }
 
+// CreateBulk creates items and returns the error of each item. Created items get their ids in place.
+func (r *ItemRepository) CreateBulk(ctx context.Context, items []models.Item, atomic bool) []error {
+	return runBulk(ctx, r.db, len(items), atomic, func(tx *gorm.DB, i int) error {
+		tags, err := resolveTags(tx, items[i].Tags)
+		if err != nil {
+			return err
+		}
+		items[i].Tags = tags
+		if err := tx.Create(&items[i]).Error; err != nil {
+			return itemWriteError(err, items[i], "Create item failed")
+		}
+		return addItemEvent(tx, models.EventItemCreated, items[i])
+	})
+}
+
+// DeleteBulk deletes the user's items and returns the error of each item. Other users' items are reported as not found.
+func (r *ItemRepository) DeleteBulk(ctx context.Context, itemIds []uint, userId uint, atomic bool) []error {
+	return runBulk(ctx, r.db, len(itemIds), atomic, func(tx *gorm.DB, i int) error {
+		result := tx.Where("user_id = ?", userId).Delete(&models.Item{}, itemIds[i])
+		if result.Error != nil {
+			return utils.NewDBError("Delete from item failed", result.Error)
+		}
+		if result.RowsAffected == 0 {
+			return utils.NewNotFoundError(fmt.Sprintf("Data not found itemId:%d userId:%d", itemIds[i], userId), nil)
+		}
+		return addItemDeletedEvent(tx, itemIds[i], userId)
+	})
+}
+
+// FindByUserInBatches calls fn with the user's items batch by batch, so that all of them aren't loaded at once.
+// An error of fn stops it and is returned as is.
+func (r *ItemRepository) FindByUserInBatches(ctx context.Context, userId uint, batchSize int, fn func(items []models.Item) error) error {
+	var batch []models.Item
+	var fnErr error
+	result := dbFrom(ctx, r.db).
+		Preload("Tags").
+		Where("user_id = ?", userId).
+		FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+			fnErr = fn(batch)
+			return fnErr
+		})
+	if fnErr != nil {
+		return fnErr
+	}
+	if result.Error != nil {
+		return utils.NewDBError("Find items failed", result.Error)
+	}
+	return nil
+}
+
 func NewItemRepository(db *gorm.DB) *ItemRepository {
 	return &ItemRepository{db: db}
 }
+
+// The matches are delimited by characters of the private use area, which are replaced with <mark></mark>
+// after the text is HTML-escaped, so that the name and description can't inject HTML into the headline.
+const (
+	headlineStart   = "\uE000"
+	headlineStop    = "\uE001"
+	headlineOptions = "StartSel=" + headlineStart + ", StopSel=" + headlineStop + ", MaxWords=20, MinWords=5, MaxFragments=2"
+)
+
+var headlineReplacer = strings.NewReplacer(headlineStart, "<mark>", headlineStop, "</mark>")
+
+// markHeadlines makes the headlines safe to render as HTML.
+func markHeadlines(results []models.ItemSearchResult) {
+	for i := range results {
+		results[i].Headline = headlineReplacer.Replace(html.EscapeString(results[i].Headline))
+	}
+}
+
+// ids of the category and all of its descendants
+const categorySubtreeSQL = `WITH RECURSIVE subtree AS (
+	SELECT id FROM categories WHERE id = ? AND deleted_at IS NULL
+	UNION ALL
+	SELECT c.id FROM categories c JOIN subtree s ON c.parent_id = s.id WHERE c.deleted_at IS NULL
+) SELECT id FROM subtree`
+
+func normalizeTag(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// resolveTags returns tags with ids, creating the ones which don't exist yet.
+func resolveTags(tx *gorm.DB, tags []models.Tag) ([]models.Tag, error) {
+	if len(tags) == 0 {
+		return []models.Tag{}, nil
+	}
+
+	names := make([]string, 0, len(tags))
+	newTags := make([]models.Tag, 0, len(tags))
+	for _, tag := range tags {
+		name := normalizeTag(tag.Name)
+		names = append(names, name)
+		newTags = append(newTags, models.Tag{Name: name})
+	}
+
+	// tags are shared between users, so another request may create the same tag at the same time.
+	result := tx.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(&newTags)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Create tags failed", result.Error)
+	}
+
+	var resolved []models.Tag
+	if err := tx.Where("name IN ?", names).Find(&resolved).Error; err != nil {
+		return nil, utils.NewDBError("Find tags failed", err)
+	}
+	return resolved, nil
+}
+
+// saveItem saves all columns of item and replaces its tags.
+func saveItem(tx *gorm.DB, item *models.Item) error {
+	tags, err := resolveTags(tx, item.Tags)
+	if err != nil {
+		return err
+	}
+	item.Tags = tags
+
+	if err := tx.Omit(clause.Associations).Save(item).Error; err != nil {
+		return err
+	}
+	return tx.Model(item).Association("Tags").Replace(tags)
+}
+
+func addItemEvent(tx *gorm.DB, eventType string, item models.Item) error {
+	return addOutboxEvent(tx, models.AggregateItem, item.ID, eventType, dto.NewItemResponse(item))
+}
+
+func addItemDeletedEvent(tx *gorm.DB, itemId uint, userId uint) error {
+	return addOutboxEvent(tx, models.AggregateItem, itemId, models.EventItemDeleted, dto.ItemDeletedEvent{ID: itemId, UserID: userId})
+}
+
+func itemWriteError(err error, item models.Item, detail string) error {
+	if _, ok := err.(*utils.APIError); ok {
+		return err
+	}
+	if errors.Is(err, gorm.ErrForeignKeyViolated) {
+		return utils.NewBadRequestError(fmt.Sprintf("category %v not found", item.CategoryID), err)
+	}
+	return utils.NewDBError(detail, err)
+}
+
+// runBulk runs op for each of n entries and returns the error of each entry.
+// In atomic mode, all entries run in one transaction, which stops at the first error and is rolled back.
+// Otherwise each entry runs in its own transaction, so that a failed entry doesn't affect the others.
+func runBulk(ctx context.Context, db *gorm.DB, n int, atomic bool, op func(tx *gorm.DB, i int) error) []error {
+	errs := make([]error, n)
+	if !atomic {
+		for i := range n {
+			errs[i] = dbFrom(ctx, db).Transaction(func(tx *gorm.DB) error {
+				return op(tx, i)
+			})
+		}
+		return errs
+	}
+
+	failed := -1
+	err := dbFrom(ctx, db).Transaction(func(tx *gorm.DB) error {
+		for i := range n {
+			if err := op(tx, i); err != nil {
+				failed = i
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		return errs
+	}
+	for i := range errs {
+		switch {
+		case i == failed:
+			errs[i] = err
+		case failed == -1:
+			// commit failed
+			errs[i] = utils.NewDBError("Commit bulk operation failed", err)
+		default:
+			errs[i] = utils.NewBulkEntryRolledBackError(fmt.Sprintf("entry %d failed", failed), nil)
+		}
+	}
+	return errs
+}
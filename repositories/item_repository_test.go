@@ -3,7 +3,11 @@ package repositories
 import (
 	"context"
 	"errors"
+	"flea-market/dto"
+	"flea-market/internal/money"
 	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
 	"regexp"
 	"testing"
 
@@ -48,11 +52,20 @@ func TestItemRepository_Create_Success(t *testing.T) {
 			sqlmock.AnyArg(),
 			item.Name,
 			item.Price,
+			money.JPY,
 			item.Description,
 			item.SoldOut,
 			item.UserID,
+			item.CategoryID,
+			item.Condition,
+			models.ModerationApproved,
+			item.ModerationReason,
 		).
 		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	// the event is committed with the item
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox" ("aggregate_type","aggregate_id","type","payload","created_at","published_at","attempts","next_attempt_at","last_error","claimed_until") VALUES`)).
+		WithArgs(models.AggregateItem, 1, models.EventItemCreated, sqlmock.AnyArg(), sqlmock.AnyArg(), nil, 0, nil, "", nil).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 	mock.ExpectCommit()
 
 	ctx := context.Background()
@@ -79,9 +92,14 @@ func TestItemRepository_Create_Timeout(t *testing.T) {
 			sqlmock.AnyArg(),
 			item.Name,
 			item.Price,
+			money.JPY,
 			item.Description,
 			item.SoldOut,
 			item.UserID,
+			item.CategoryID,
+			item.Condition,
+			models.ModerationApproved,
+			item.ModerationReason,
 		).
 		WillReturnError(errors.New("context deadline exceeded"))
 	mock.ExpectRollback()
@@ -109,9 +127,14 @@ func TestItemRepository_Create_Canceled(t *testing.T) {
 			sqlmock.AnyArg(),
 			item.Name,
 			item.Price,
+			money.JPY,
 			item.Description,
 			item.SoldOut,
 			item.UserID,
+			item.CategoryID,
+			item.Condition,
+			models.ModerationApproved,
+			item.ModerationReason,
 		).WillReturnError(errors.New("context canceled"))
 	mock.ExpectRollback()
 
@@ -137,9 +160,14 @@ func TestItemRepository_Create_OtherError(t *testing.T) {
 			sqlmock.AnyArg(),
 			item.Name,
 			item.Price,
+			money.JPY,
 			item.Description,
 			item.SoldOut,
 			item.UserID,
+			item.CategoryID,
+			item.Condition,
+			models.ModerationApproved,
+			item.ModerationReason,
 		).WillReturnError(gorm.ErrDuplicatedKey)
 	mock.ExpectRollback()
 
@@ -162,6 +190,9 @@ func TestItemRepository_FindById_Success(t *testing.T) {
 		WithArgs(itemID, userID, 1).
 		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "price"}).
 			AddRow(itemID, userID, "Test", 100))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "item_tags" WHERE "item_tags"."item_id" = $1`)).
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id", "tag_id"}))
 
 	ctx := context.Background()
 	item, err := repo.FindById(ctx, itemID, userID)
@@ -173,6 +204,27 @@ func TestItemRepository_FindById_Success(t *testing.T) {
 	}
 }
 
+func TestItemRepository_FindByIdForUpdate(t *testing.T) {
+	_, mock, repo := setupTestDB(t)
+	defer mock.ExpectClose()
+
+	itemID := uint(1)
+	userID := uint(2)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "items" WHERE (id = $1 AND user_id = $2) AND "items"."deleted_at" IS NULL ORDER BY "items"."id" LIMIT $3 FOR UPDATE`)).
+		WithArgs(itemID, userID, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "name", "price"}).
+			AddRow(itemID, userID, "Test", 100))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "item_tags" WHERE "item_tags"."item_id" = $1`)).
+		WithArgs(itemID).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id", "tag_id"}))
+
+	item, err := repo.FindByIdForUpdate(context.Background(), itemID, userID)
+
+	assert.NoError(t, err)
+	assert.Equal(t, itemID, item.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestItemRepository_FindById_NotFound(t *testing.T) {
 	_, mock, repo := setupTestDB(t)
 	defer mock.ExpectClose()
@@ -191,3 +243,166 @@ func TestItemRepository_FindById_NotFound(t *testing.T) {
 
 	assert.ErrorContains(t, err, "Not Found From DB")
 }
+
+func TestItemRepository_Create_WithTags(t *testing.T) {
+	_, mock, repo := setupTestDB(t)
+	defer mock.ExpectClose()
+
+	item := models.Item{UserID: 1, Name: "tagged", Price: 100, Tags: []models.Tag{{Name: " Vintage "}}}
+
+	mock.ExpectBegin()
+	// tags are normalized and created only when missing
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "tags" ("created_at","updated_at","deleted_at","name") VALUES ($1,$2,$3,$4) ON CONFLICT ("name") DO NOTHING RETURNING "id"`)).
+		WithArgs(sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), "vintage").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "tags" WHERE name IN ($1) AND "tags"."deleted_at" IS NULL`)).
+		WithArgs("vintage").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(7, "vintage"))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "items"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "tags"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectExec(regexp.QuoteMeta(`INSERT INTO "item_tags" ("item_id","tag_id") VALUES ($1,$2) ON CONFLICT DO NOTHING`)).
+		WithArgs(1, 7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
+
+	result, err := repo.Create(context.Background(), item)
+	assert.NoError(t, err)
+	assert.Equal(t, uint(7), result.Tags[0].ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItemRepository_FindAll_Filter(t *testing.T) {
+	_, mock, repo := setupTestDB(t)
+	defer mock.ExpectClose()
+
+	categoryID := uint(2)
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "items" WHERE moderation_status = $1 AND category_id IN (WITH RECURSIVE subtree AS (`)+
+		`.*`+regexp.QuoteMeta(`) SELECT id FROM subtree) AND id IN (SELECT item_tags.item_id FROM "item_tags" JOIN tags ON tags.id = item_tags.tag_id WHERE tags.name = $3) AND "items"."deleted_at" IS NULL`)).
+		WithArgs(models.ModerationApproved, categoryID, "vintage").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "Test"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "item_tags" WHERE "item_tags"."item_id" = $1`)).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id", "tag_id"}))
+
+	items, err := repo.FindAll(context.Background(), dto.ItemQuery{CategoryID: &categoryID, Tag: "Vintage"})
+	assert.NoError(t, err)
+	assert.Len(t, *items, 1)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItemRepository_Search(t *testing.T) {
+	_, mock, repo := setupTestDB(t)
+	defer mock.ExpectClose()
+
+	tsQuery := "vint:* & cam:*"
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT items.*, ts_rank(search_vector, to_tsquery('simple', $1)) AS rank, `+
+		`ts_headline('simple', name || ' ' || coalesce(description, ''), to_tsquery('simple', $2), $3) AS headline `+
+		`FROM "items" WHERE (search_vector @@ to_tsquery('simple', $4) AND moderation_status = $5) AND "items"."deleted_at" IS NULL ORDER BY rank DESC, id LIMIT $6`)).
+		WithArgs(tsQuery, tsQuery, headlineOptions, tsQuery, models.ModerationApproved, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "price", "rank", "headline"}).
+			AddRow(3, "Vintage camera", 5000, 0.6, headlineStart+"Vintage"+headlineStop+" "+headlineStart+"camera"+headlineStop+" <b>").
+			AddRow(1, "Vintage lens", 3000, 0.3, headlineStart+"Vintage"+headlineStop+" lens"))
+
+	results, err := repo.Search(context.Background(), tsQuery, 10)
+	assert.NoError(t, err)
+	assert.Len(t, *results, 2)
+	assert.Equal(t, uint(3), (*results)[0].ID)
+	assert.Equal(t, "Vintage camera", (*results)[0].Name)
+	assert.Equal(t, 0.6, (*results)[0].Rank)
+	// the text is escaped, and only the delimiters become tags
+	assert.Equal(t, "<mark>Vintage</mark> <mark>camera</mark> &lt;b&gt;", (*results)[0].Headline)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestItemRepository_Search_DBError(t *testing.T) {
+	_, mock, repo := setupTestDB(t)
+	defer mock.ExpectClose()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT items.*`)).
+		WillReturnError(errors.New("syntax error in tsquery"))
+
+	_, err := repo.Search(context.Background(), "a:*", 10)
+	assert.ErrorContains(t, err, "Search items failed")
+}
+
+func TestToFTS5Query(t *testing.T) {
+	assert.Equal(t, `"vintage"*`, toFTS5Query("vintage:*"))
+	assert.Equal(t, `"vintage"* AND "camera"*`, toFTS5Query("vintage:* & camera:*"))
+}
+
+func TestItemRepository_DeleteBulk(t *testing.T) {
+	deleteSQL := regexp.QuoteMeta(`UPDATE "items" SET "deleted_at"=$1 WHERE user_id = $2 AND "items"."id" = $3 AND "items"."deleted_at" IS NULL`)
+
+	t.Run("best effort", func(t *testing.T) {
+		_, mock, repo := setupTestDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteSQL).WithArgs(sqlmock.AnyArg(), 1, 10).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox"`)).
+			WithArgs(models.AggregateItem, 10, models.EventItemDeleted, `{"id":10,"userId":1}`, sqlmock.AnyArg(), nil, 0, nil, "", nil).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteSQL).WithArgs(sqlmock.AnyArg(), 1, 11).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		errs := repo.DeleteBulk(context.Background(), []uint{10, 11}, 1, false)
+
+		assert.NoError(t, errs[0])
+		assert.Equal(t, http.StatusNotFound, errs[1].(*utils.APIError).StatusCode)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("atomic stops at the first error and rolls back", func(t *testing.T) {
+		_, mock, repo := setupTestDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteSQL).WithArgs(sqlmock.AnyArg(), 1, 10).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "outbox"`)).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectExec(deleteSQL).WithArgs(sqlmock.AnyArg(), 1, 11).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		errs := repo.DeleteBulk(context.Background(), []uint{10, 11, 12}, 1, true)
+
+		assert.Equal(t, utils.BulkEntryRolledBack, errs[0].(*utils.APIError).MessageCode)
+		assert.Equal(t, utils.NotFound, errs[1].(*utils.APIError).MessageCode)
+		assert.Equal(t, utils.BulkEntryRolledBack, errs[2].(*utils.APIError).MessageCode)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestItemRepository_FindByUserInBatches(t *testing.T) {
+	_, mock, repo := setupTestDB(t)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "items" WHERE user_id = $1 AND "items"."deleted_at" IS NULL ORDER BY "items"."id" LIMIT $2`)).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "a").AddRow(2, "b"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "item_tags" WHERE "item_tags"."item_id" IN ($1,$2)`)).
+		WithArgs(1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id", "tag_id"}))
+	// the next batch starts after the last id
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "items" WHERE user_id = $1 AND "items"."id" > $2 AND "items"."deleted_at" IS NULL ORDER BY "items"."id" LIMIT $3`)).
+		WithArgs(1, 2, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name"}).AddRow(3, "c"))
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "item_tags" WHERE "item_tags"."item_id" = $1`)).
+		WithArgs(3).
+		WillReturnRows(sqlmock.NewRows([]string{"item_id", "tag_id"}))
+
+	var names [][]string
+	err := repo.FindByUserInBatches(context.Background(), 1, 2, func(items []models.Item) error {
+		var batch []string
+		for _, item := range items {
+			batch = append(batch, item.Name)
+		}
+		names = append(names, batch)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"a", "b"}, {"c"}}, names)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,122 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type JobRepository struct {
+	db *gorm.DB
+}
+
+func NewJobRepository(db *gorm.DB) *JobRepository {
+	return &JobRepository{db: db}
+}
+
+// Enqueue inserts job and returns true.
+// When job has a UniqueKey and a queued job has the same key, job isn't inserted and the queued one is returned with false.
+func (r *JobRepository) Enqueue(ctx context.Context, job models.Job) (*models.Job, bool, error) {
+	db := dbFrom(ctx, r.db)
+	if job.UniqueKey == nil {
+		if err := db.Create(&job).Error; err != nil {
+			return nil, false, utils.NewDBError("Create job failed", err)
+		}
+		return &job, true, nil
+	}
+
+	// the target has to match the partial unique index idx_jobs_unique_key
+	result := db.Clauses(clause.OnConflict{
+		Columns:     []clause.Column{{Name: "unique_key"}},
+		TargetWhere: clause.Where{Exprs: []clause.Expression{clause.Expr{SQL: "unique_key IS NOT NULL AND state = 'queued'"}}},
+		DoNothing:   true,
+	}).Create(&job)
+	if result.Error != nil {
+		return nil, false, utils.NewDBError("Create job failed", result.Error)
+	}
+	if result.RowsAffected == 1 {
+		return &job, true, nil
+	}
+
+	var queued models.Job
+	if err := db.Where("unique_key = ? AND state = ?", *job.UniqueKey, models.JobQueued).First(&queued).Error; err != nil {
+		return nil, false, utils.NewDBError("Find job failed", err)
+	}
+	return &queued, false, nil
+}
+
+// Claim locks the next job of kinds which is due at now and marks it running until lockedUntil.
+// SKIP LOCKED lets workers claim different jobs concurrently without waiting for each other.
+// It returns nil when there's no job to run.
+func (r *JobRepository) Claim(ctx context.Context, kinds []string, now time.Time, lockedUntil time.Time) (*models.Job, error) {
+	var job *models.Job
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		var jobs []models.Job
+		result := tx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate, Options: clause.LockingOptionsSkipLocked}).
+			Where("kind IN ?", kinds).
+			Where("(state = ? AND run_at <= ?) OR (state = ? AND locked_until <= ?)", models.JobQueued, now, models.JobRunning, now).
+			Order("run_at, id").
+			Limit(1).
+			Find(&jobs)
+		if result.Error != nil {
+			return result.Error
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		job = &jobs[0]
+		job.State = models.JobRunning
+		job.Attempts++
+		job.LockedUntil = &lockedUntil
+		return tx.Model(job).
+			Select("state", "attempts", "locked_until").
+			Updates(job).Error
+	})
+	if err != nil {
+		return nil, utils.NewDBError("Claim job failed", err)
+	}
+	return job, nil
+}
+
+// Complete marks the job done.
+// The job is identified with its attempts too, so that a worker whose lock has expired can't overwrite the next attempt.
+func (r *JobRepository) Complete(ctx context.Context, job models.Job, now time.Time) error {
+	return r.finish(ctx, job, map[string]any{"state": models.JobDone, "finished_at": now, "locked_until": nil})
+}
+
+// Retry queues the job again at runAt.
+// When a job with the same UniqueKey has been queued since the job was claimed, the job can't be queued
+// because of idx_jobs_unique_key, and it's marked superseded instead, as the queued one does the same work.
+func (r *JobRepository) Retry(ctx context.Context, job models.Job, runAt time.Time, lastError string) error {
+	err := r.finish(ctx, job, map[string]any{"state": models.JobQueued, "run_at": runAt, "last_error": lastError, "locked_until": nil})
+	if job.UniqueKey == nil || !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+	return r.finish(ctx, job, map[string]any{"state": models.JobSuperseded, "finished_at": time.Now(), "last_error": lastError, "locked_until": nil})
+}
+
+// Bury moves the job to the dead letter state.
+func (r *JobRepository) Bury(ctx context.Context, job models.Job, now time.Time, lastError string) error {
+	return r.finish(ctx, job, map[string]any{"state": models.JobDead, "finished_at": now, "last_error": lastError, "locked_until": nil})
+}
+
+func (r *JobRepository) finish(ctx context.Context, job models.Job, updates map[string]any) error {
+	result := dbFrom(ctx, r.db).
+		Model(&models.Job{}).
+		Where("id = ? AND state = ? AND attempts = ?", job.ID, models.JobRunning, job.Attempts).
+		Updates(updates)
+	if result.Error != nil {
+		return utils.NewDBError("Update job failed", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return utils.NewNotFoundError(fmt.Sprintf("job %d attempt %d is not running", job.ID, job.Attempts), nil)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package repositories
+
+import (
+	"context"
+	"flea-market/models"
+	"flea-market/utils"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestJobRepository_Claim(t *testing.T) {
+	now := time.Now()
+	lockedUntil := now.Add(time.Minute)
+	selectSQL := regexp.QuoteMeta(`SELECT * FROM "jobs" WHERE kind IN ($1,$2) AND ((state = $3 AND run_at <= $4) OR (state = $5 AND locked_until <= $6)) ORDER BY run_at, id LIMIT $7 FOR UPDATE SKIP LOCKED`)
+
+	t.Run("claimed", func(t *testing.T) {
+		gdb, mock, _ := setupTestDB(t)
+		repo := NewJobRepository(gdb)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectSQL).
+			WithArgs("a", "b", models.JobQueued, now, models.JobRunning, now, 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "state", "attempts"}).AddRow(7, "a", models.JobQueued, 1))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "jobs" SET "state"=$1,"attempts"=$2,"locked_until"=$3,"updated_at"=$4 WHERE "id" = $5`)).
+			WithArgs(models.JobRunning, 2, lockedUntil, sqlmock.AnyArg(), 7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		job, err := repo.Claim(context.Background(), []string{"a", "b"}, now, lockedUntil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), job.ID)
+		assert.Equal(t, models.JobRunning, job.State)
+		assert.Equal(t, 2, job.Attempts)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nothing to run", func(t *testing.T) {
+		gdb, mock, _ := setupTestDB(t)
+		repo := NewJobRepository(gdb)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(selectSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+		mock.ExpectCommit()
+
+		job, err := repo.Claim(context.Background(), []string{"a", "b"}, now, lockedUntil)
+
+		assert.NoError(t, err)
+		assert.Nil(t, job)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestJobRepository_Enqueue_Unique(t *testing.T) {
+	gdb, mock, _ := setupTestDB(t)
+	repo := NewJobRepository(gdb)
+	key := "purge"
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`INSERT INTO "jobs"`) + `.*` +
+		regexp.QuoteMeta(`ON CONFLICT ("unique_key") WHERE unique_key IS NOT NULL AND state = 'queued' DO NOTHING RETURNING "id"`)).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectCommit()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "jobs" WHERE unique_key = $1 AND state = $2 ORDER BY "jobs"."id" LIMIT $3`)).
+		WithArgs(key, models.JobQueued, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "unique_key"}).AddRow(4, key))
+
+	job, created, err := repo.Enqueue(context.Background(), models.Job{Kind: "purge", State: models.JobQueued, UniqueKey: &key})
+
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, uint(4), job.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_Retry_LostLock(t *testing.T) {
+	gdb, mock, _ := setupTestDB(t)
+	repo := NewJobRepository(gdb)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "jobs" SET "last_error"=$1,"locked_until"=$2,"run_at"=$3,"state"=$4,"updated_at"=$5 WHERE id = $6 AND state = $7 AND attempts = $8`)).
+		WithArgs("failed", nil, sqlmock.AnyArg(), models.JobQueued, sqlmock.AnyArg(), 7, models.JobRunning, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	err := repo.Retry(context.Background(), models.Job{ID: 7, Attempts: 2}, time.Now(), "failed")
+
+	assert.Equal(t, utils.NotFound, err.(*utils.APIError).MessageCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobRepository_Retry_Superseded(t *testing.T) {
+	gdb, mock, _ := setupTestDB(t)
+	repo := NewJobRepository(gdb)
+	key := "purge"
+
+	// another job with the key was queued while the job was running
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "jobs" SET "last_error"=$1,"locked_until"=$2,"run_at"=$3,"state"=$4,"updated_at"=$5 WHERE id = $6 AND state = $7 AND attempts = $8`)).
+		WithArgs("failed", nil, sqlmock.AnyArg(), models.JobQueued, sqlmock.AnyArg(), 7, models.JobRunning, 2).
+		WillReturnError(gorm.ErrDuplicatedKey)
+	mock.ExpectRollback()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "jobs" SET "finished_at"=$1,"last_error"=$2,"locked_until"=$3,"state"=$4,"updated_at"=$5 WHERE id = $6 AND state = $7 AND attempts = $8`)).
+		WithArgs(sqlmock.AnyArg(), "failed", nil, models.JobSuperseded, sqlmock.AnyArg(), 7, models.JobRunning, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.Retry(context.Background(), models.Job{ID: 7, Attempts: 2, UniqueKey: &key}, time.Now(), "failed")
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
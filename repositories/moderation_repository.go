@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ModerationRepository struct {
+	db *gorm.DB
+}
+
+func NewModerationRepository(db *gorm.DB) *ModerationRepository {
+	return &ModerationRepository{db: db}
+}
+
+func (r *ModerationRepository) CreateReport(ctx context.Context, report models.ItemReport) (*models.ItemReport, error) {
+	result := dbFrom(ctx, r.db).Create(&report)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrDuplicatedKey) {
+			return nil, utils.NewDuplicateKeyError(fmt.Sprintf("item %d is already reported by user %d", report.ItemID, report.ReporterID), result.Error)
+		}
+		return nil, utils.NewDBError("Create report failed", result.Error)
+	}
+	return &report, nil
+}
+
+func (r *ModerationRepository) CountOpenReports(ctx context.Context, itemId uint) (int64, error) {
+	var count int64
+	result := dbFrom(ctx, r.db).Model(&models.ItemReport{}).Where("item_id = ? AND resolved_at IS NULL", itemId).Count(&count)
+	if result.Error != nil {
+		return 0, utils.NewDBError("Count reports failed", result.Error)
+	}
+	return count, nil
+}
+
+// FindItem finds an item of any owner in any moderation status.
+func (r *ModerationRepository) FindItem(ctx context.Context, itemId uint) (*models.Item, error) {
+	var item models.Item
+	result := dbFrom(ctx, r.db).Preload("Tags").First(&item, "id = ?", itemId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, utils.NewNotFoundError(fmt.Sprintf("item %d not found", itemId), result.Error)
+		}
+		return nil, utils.NewDBError("Find item failed", result.Error)
+	}
+	return &item, nil
+}
+
+// UpdateStatus saves only the moderation status and reason of item, with an item.updated event.
+func (r *ModerationRepository) UpdateStatus(ctx context.Context, item models.Item) (*models.Item, error) {
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&item).Select("moderation_status", "moderation_reason").Updates(&item).Error; err != nil {
+			return utils.NewDBError("Update moderation status failed", err)
+		}
+		return addItemEvent(tx, models.EventItemUpdated, item)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+// ResolveReports resolves the open reports of the item when an admin decides on it.
+func (r *ModerationRepository) ResolveReports(ctx context.Context, itemId uint) error {
+	result := dbFrom(ctx, r.db).Model(&models.ItemReport{}).
+		Where("item_id = ? AND resolved_at IS NULL", itemId).
+		Update("resolved_at", time.Now())
+	if result.Error != nil {
+		return utils.NewDBError("Resolve reports failed", result.Error)
+	}
+	return nil
+}
+
+// FindQueue returns the items waiting for an admin, which are pending or have open reports, from the oldest.
+func (r *ModerationRepository) FindQueue(ctx context.Context, limit int) (*[]models.ModerationQueueEntry, error) {
+	db := dbFrom(ctx, r.db)
+	var items []models.Item
+	result := db.Preload("Tags").
+		Where("moderation_status = ? OR id IN (?)", models.ModerationPending,
+			db.Model(&models.ItemReport{}).Select("item_id").Where("resolved_at IS NULL")).
+		Order("id").
+		Limit(limit).
+		Find(&items)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Find moderation queue failed", result.Error)
+	}
+
+	entries := make([]models.ModerationQueueEntry, len(items))
+	if len(items) == 0 {
+		return &entries, nil
+	}
+	itemIds := make([]uint, len(items))
+	indexes := make(map[uint]int, len(items))
+	for i, item := range items {
+		itemIds[i] = item.ID
+		indexes[item.ID] = i
+		entries[i].Item = item
+	}
+	var reports []models.ItemReport
+	if err := db.Where("item_id IN ? AND resolved_at IS NULL", itemIds).Order("id").Find(&reports).Error; err != nil {
+		return nil, utils.NewDBError("Find reports failed", err)
+	}
+	for _, report := range reports {
+		i := indexes[report.ItemID]
+		entries[i].Reports = append(entries[i].Reports, report)
+	}
+	return &entries, nil
+}
@@ -0,0 +1,43 @@
+package repositories
+
+import (
+	"context"
+	"flea-market/models"
+	"flea-market/utils"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type NotificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *NotificationRepository {
+	return &NotificationRepository{db: db}
+}
+
+func (r *NotificationRepository) Create(ctx context.Context, notification models.Notification) (*models.Notification, error) {
+	result := dbFrom(ctx, r.db).Create(&notification)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Create notification failed", result.Error)
+	}
+	return &notification, nil
+}
+
+// FindAfter returns the latest limit notifications of the user whose id is greater than lastId and which are created since since,
+// in ascending order. It is used to replay events missed while the client was disconnected.
+func (r *NotificationRepository) FindAfter(ctx context.Context, userId uint, lastId uint, since time.Time, limit int) (*[]models.Notification, error) {
+	var notifications []models.Notification
+	result := dbFrom(ctx, r.db).
+		Where("user_id = ? AND id > ? AND created_at >= ?", userId, lastId, since).
+		Order("id DESC").
+		Limit(limit).
+		Find(&notifications)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Find notifications failed", result.Error)
+	}
+	slices.Reverse(notifications)
+	return &notifications, nil
+}
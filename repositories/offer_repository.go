@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OfferRepository struct {
+	db *gorm.DB
+}
+
+func NewOfferRepository(db *gorm.DB) *OfferRepository {
+	return &OfferRepository{db: db}
+}
+
+func (r *OfferRepository) Create(ctx context.Context, offer models.Offer) (*models.Offer, error) {
+	result := dbFrom(ctx, r.db).Create(&offer)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Create offer failed", result.Error)
+	}
+	return &offer, nil
+}
+
+func (r *OfferRepository) FindById(ctx context.Context, offerId uint) (*models.Offer, error) {
+	var offer models.Offer
+	result := dbFrom(ctx, r.db).First(&offer, "id = ?", offerId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, utils.NewNotFoundError(fmt.Sprintf("offer %d not found", offerId), result.Error)
+		}
+		return nil, utils.NewDBError("Find offer failed", result.Error)
+	}
+	return &offer, nil
+}
+
+// FindByItem returns offers on the item which the user takes part in.
+// The seller sees every offer and a buyer sees only their own.
+func (r *OfferRepository) FindByItem(ctx context.Context, itemId uint, userId uint) (*[]models.Offer, error) {
+	var offers []models.Offer
+	result := dbFrom(ctx, r.db).
+		Where("item_id = ? AND (seller_id = ? OR buyer_id = ?)", itemId, userId, userId).
+		Order("id").
+		Find(&offers)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Find offers failed", result.Error)
+	}
+	return &offers, nil
+}
+
+// UpdateStatus changes a pending offer to the given status.
+func (r *OfferRepository) UpdateStatus(ctx context.Context, offer models.Offer, status models.OfferStatus) (*models.Offer, error) {
+	if err := transitionOffer(dbFrom(ctx, r.db), &offer, status, map[string]any{}); err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+// Counter marks the offer as countered and creates the counter-offer atomically.
+func (r *OfferRepository) Counter(ctx context.Context, offer models.Offer, counterOffer models.Offer) (*models.Offer, error) {
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := transitionOffer(tx, &offer, models.OfferCountered, map[string]any{}); err != nil {
+			return err
+		}
+		if err := tx.Create(&counterOffer).Error; err != nil {
+			return utils.NewDBError("Create counter-offer failed", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &counterOffer, nil
+}
+
+// Accept reserves the item for the buyer until reservedUntil.
+// The item row is locked, so two offers on the same item can't be accepted at the same time.
+func (r *OfferRepository) Accept(ctx context.Context, offer models.Offer, reservedUntil time.Time) (*models.Offer, error) {
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		item, err := lockItem(tx, offer.ItemID)
+		if err != nil {
+			return err
+		}
+		if item.SoldOut {
+			return utils.NewItemSoldOutError(fmt.Sprintf("item %d is sold out", item.ID), nil)
+		}
+
+		if _, found, err := findReservation(tx, offer.ItemID, time.Now()); err != nil {
+			return err
+		} else if found {
+			return utils.NewItemReservedError(fmt.Sprintf("item %d is reserved by another offer", item.ID), nil)
+		}
+
+		return transitionOffer(tx, &offer, models.OfferAccepted, map[string]any{"reserved_until": reservedUntil})
+	})
+	if err != nil {
+		return nil, err
+	}
+	offer.ReservedUntil = &reservedUntil
+	return &offer, nil
+}
+
+// ExpirePending expires every pending offer whose ExpiresAt has passed, and returns the number of them.
+func (r *OfferRepository) ExpirePending(ctx context.Context, now time.Time) (int64, error) {
+	result := dbFrom(ctx, r.db).
+		Model(&models.Offer{}).
+		Where("status = ? AND expires_at <= ?", models.OfferPending, now).
+		Update("status", models.OfferExpired)
+	if result.Error != nil {
+		return 0, utils.NewDBError("Expire offers failed", result.Error)
+	}
+	return result.RowsAffected, nil
+}
+
+// transitionOffer updates the status only when the offer is still pending in DB,
+// so a concurrent request which changed it first wins and this one gets 409.
+func transitionOffer(db *gorm.DB, offer *models.Offer, status models.OfferStatus, updates map[string]any) error {
+	if !offer.Status.CanTransitionTo(status) {
+		return utils.NewOfferInvalidTransitionError(fmt.Sprintf("offer %d: %s -> %s", offer.ID, offer.Status, status), nil)
+	}
+
+	updates["status"] = status
+	result := db.Model(&models.Offer{}).
+		Where("id = ? AND status = ?", offer.ID, offer.Status).
+		Updates(updates)
+	if result.Error != nil {
+		return utils.NewDBError("Update offer failed", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return utils.NewOfferInvalidTransitionError(fmt.Sprintf("offer %d was changed by another request", offer.ID), nil)
+	}
+
+	offer.Status = status
+	return nil
+}
+
+func lockItem(tx *gorm.DB, itemId uint) (*models.Item, error) {
+	var item models.Item
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&item, "id = ?", itemId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, utils.NewNotFoundError(fmt.Sprintf("item %d not found", itemId), result.Error)
+		}
+		return nil, utils.NewDBError("Lock item failed", result.Error)
+	}
+	return &item, nil
+}
+
+// findReservation returns the accepted offer whose reservation is still valid.
+func findReservation(tx *gorm.DB, itemId uint, now time.Time) (*models.Offer, bool, error) {
+	var offer models.Offer
+	result := tx.
+		Where("item_id = ? AND status = ? AND reserved_until > ?", itemId, models.OfferAccepted, now).
+		Limit(1).
+		Find(&offer)
+	if result.Error != nil {
+		return nil, false, utils.NewDBError("Find reservation failed", result.Error)
+	}
+	return &offer, result.RowsAffected > 0, nil
+}
@@ -0,0 +1,95 @@
+package repositories
+
+import (
+	"context"
+	"flea-market/models"
+	"flea-market/utils"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupOfferTestDB(t *testing.T) (sqlmock.Sqlmock, *OfferRepository) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock db: %s", err)
+	}
+	gdb, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm db: %s", err)
+	}
+	return mock, NewOfferRepository(gdb)
+}
+
+func TestOfferRepository_UpdateStatus_Success(t *testing.T) {
+	mock, repo := setupOfferTestDB(t)
+
+	offer := models.Offer{Status: models.OfferPending}
+	offer.ID = 1
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "offers" SET "status"=$1,"updated_at"=$2 WHERE (id = $3 AND status = $4) AND "offers"."deleted_at" IS NULL`)).
+		WithArgs(models.OfferDeclined, sqlmock.AnyArg(), offer.ID, models.OfferPending).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	updated, err := repo.UpdateStatus(context.Background(), offer, models.OfferDeclined)
+	assert.NoError(t, err)
+	assert.Equal(t, models.OfferDeclined, updated.Status)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// Another request changed the status after the offer was read.
+func TestOfferRepository_UpdateStatus_Conflict(t *testing.T) {
+	mock, repo := setupOfferTestDB(t)
+
+	offer := models.Offer{Status: models.OfferPending}
+	offer.ID = 1
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "offers"`)).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	_, err := repo.UpdateStatus(context.Background(), offer, models.OfferAccepted)
+	var apiErr *utils.APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, utils.OfferInvalidTransition, apiErr.MessageCode)
+	assert.Equal(t, 409, apiErr.StatusCode)
+}
+
+func TestOfferRepository_UpdateStatus_FinalStatus(t *testing.T) {
+	mock, repo := setupOfferTestDB(t)
+
+	offer := models.Offer{Status: models.OfferDeclined}
+	offer.ID = 1
+
+	_, err := repo.UpdateStatus(context.Background(), offer, models.OfferAccepted)
+	var apiErr *utils.APIError
+	assert.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, utils.OfferInvalidTransition, apiErr.MessageCode)
+	// no query is issued
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOfferRepository_ExpirePending(t *testing.T) {
+	mock, repo := setupOfferTestDB(t)
+
+	now := time.Now()
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "offers" SET "status"=$1,"updated_at"=$2 WHERE (status = $3 AND expires_at <= $4) AND "offers"."deleted_at" IS NULL`)).
+		WithArgs(models.OfferExpired, sqlmock.AnyArg(), models.OfferPending, now).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	count, err := repo.ExpirePending(context.Background(), now)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+}
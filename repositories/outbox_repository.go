@@ -0,0 +1,113 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"flea-market/infra"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// an arbitrary key of pg_try_advisory_xact_lock, held by the relay claiming events
+const outboxRelayLockKey = 38_000_001
+
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Claim marks up to limit unpublished events claimed until claimedUntil and returns them in the order of ID.
+// The events are published out of any transaction and saved with Save, so that slow publishes don't hold a transaction or the lock.
+// An event isn't claimed while it or an earlier event of its aggregate waits for the next attempt or is claimed,
+// so that the events of an aggregate are published in order.
+// The claim holds a transaction-scoped advisory lock, so that relays in other processes don't claim the same events,
+// and nothing is claimed while another relay holds it.
+func (r *OutboxRepository) Claim(ctx context.Context, limit int, now time.Time, claimedUntil time.Time) ([]models.OutboxEvent, error) {
+	var events []models.OutboxEvent
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		// SQLite allows only one writer, so the transaction is the lock
+		if !infra.IsSQLite(tx) {
+			locked := false
+			if err := tx.Raw("SELECT pg_try_advisory_xact_lock(?)", outboxRelayLockKey).Scan(&locked).Error; err != nil {
+				return err
+			}
+			if !locked {
+				return nil
+			}
+		}
+
+		result := tx.Where("published_at IS NULL").
+			Where("(next_attempt_at IS NULL OR next_attempt_at <= ?) AND (claimed_until IS NULL OR claimed_until <= ?)", now, now).
+			Where(outboxEarlierWaitingSQL, now, now).
+			Order("id").Limit(limit).Find(&events)
+		if result.Error != nil || len(events) == 0 {
+			return result.Error
+		}
+
+		ids := make([]uint, len(events))
+		for i := range events {
+			ids[i] = events[i].ID
+			events[i].ClaimedUntil = &claimedUntil
+		}
+		return tx.Model(&models.OutboxEvent{}).Where("id IN ?", ids).Update("claimed_until", claimedUntil).Error
+	})
+	if err != nil {
+		return nil, utils.NewDBError("Claim outbox events failed", err)
+	}
+	return events, nil
+}
+
+// no earlier unpublished event of the aggregate waits for the next attempt or is claimed
+const outboxEarlierWaitingSQL = `NOT EXISTS (SELECT 1 FROM outbox earlier WHERE earlier.aggregate_type = outbox.aggregate_type AND earlier.aggregate_id = outbox.aggregate_id` +
+	` AND earlier.id < outbox.id AND earlier.published_at IS NULL AND (earlier.next_attempt_at > ? OR earlier.claimed_until > ?))`
+
+// Save saves the results of publishing claimed events and releases them.
+// An event which has been published by another relay since its claim expired is left as is.
+func (r *OutboxRepository) Save(ctx context.Context, events []models.OutboxEvent) error {
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, event := range events {
+			result := tx.Model(&models.OutboxEvent{}).
+				Where("id = ? AND published_at IS NULL", event.ID).
+				Updates(map[string]any{
+					"published_at":    event.PublishedAt,
+					"attempts":        event.Attempts,
+					"next_attempt_at": event.NextAttemptAt,
+					"last_error":      event.LastError,
+					"claimed_until":   nil,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return utils.NewDBError("Save outbox events failed", err)
+	}
+	return nil
+}
+
+// addOutboxEvent writes an event in tx, so that it's published only when the change is committed.
+func addOutboxEvent(tx *gorm.DB, aggregateType string, aggregateId uint, eventType string, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return utils.NewUnknownError(fmt.Sprintf("marshal %s event", eventType), err)
+	}
+	event := models.OutboxEvent{
+		AggregateType: aggregateType,
+		AggregateID:   aggregateId,
+		Type:          eventType,
+		Payload:       string(data),
+	}
+	if err := tx.Create(&event).Error; err != nil {
+		return utils.NewDBError("Create outbox event failed", err)
+	}
+	return nil
+}
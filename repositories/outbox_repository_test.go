@@ -0,0 +1,84 @@
+package repositories
+
+import (
+	"context"
+	"flea-market/models"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOutboxRepository_Claim(t *testing.T) {
+	lockSQL := regexp.QuoteMeta(`SELECT pg_try_advisory_xact_lock($1)`)
+	now := time.Now()
+	claimedUntil := now.Add(time.Minute)
+
+	t.Run("claims due events", func(t *testing.T) {
+		gdb, mock, _ := setupTestDB(t)
+		repo := NewOutboxRepository(gdb)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockSQL).WithArgs(outboxRelayLockKey).
+			WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(true))
+		// events waiting for the next attempt, and the later events of their aggregates, aren't claimed
+		mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "outbox" WHERE published_at IS NULL AND ((next_attempt_at IS NULL OR next_attempt_at <= $1) AND (claimed_until IS NULL OR claimed_until <= $2)) AND (NOT EXISTS (`)+
+			`.*`+regexp.QuoteMeta(`(earlier.next_attempt_at > $3 OR earlier.claimed_until > $4))) ORDER BY id LIMIT $5`)).
+			WithArgs(now, now, now, now, 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "aggregate_type", "aggregate_id", "type"}).
+				AddRow(1, models.AggregateItem, 3, models.EventItemCreated).
+				AddRow(2, models.AggregateItem, 3, models.EventItemUpdated))
+		mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox" SET "claimed_until"=$1 WHERE id IN ($2,$3)`)).
+			WithArgs(claimedUntil, 1, 2).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+		mock.ExpectCommit()
+
+		events, err := repo.Claim(context.Background(), 10, now, claimedUntil)
+
+		assert.NoError(t, err)
+		assert.Len(t, events, 2)
+		assert.Equal(t, claimedUntil, *events[1].ClaimedUntil)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("claims nothing while another relay holds the lock", func(t *testing.T) {
+		gdb, mock, _ := setupTestDB(t)
+		repo := NewOutboxRepository(gdb)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(lockSQL).
+			WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_xact_lock"}).AddRow(false))
+		mock.ExpectCommit()
+
+		events, err := repo.Claim(context.Background(), 10, now, claimedUntil)
+
+		assert.NoError(t, err)
+		assert.Empty(t, events)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
+
+func TestOutboxRepository_Save(t *testing.T) {
+	gdb, mock, _ := setupTestDB(t)
+	repo := NewOutboxRepository(gdb)
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox" SET "attempts"=$1,"claimed_until"=$2,"last_error"=$3,"next_attempt_at"=$4,"published_at"=$5 WHERE id = $6 AND published_at IS NULL`)).
+		WithArgs(0, nil, "", nil, now, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "outbox" SET "attempts"=$1,"claimed_until"=$2,"last_error"=$3,"next_attempt_at"=$4,"published_at"=$5 WHERE id = $6 AND published_at IS NULL`)).
+		WithArgs(1, nil, "unavailable", now, nil, 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	err := repo.Save(context.Background(), []models.OutboxEvent{
+		{ID: 1, PublishedAt: &now},
+		{ID: 2, Attempts: 1, NextAttemptAt: &now, LastError: "unavailable"},
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PurchaseRepository struct {
+	db *gorm.DB
+}
+
+func NewPurchaseRepository(db *gorm.DB) *PurchaseRepository {
+	return &PurchaseRepository{db: db}
+}
+
+// Purchase marks the item as sold and records the purchase.
+// While the item is reserved by an accepted offer, only its buyer can purchase it, at the offered price.
+func (r *PurchaseRepository) Purchase(ctx context.Context, itemId uint, buyerId uint) (*models.Purchase, error) {
+	var purchase models.Purchase
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		item, err := lockItem(tx, itemId)
+		if err != nil {
+			return err
+		}
+		if item.SoldOut {
+			return utils.NewItemSoldOutError(fmt.Sprintf("item %d is sold out", item.ID), nil)
+		}
+
+		purchase = models.Purchase{
+			ItemID:   item.ID,
+			BuyerID:  buyerId,
+			SellerID: item.UserID,
+			Price:    item.Price,
+		}
+
+		reservation, found, err := findReservation(tx, item.ID, time.Now())
+		if err != nil {
+			return err
+		}
+		if found {
+			if reservation.BuyerID != buyerId {
+				return utils.NewItemReservedError(fmt.Sprintf("item %d is reserved for user %d", item.ID, reservation.BuyerID), nil)
+			}
+			purchase.Price = reservation.Price
+			purchase.OfferID = &reservation.ID
+		}
+
+		if err := tx.Model(item).Update("sold_out", true).Error; err != nil {
+			return utils.NewDBError("Update item failed", err)
+		}
+		if err := tx.Create(&purchase).Error; err != nil {
+			return utils.NewDBError("Create purchase failed", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &purchase, nil
+}
+
+func (r *PurchaseRepository) FindById(ctx context.Context, purchaseId uint) (*models.Purchase, error) {
+	var purchase models.Purchase
+	result := dbFrom(ctx, r.db).First(&purchase, "id = ?", purchaseId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, utils.NewNotFoundError(fmt.Sprintf("purchase %d not found", purchaseId), result.Error)
+		}
+		return nil, utils.NewDBError("Find purchase failed", result.Error)
+	}
+	return &purchase, nil
+}
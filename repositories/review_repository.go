@@ -0,0 +1,114 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"flea-market/dto"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ReviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *ReviewRepository {
+	return &ReviewRepository{db: db}
+}
+
+// Create saves the review and adds its rating to the reviewee's aggregate in the same transaction.
+func (r *ReviewRepository) Create(ctx context.Context, review models.Review) (*models.Review, error) {
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&review).Error; err != nil {
+			if errors.Is(err, gorm.ErrDuplicatedKey) {
+				return utils.NewDuplicateKeyError(fmt.Sprintf("purchase %d is already reviewed by user %d", review.PurchaseID, review.ReviewerID), err)
+			}
+			return utils.NewDBError("Create review failed", err)
+		}
+
+		result := tx.Model(&models.User{}).
+			Where("id = ?", review.RevieweeID).
+			Updates(map[string]any{
+				"review_count": gorm.Expr("review_count + 1"),
+				"rating_sum":   gorm.Expr("rating_sum + ?", review.Rating),
+			})
+		if result.Error != nil {
+			return utils.NewDBError("Update rating failed", result.Error)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+// Update applies input to the review and replaces its previous rating with the new one in the reviewee's aggregate.
+// The review is read again with a row lock, so that concurrent edits don't subtract the same previous rating twice.
+func (r *ReviewRepository) Update(ctx context.Context, reviewId uint, input dto.UpdateReviewInput) (*models.Review, error) {
+	var review models.Review
+	err := dbFrom(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&review, "id = ?", reviewId)
+		if result.Error != nil {
+			if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+				return utils.NewNotFoundError(fmt.Sprintf("review %d not found", reviewId), result.Error)
+			}
+			return utils.NewDBError("Lock review failed", result.Error)
+		}
+
+		previousRating := review.Rating
+		if input.Rating != nil {
+			review.Rating = *input.Rating
+		}
+		if input.Comment != nil {
+			review.Comment = *input.Comment
+		}
+		if err := tx.Save(&review).Error; err != nil {
+			return utils.NewDBError("Update review failed", err)
+		}
+		if review.Rating == previousRating {
+			return nil
+		}
+
+		result = tx.Model(&models.User{}).
+			Where("id = ?", review.RevieweeID).
+			Update("rating_sum", gorm.Expr("rating_sum + ? - ?", review.Rating, previousRating))
+		if result.Error != nil {
+			return utils.NewDBError("Update rating failed", result.Error)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &review, nil
+}
+
+func (r *ReviewRepository) FindById(ctx context.Context, reviewId uint) (*models.Review, error) {
+	var review models.Review
+	result := dbFrom(ctx, r.db).First(&review, "id = ?", reviewId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, utils.NewNotFoundError(fmt.Sprintf("review %d not found", reviewId), result.Error)
+		}
+		return nil, utils.NewDBError("Find review failed", result.Error)
+	}
+	return &review, nil
+}
+
+// FindByReviewee returns reviews the user received, newest first.
+func (r *ReviewRepository) FindByReviewee(ctx context.Context, userId uint) (*[]models.Review, error) {
+	var reviews []models.Review
+	result := dbFrom(ctx, r.db).
+		Where("reviewee_id = ?", userId).
+		Order("id DESC").
+		Find(&reviews)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Find reviews failed", result.Error)
+	}
+	return &reviews, nil
+}
@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"context"
+	"flea-market/dto"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func setupReviewTestDB(t *testing.T) (sqlmock.Sqlmock, *ReviewRepository) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock db: %s", err)
+	}
+	gdb, err := gorm.Open(postgres.New(postgres.Config{
+		Conn: db,
+	}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm db: %s", err)
+	}
+	return mock, NewReviewRepository(gdb)
+}
+
+// The previous rating is taken from the row locked in the transaction, not from the caller's earlier read.
+func TestReviewRepository_Update_LocksReview(t *testing.T) {
+	mock, repo := setupReviewTestDB(t)
+	now := time.Now()
+	rating := uint(2)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "reviews" WHERE id = $1 AND "reviews"."deleted_at" IS NULL ORDER BY "reviews"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at", "purchase_id", "reviewer_id", "reviewee_id", "rating", "comment"}).
+			AddRow(3, now, 1, 1, 2, 4, "good"))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "reviews" SET`)).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(regexp.QuoteMeta(`UPDATE "users" SET "rating_sum"=rating_sum + $1 - $2,"updated_at"=$3 WHERE id = $4`)).
+		WithArgs(2, 4, sqlmock.AnyArg(), 2).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	review, err := repo.Update(context.Background(), 3, dto.UpdateReviewInput{Rating: &rating})
+	assert.NoError(t, err)
+	assert.Equal(t, uint(2), review.Rating)
+	assert.Equal(t, "good", review.Comment)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
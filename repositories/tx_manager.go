@@ -0,0 +1,46 @@
+package repositories
+
+import (
+	"context"
+	"flea-market/utils"
+
+	"gorm.io/gorm"
+)
+
+type txKey struct{}
+
+// TxManager makes calls of repositories atomic. The transaction is passed through the context,
+// so repositories don't need to know whether they are called in a transaction.
+type TxManager struct {
+	db *gorm.DB
+}
+
+func NewTxManager(db *gorm.DB) *TxManager {
+	return &TxManager{db: db}
+}
+
+// WithinTx runs fn in a transaction, which is committed when fn returns nil and rolled back when it returns an error or panics.
+// Repositories called with the ctx passed to fn use the transaction, so the ctx must not be used by other goroutines.
+//
+// Within another WithinTx it makes a savepoint instead: an error rolls back only the changes made in fn,
+// and the outer transaction can go on.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	err := dbFrom(ctx, m.db).Transaction(func(tx *gorm.DB) error {
+		return fn(context.WithValue(ctx, txKey{}, tx))
+	})
+	if err != nil {
+		if _, ok := err.(*utils.APIError); ok {
+			return err
+		}
+		return utils.NewDBError("Transaction failed", err)
+	}
+	return nil
+}
+
+// dbFrom returns the transaction of WithinTx in ctx, or db outside of WithinTx. Every repository queries through it.
+func dbFrom(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx.WithContext(ctx)
+	}
+	return db.WithContext(ctx)
+}
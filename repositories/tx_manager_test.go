@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"flea-market/models"
+	"flea-market/utils"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxManager_WithinTx(t *testing.T) {
+	insertUserSQL := regexp.QuoteMeta(`INSERT INTO "users"`)
+	insertItemSQL := regexp.QuoteMeta(`INSERT INTO "items"`)
+	insertOutboxSQL := regexp.QuoteMeta(`INSERT INTO "outbox"`)
+	// repositories which use a transaction themselves make a savepoint in the outer one
+	savepointSQL := `SAVEPOINT sp\d+`
+	rollbackToSQL := `ROLLBACK TO SAVEPOINT sp\d+`
+
+	user := models.User{Email: "new@test.com", Password: "hashed"}
+	item := models.Item{Name: "first item", Price: 100, UserID: 3}
+
+	t.Run("commits a user and their first item together", func(t *testing.T) {
+		gdb, mock, itemRepo := setupTestDB(t)
+		authRepo := NewAuthRepository(gdb)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertUserSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectExec(savepointSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(insertItemSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectQuery(insertOutboxSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+		mock.ExpectCommit()
+
+		err := NewTxManager(gdb).WithinTx(context.Background(), func(ctx context.Context) error {
+			if err := authRepo.CreateUser(ctx, user); err != nil {
+				return err
+			}
+			_, err := itemRepo.Create(ctx, item)
+			return err
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back the user when the item fails", func(t *testing.T) {
+		gdb, mock, itemRepo := setupTestDB(t)
+		authRepo := NewAuthRepository(gdb)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertUserSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectExec(savepointSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(insertItemSQL).WillReturnError(errors.New("insert failed"))
+		mock.ExpectExec(rollbackToSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectRollback()
+
+		err := NewTxManager(gdb).WithinTx(context.Background(), func(ctx context.Context) error {
+			if err := authRepo.CreateUser(ctx, user); err != nil {
+				return err
+			}
+			_, err := itemRepo.Create(ctx, item)
+			return err
+		})
+
+		assert.Equal(t, utils.DBError, err.(*utils.APIError).MessageCode)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("nested WithinTx rolls back only to its savepoint", func(t *testing.T) {
+		gdb, mock, _ := setupTestDB(t)
+		authRepo := NewAuthRepository(gdb)
+		txManager := NewTxManager(gdb)
+		innerErr := utils.NewBadRequestError("inner failed", nil)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(insertUserSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+		mock.ExpectExec(savepointSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(insertUserSQL).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(4))
+		mock.ExpectExec(rollbackToSQL).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+			if err := authRepo.CreateUser(ctx, user); err != nil {
+				return err
+			}
+			err := txManager.WithinTx(ctx, func(ctx context.Context) error {
+				if err := authRepo.CreateUser(ctx, models.User{Email: "other@test.com", Password: "hashed"}); err != nil {
+					return err
+				}
+				return innerErr
+			})
+			assert.Same(t, innerErr, err)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("rolls back on panic", func(t *testing.T) {
+		gdb, mock, _ := setupTestDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectRollback()
+
+		assert.Panics(t, func() {
+			_ = NewTxManager(gdb).WithinTx(context.Background(), func(ctx context.Context) error {
+				panic("boom")
+			})
+		})
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("commit failure is a DB error", func(t *testing.T) {
+		gdb, mock, _ := setupTestDB(t)
+
+		mock.ExpectBegin()
+		mock.ExpectCommit().WillReturnError(errors.New("connection lost"))
+
+		err := NewTxManager(gdb).WithinTx(context.Background(), func(ctx context.Context) error {
+			return nil
+		})
+
+		assert.Equal(t, utils.DBError, err.(*utils.APIError).MessageCode)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
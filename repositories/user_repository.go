@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type UserRepository struct {
+	db *gorm.DB
+}
+
+func NewUserRepository(db *gorm.DB) *UserRepository {
+	return &UserRepository{db: db}
+}
+
+func (r *UserRepository) FindById(ctx context.Context, userId uint) (*models.User, error) {
+	var user models.User
+	result := dbFrom(ctx, r.db).First(&user, "id = ?", userId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, utils.NewNotFoundError(fmt.Sprintf("user %d not found", userId), result.Error)
+		}
+		return nil, utils.NewDBError("Find user failed", result.Error)
+	}
+	return &user, nil
+}
+
+// FindByIdForUpdate finds the user like FindById and locks it until the transaction of ctx ends,
+// so that the checks of the limits of the user run one at a time.
+func (r *UserRepository) FindByIdForUpdate(ctx context.Context, userId uint) (*models.User, error) {
+	var user models.User
+	result := dbFrom(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, "id = ?", userId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, utils.NewNotFoundError(fmt.Sprintf("user %d not found", userId), result.Error)
+		}
+		return nil, utils.NewDBError("Find user failed", result.Error)
+	}
+	return &user, nil
+}
+
+func (r *UserRepository) UpdatePlan(ctx context.Context, userId uint, plan models.Plan) error {
+	result := dbFrom(ctx, r.db).Model(&models.User{}).Where("id = ?", userId).Update("plan", plan)
+	if result.Error != nil {
+		return utils.NewDBError("Update plan failed", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return utils.NewNotFoundError(fmt.Sprintf("user %d not found", userId), nil)
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package repositories
+
+import (
+	"context"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// The user row is locked, so that the limits of the user are checked one create at a time.
+func TestUserRepository_FindByIdForUpdate(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock db: %s", err)
+	}
+	gdb, err := gorm.Open(postgres.New(postgres.Config{Conn: db}), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("failed to open gorm db: %s", err)
+	}
+	repo := NewUserRepository(gdb)
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT * FROM "users" WHERE id = $1 AND "users"."deleted_at" IS NULL ORDER BY "users"."id" LIMIT $2 FOR UPDATE`)).
+		WithArgs(1, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "email", "plan"}).AddRow(1, "test1@example.com", "free"))
+
+	user, err := repo.FindByIdForUpdate(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, uint(1), user.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,78 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type WebhookRepository struct {
+	db *gorm.DB
+}
+
+func NewWebhookRepository(db *gorm.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) Create(ctx context.Context, webhook models.Webhook) (*models.Webhook, error) {
+	result := dbFrom(ctx, r.db).Create(&webhook)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Create webhook failed", result.Error)
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) FindByUser(ctx context.Context, userId uint) (*[]models.Webhook, error) {
+	var webhooks []models.Webhook
+	result := dbFrom(ctx, r.db).Where("user_id = ?", userId).Order("id").Find(&webhooks)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Find webhooks failed", result.Error)
+	}
+	return &webhooks, nil
+}
+
+// FindById finds the user's webhook. Another user's webhook is not found.
+func (r *WebhookRepository) FindById(ctx context.Context, webhookId uint, userId uint) (*models.Webhook, error) {
+	var webhook models.Webhook
+	result := dbFrom(ctx, r.db).First(&webhook, "id = ? AND user_id = ?", webhookId, userId)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, utils.NewNotFoundError(fmt.Sprintf("webhook %d not found", webhookId), result.Error)
+		}
+		return nil, utils.NewDBError("Find webhook failed", result.Error)
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) Delete(ctx context.Context, webhookId uint, userId uint) error {
+	result := dbFrom(ctx, r.db).Where("id = ? AND user_id = ?", webhookId, userId).Delete(&models.Webhook{})
+	if result.Error != nil {
+		return utils.NewDBError("Delete webhook failed", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return utils.NewNotFoundError(fmt.Sprintf("webhook %d not found", webhookId), nil)
+	}
+	return nil
+}
+
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	result := dbFrom(ctx, r.db).Create(&delivery)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Create webhook delivery failed", result.Error)
+	}
+	return &delivery, nil
+}
+
+// FindDeliveries returns the latest deliveries of the webhook first.
+func (r *WebhookRepository) FindDeliveries(ctx context.Context, webhookId uint, limit int) (*[]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	result := dbFrom(ctx, r.db).Where("webhook_id = ?", webhookId).Order("id DESC").Limit(limit).Find(&deliveries)
+	if result.Error != nil {
+		return nil, utils.NewDBError("Find webhook deliveries failed", result.Error)
+	}
+	return &deliveries, nil
+}
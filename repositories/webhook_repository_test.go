@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"context"
+	"flea-market/utils"
+	"regexp"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWebhookRepository_Delete(t *testing.T) {
+	deleteSQL := regexp.QuoteMeta(`UPDATE "webhooks" SET "deleted_at"=$1 WHERE (id = $2 AND user_id = $3) AND "webhooks"."deleted_at" IS NULL`)
+
+	t.Run("deleted", func(t *testing.T) {
+		gdb, mock, _ := setupTestDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteSQL).WithArgs(sqlmock.AnyArg(), 1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		err := NewWebhookRepository(gdb).Delete(context.Background(), 1, 2)
+
+		assert.NoError(t, err)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("another user's webhook is not found", func(t *testing.T) {
+		gdb, mock, _ := setupTestDB(t)
+		mock.ExpectBegin()
+		mock.ExpectExec(deleteSQL).WithArgs(sqlmock.AnyArg(), 1, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectCommit()
+
+		err := NewWebhookRepository(gdb).Delete(context.Background(), 1, 3)
+
+		assert.Equal(t, utils.NotFound, err.(*utils.APIError).MessageCode)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -3,109 +3,160 @@ package services
 import (
 	"context"
 	"errors"
+	"flea-market/internal/jwtauth"
+	"flea-market/internal/password"
 	"flea-market/models"
 	"flea-market/utils"
-	"fmt"
-	"os"
-	"time"
+	"sync"
 
 	"github.com/golang-jwt/jwt/v5"
-	"golang.org/x/crypto/bcrypt"
 )
 
 type IAuthRepository interface {
 	CreateUser(ctx context.Context, user models.User) error
 	FindUser(ctx context.Context, email string) (*models.User, error)
+	FindUserById(ctx context.Context, userId uint) (*models.User, error)
+	UpdatePassword(ctx context.Context, userId uint, hashed string) error
+}
+
+// ITokenIssuer issues and verifies the access tokens, which is jwtauth.Issuer.
+type ITokenIssuer interface {
+	Issue(userId uint, email string) (string, error)
+	Verify(token string) (*jwtauth.Claims, error)
 }
 
 type AuthService struct {
 	repository IAuthRepository
+	hasher     password.Hasher
+	policy     *password.Policy
+	tokens     ITokenIssuer
+
+	dummyOnce sync.Once
+	dummy     string
 }
 
-func (s *AuthService) Signup(ctx context.Context, email string, password string) error {
-	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+func (s *AuthService) Signup(ctx context.Context, email string, plain string) error {
+	if err := s.checkPolicy(plain, email); err != nil {
+		return err
+	}
+	hashed, err := hashPassword(s.hasher, plain)
 	if err != nil {
-		return utils.NewUnknownError("bcrypt.GenerateFromPassword failed", err)
+		return err
 	}
-	user := models.User{Email: email, Password: string(hashed)}
+	user := models.User{Email: email, Password: hashed}
 	return s.repository.CreateUser(ctx, user)
 
 }
 
-func (s *AuthService) Login(ctx context.Context, email string, password string) (*string, error) {
+// checkPolicy checks a new password. Only Signup sets one for now, and a password reset would have to call it too.
+func (s *AuthService) checkPolicy(plain string, email string) error {
+	err := s.policy.Validate(plain, email)
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, password.ErrLength):
+		return utils.NewPasswordLengthInvalidError("password is rejected by the policy", err)
+	case errors.Is(err, password.ErrBreached):
+		return utils.NewPasswordBreachedError("password is rejected by the policy", err)
+	case errors.Is(err, password.ErrSameAsEmail):
+		return utils.NewPasswordSameAsEmailError("password is rejected by the policy", err)
+	default:
+		return utils.NewUnknownError("password policy failed", err)
+	}
+}
+
+// HashPassword hashes a password to be stored as models.User.Password with the default argon2id.
+// It's used for the users loaded from fixtures, and they can log in whichever hasher is configured.
+func HashPassword(plain string) (string, error) {
+	return hashPassword(password.NewArgon2id(password.DefaultArgon2idParams), plain)
+}
+
+func hashPassword(hasher password.Hasher, plain string) (string, error) {
+	hashed, err := hasher.Hash(plain)
+	if errors.Is(err, password.ErrTooLong) {
+		return "", utils.NewPasswordLengthInvalidError("password is too long for the hasher", err)
+	}
+	if err != nil {
+		return "", utils.NewUnknownError("hashing password failed", err)
+	}
+	return hashed, nil
+}
+
+// Login upgrades the hash of the password when it's made by an old algorithm or parameters,
+// which is possible only here because the plain password is needed.
+func (s *AuthService) Login(ctx context.Context, email string, plain string) (*string, error) {
 	user, err := s.repository.FindUser(ctx, email)
 	if err != nil {
+		if apiErr, ok := err.(*utils.APIError); ok && apiErr.MessageCode == utils.NotFound {
+			// verifies anyway and fails like a wrong password, so that neither the response time
+			// nor the response tells whether the email is registered
+			_, _ = s.hasher.Verify(plain, s.dummyHash())
+			return nil, utils.NewUnauthorized("Invalid email or password", err)
+		}
 		return nil, err
 	}
 
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	ok, err := s.hasher.Verify(plain, user.Password)
 	if err != nil {
-		return nil, utils.NewUnauthorized("Invalid email or password", err)
+		return nil, utils.NewUnknownError("verifying password failed", err)
+	}
+	if !ok {
+		return nil, utils.NewUnauthorized("Invalid email or password", errors.New("password doesn't match"))
+	}
+	if s.hasher.NeedsRehash(user.Password) {
+		s.rehash(ctx, user.ID, plain)
 	}
 
-	token, err := CreateToken(user.ID, user.Email)
+	token, err := s.tokens.Issue(user.ID, user.Email)
 	if err != nil {
-		return nil, err
+		return nil, utils.NewUnknownError("Creating JWT failed", err)
 	}
 
-	return token, nil
+	return &token, nil
 }
 
-func (s *AuthService) GetUserFromToken(token string) (*models.User, error) {
-	parsedToken, err := jwt.Parse(token, func(t *jwt.Token) (any, error) {
-		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, utils.NewUnauthorized("JWT is invalid", fmt.Errorf("unexpected signing method %v", t.Header["alg"]))
-		}
-		return []byte(os.Getenv("SECRET_KEY")), nil
+// dummyHash is made once by the hasher, so that verifying it costs as much as verifying a real password.
+func (s *AuthService) dummyHash() string {
+	s.dummyOnce.Do(func() {
+		s.dummy, _ = s.hasher.Hash("dummy password for unknown users")
 	})
+	return s.dummy
+}
 
+func (s *AuthService) GetUserFromToken(token string) (*models.User, error) {
+	claims, err := s.tokens.Verify(token)
+	if errors.Is(err, jwt.ErrTokenExpired) {
+		return nil, utils.NewUnauthorized("token is expired", err)
+	}
 	if err != nil {
-		return nil, utils.NewUnauthorized("failed to parse token", err)
+		return nil, utils.NewUnauthorized("token is invalid", err)
 	}
 
-	var user *models.User
-	if claims, ok := parsedToken.Claims.(jwt.MapClaims); ok {
-		exp, ok := claims["exp"].(float64)
-		if !ok {
-			return nil, utils.NewUnauthorized("token is invalid", errors.New("can't get exp from the token"))
-		}
-
-		if float64(time.Now().Unix()) > exp {
-			return nil, utils.NewUnauthorized("token is expired", jwt.ErrTokenExpired)
-		}
-
-		// this function is called by middleware, therefore context is not passed, so create context here.
-		ctx := context.TODO()
-		user, err = s.repository.FindUser(ctx, claims["email"].(string))
-		if err != nil {
-			return nil, err
-		}
+	// this function is called by middleware, therefore context is not passed, so create context here.
+	ctx := context.TODO()
+	// the user is found by sub, and the email has to match too,
+	// so that a token doesn't go to another user who has taken the email after it was changed
+	user, err := s.repository.FindUserById(ctx, claims.UserID())
+	if err != nil {
+		return nil, err
+	}
+	if user.Email != claims.Email {
+		return nil, utils.NewUnauthorized("token is invalid", errors.New("email doesn't match the user"))
 	}
-
 	return user, nil
-
 }
 
-func NewAuthService(repository IAuthRepository) *AuthService {
-	return &AuthService{repository: repository}
+func NewAuthService(repository IAuthRepository, hasher password.Hasher, policy *password.Policy, tokens ITokenIssuer) *AuthService {
+	return &AuthService{repository: repository, hasher: hasher, policy: policy, tokens: tokens}
 }
 
-func CreateToken(userId uint, email string) (*string, error) {
-	secret := os.Getenv("SECRET_KEY")
-	if secret == "" {
-		return nil, utils.NewUnknownError("Internal Error", errors.New("SECRET_KEY is not set"))
+// The password is already verified, so failing to rehash must not fail the login. It's tried again on the next login.
+func (s *AuthService) rehash(ctx context.Context, userId uint, plain string) {
+	hashed, err := s.hasher.Hash(plain)
+	if err == nil {
+		err = s.repository.UpdatePassword(ctx, userId, hashed)
 	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"sub":   userId,
-		"email": email,
-		"exp":   time.Now().Add(time.Hour).Unix(),
-	})
-
-	tokenString, err := token.SignedString([]byte(secret))
 	if err != nil {
-		return nil, utils.NewUnknownError("Creating JWT failed", err)
+		utils.Logger(utils.PasswordRehashFailed, "", "", "", userId, err.Error())
 	}
-
-	return &tokenString, nil
 }
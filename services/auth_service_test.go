@@ -1,13 +1,19 @@
 package services
 
 import (
+	"context"
+	"flea-market/internal/jwtauth"
+	"flea-market/internal/mocks"
+	"flea-market/internal/password"
 	test_utils "flea-market/internal/test/utils"
+	"flea-market/models"
+	"flea-market/utils"
 	"os"
 	"testing"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/bcrypt"
 )
 
 func TestMain(m *testing.M) {
@@ -18,42 +24,115 @@ func TestMain(m *testing.M) {
 	os.Exit(code)
 }
 
-func TestCreateToken(t *testing.T) {
+var testTokenConfig = jwtauth.Config{Issuer: "flea-market", Audience: "flea-market", TTL: time.Hour}
 
-	userId := uint(123)
-	email := "test@example.com"
+func newTestAuthService(t *testing.T, tokens ITokenIssuer) *AuthService {
+	hashed, err := bcrypt.GenerateFromPassword([]byte("nikutaberu"), bcrypt.MinCost)
+	assert.NoError(t, err)
+	repo := &mocks.MockAuthRepository{
+		FindUserFunc: func(ctx context.Context, email string) (*models.User, error) {
+			user := &models.User{Email: email, Password: string(hashed)}
+			user.ID = 123
+			return user, nil
+		},
+		FindUserByIdFunc: func(ctx context.Context, userId uint) (*models.User, error) {
+			if userId != 123 {
+				return nil, utils.NewNotFoundError("user not found", nil)
+			}
+			user := &models.User{Email: "test@example.com", Password: string(hashed)}
+			user.ID = userId
+			return user, nil
+		},
+	}
+	hasher := password.NewBcrypt(bcrypt.MinCost)
+	policy := password.NewPolicy(password.DefaultMinLength, password.DefaultMaxLength, nil)
+	return NewAuthService(repo, hasher, policy, tokens)
+}
+
+func TestLoginIssuesToken(t *testing.T) {
+	tokens := jwtauth.NewIssuer(jwtauth.NewSecretKeySet([]byte("test")), testTokenConfig)
+	service := newTestAuthService(t, tokens)
 
-	tokenStr, err := CreateToken(userId, email)
+	token, err := service.Login(context.Background(), "test@example.com", "nikutaberu")
 	assert.NoError(t, err)
-	assert.NotNil(t, tokenStr)
 
-	// トークンのパース
-	token, err := jwt.Parse(*tokenStr, func(token *jwt.Token) (interface{}, error) {
-		return []byte(os.Getenv("SECRET_KEY")), nil
-	})
+	claims, err := tokens.Verify(*token)
 	assert.NoError(t, err)
-	assert.True(t, token.Valid)
+	assert.Equal(t, "123", claims.Subject)
+	assert.Equal(t, "test@example.com", claims.Email)
+	// exp(有効期限)が将来になっていることを確認
+	assert.True(t, claims.ExpiresAt.After(time.Now()))
 
-	claims, ok := token.Claims.(jwt.MapClaims)
-	assert.True(t, ok)
-	assert.Equal(t, float64(userId), claims["sub"])
-	assert.Equal(t, email, claims["email"])
+	user, err := service.GetUserFromToken(*token)
+	assert.NoError(t, err)
+	assert.Equal(t, "test@example.com", user.Email)
+}
 
-	// exp(有効期限)が将来になっていることを確認
-	exp := int64(claims["exp"].(float64))
-	assert.Greater(t, exp, time.Now().Unix())
+func TestGetUserFromInvalidToken(t *testing.T) {
+	tokens := jwtauth.NewIssuer(jwtauth.NewSecretKeySet([]byte("test")), testTokenConfig)
+	service := newTestAuthService(t, tokens)
+
+	expired := testTokenConfig
+	expired.TTL = -time.Minute
+	otherAudience := testTokenConfig
+	otherAudience.Audience = "other"
+	cases := []struct {
+		name   string
+		issuer *jwtauth.Issuer
+	}{
+		{"expired", jwtauth.NewIssuer(jwtauth.NewSecretKeySet([]byte("test")), expired)},
+		{"other audience", jwtauth.NewIssuer(jwtauth.NewSecretKeySet([]byte("test")), otherAudience)},
+		{"other secret", jwtauth.NewIssuer(jwtauth.NewSecretKeySet([]byte("other")), testTokenConfig)},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			token, err := tc.issuer.Issue(123, "test@example.com")
+			assert.NoError(t, err)
+
+			_, err = service.GetUserFromToken(token)
+			assertMessageCode(t, utils.UnAuthorized, err)
+		})
+	}
+
+	t.Run("email of another user", func(t *testing.T) {
+		// the email was changed after the token was issued
+		token, err := tokens.Issue(123, "old@example.com")
+		assert.NoError(t, err)
+		_, err = service.GetUserFromToken(token)
+		assertMessageCode(t, utils.UnAuthorized, err)
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		token, err := tokens.Issue(456, "test@example.com")
+		assert.NoError(t, err)
+		_, err = service.GetUserFromToken(token)
+		assertMessageCode(t, utils.NotFound, err)
+	})
+}
+
+// countingHasher counts the verifies, which take the most time of a login.
+type countingHasher struct {
+	password.Hasher
+	verified int
 }
 
-func TestCreateToken_NoSecret(t *testing.T) {
-	// SECRET_KEY未設定時
-	oldSecret := os.Getenv("SECRET_KEY")
-	os.Setenv("SECRET_KEY", "")
-	defer os.Setenv("SECRET_KEY", oldSecret)
+func (h *countingHasher) Verify(plain string, encoded string) (bool, error) {
+	h.verified++
+	return h.Hasher.Verify(plain, encoded)
+}
 
-	userId := uint(123)
-	email := "test@example.com"
+func TestLoginUnknownEmailVerifiesDummyHash(t *testing.T) {
+	repo := &mocks.MockAuthRepository{
+		FindUserFunc: func(ctx context.Context, email string) (*models.User, error) {
+			return nil, utils.NewNotFoundError("user not found", nil)
+		},
+	}
+	hasher := &countingHasher{Hasher: password.NewBcrypt(bcrypt.MinCost)}
+	policy := password.NewPolicy(password.DefaultMinLength, password.DefaultMaxLength, nil)
+	service := NewAuthService(repo, hasher, policy, jwtauth.NewIssuer(jwtauth.NewSecretKeySet([]byte("test")), testTokenConfig))
 
-	tokenStr, err := CreateToken(userId, email)
-	assert.Error(t, err)
-	assert.Nil(t, tokenStr)
+	_, err := service.Login(context.Background(), "nobody@example.com", "nikutaberu")
+	assertMessageCode(t, utils.UnAuthorized, err)
+	assert.Equal(t, 1, hasher.verified)
+	assert.True(t, hasher.Recognizes(service.dummyHash()))
 }
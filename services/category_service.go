@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/models"
+)
+
+type ICategoryRepository interface {
+	FindAll(ctx context.Context) (*[]models.Category, error)
+	CountItems(ctx context.Context) (map[uint]int64, error)
+}
+
+type CategoryService struct {
+	repository ICategoryRepository
+}
+
+func NewCategoryService(repository ICategoryRepository) *CategoryService {
+	return &CategoryService{repository: repository}
+}
+
+// FindTree returns root categories with their descendants.
+// The tree is small, so it's built in memory instead of a recursive query.
+func (s *CategoryService) FindTree(ctx context.Context) (*[]dto.CategoryTreeNode, error) {
+	categories, err := s.repository.FindAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := s.repository.CountItems(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	children := map[uint][]models.Category{}
+	var roots []models.Category
+	for _, category := range *categories {
+		if category.ParentID == nil {
+			roots = append(roots, category)
+			continue
+		}
+		children[*category.ParentID] = append(children[*category.ParentID], category)
+	}
+
+	var build func(category models.Category) dto.CategoryTreeNode
+	build = func(category models.Category) dto.CategoryTreeNode {
+		node := dto.CategoryTreeNode{
+			ID:        category.ID,
+			Name:      category.Name,
+			Slug:      category.Slug,
+			ItemCount: counts[category.ID],
+			Children:  []dto.CategoryTreeNode{},
+		}
+		for _, child := range children[category.ID] {
+			childNode := build(child)
+			node.ItemCount += childNode.ItemCount
+			node.Children = append(node.Children, childNode)
+		}
+		return node
+	}
+
+	tree := make([]dto.CategoryTreeNode, 0, len(roots))
+	for _, root := range roots {
+		tree = append(tree, build(root))
+	}
+	return &tree, nil
+}
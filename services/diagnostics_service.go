@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/repositories"
+)
+
+type IDiagnosticsRepository interface {
+	PoolStats(ctx context.Context) ([]repositories.PoolStats, error)
+}
+
+type DiagnosticsService struct {
+	repository IDiagnosticsRepository
+}
+
+func NewDiagnosticsService(repository IDiagnosticsRepository) *DiagnosticsService {
+	return &DiagnosticsService{repository: repository}
+}
+
+func (s *DiagnosticsService) DBPoolStats(ctx context.Context) ([]dto.DBPoolStatsResponse, error) {
+	stats, err := s.repository.PoolStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	responses := make([]dto.DBPoolStatsResponse, 0, len(stats))
+	for _, stat := range stats {
+		responses = append(responses, dto.DBPoolStatsResponse{
+			Name:               stat.Name,
+			Healthy:            stat.Healthy,
+			MaxOpenConnections: stat.MaxOpenConnections,
+			OpenConnections:    stat.OpenConnections,
+			InUse:              stat.InUse,
+			Idle:               stat.Idle,
+			WaitCount:          stat.WaitCount,
+			WaitDurationMs:     stat.WaitDuration.Milliseconds(),
+			MaxIdleClosed:      stat.MaxIdleClosed,
+			MaxIdleTimeClosed:  stat.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  stat.MaxLifetimeClosed,
+		})
+	}
+	return responses, nil
+}
@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// how long a response is replayed to retries
+	idempotencyKeyTTL = 24 * time.Hour
+	// a request in flight longer than this is considered abandoned, e.g. by a crash, and the key can be used again
+	idempotencyLockTimeout = time.Minute
+)
+
+type IIdempotencyRepository interface {
+	Reserve(ctx context.Context, key models.IdempotencyKey, now time.Time, staleBefore time.Time) (*models.IdempotencyKey, bool, error)
+	Complete(ctx context.Context, id uint, status int, headers http.Header, body []byte) error
+	Delete(ctx context.Context, id uint) error
+	DeleteExpired(ctx context.Context, now time.Time) (int64, error)
+}
+
+type IdempotencyService struct {
+	repository IIdempotencyRepository
+}
+
+func NewIdempotencyService(repository IIdempotencyRepository) *IdempotencyService {
+	return &IdempotencyService{repository: repository}
+}
+
+// Begin reserves the user's key for the request identified by fingerprint.
+// It returns the stored key with true when the request has already completed and its response has to be replayed.
+// Otherwise the caller handles the request and has to call either Complete or Release.
+func (s *IdempotencyService) Begin(ctx context.Context, userId uint, key string, fingerprint string) (*models.IdempotencyKey, bool, error) {
+	now := time.Now()
+	record, reserved, err := s.repository.Reserve(ctx, models.IdempotencyKey{
+		UserID:      userId,
+		Key:         key,
+		Fingerprint: fingerprint,
+		ExpiresAt:   now.Add(idempotencyKeyTTL),
+	}, now, now.Add(-idempotencyLockTimeout))
+	if err != nil {
+		return nil, false, err
+	}
+	if reserved {
+		return record, false, nil
+	}
+
+	if record.Fingerprint != fingerprint {
+		return nil, false, utils.NewIdempotencyKeyReusedError(fmt.Sprintf("key %q userId:%d", key, userId), nil)
+	}
+	if !record.Completed() {
+		return nil, false, utils.NewIdempotencyKeyInFlightError(fmt.Sprintf("key %q userId:%d", key, userId), nil)
+	}
+	return record, true, nil
+}
+
+func (s *IdempotencyService) Complete(ctx context.Context, id uint, status int, headers http.Header, body []byte) error {
+	return s.repository.Complete(ctx, id, status, headers, body)
+}
+
+func (s *IdempotencyService) Release(ctx context.Context, id uint) error {
+	return s.repository.Delete(ctx, id)
+}
+
+// PurgeExpired deletes the keys which are no longer replayed, and returns the number of them.
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.repository.DeleteExpired(ctx, time.Now())
+}
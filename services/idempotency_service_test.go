@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+	"flea-market/internal/mocks"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyService_Begin(t *testing.T) {
+	stored := func(record models.IdempotencyKey) *mocks.MockIdempotencyRepository {
+		return &mocks.MockIdempotencyRepository{
+			ReserveFunc: func(ctx context.Context, key models.IdempotencyKey, now time.Time, staleBefore time.Time) (*models.IdempotencyKey, bool, error) {
+				return &record, false, nil
+			},
+		}
+	}
+
+	t.Run("new key", func(t *testing.T) {
+		repo := &mocks.MockIdempotencyRepository{
+			ReserveFunc: func(ctx context.Context, key models.IdempotencyKey, now time.Time, staleBefore time.Time) (*models.IdempotencyKey, bool, error) {
+				assert.Equal(t, uint(1), key.UserID)
+				assert.Equal(t, now.Add(idempotencyKeyTTL), key.ExpiresAt)
+				assert.Equal(t, now.Add(-idempotencyLockTimeout), staleBefore)
+				key.ID = 10
+				return &key, true, nil
+			},
+		}
+		record, replay, err := NewIdempotencyService(repo).Begin(context.Background(), 1, "k", "fp")
+
+		assert.NoError(t, err)
+		assert.False(t, replay)
+		assert.Equal(t, uint(10), record.ID)
+	})
+
+	t.Run("completed", func(t *testing.T) {
+		repo := stored(models.IdempotencyKey{Fingerprint: "fp", ResponseStatus: http.StatusCreated})
+		record, replay, err := NewIdempotencyService(repo).Begin(context.Background(), 1, "k", "fp")
+
+		assert.NoError(t, err)
+		assert.True(t, replay)
+		assert.Equal(t, http.StatusCreated, record.ResponseStatus)
+	})
+
+	t.Run("in flight", func(t *testing.T) {
+		repo := stored(models.IdempotencyKey{Fingerprint: "fp"})
+		_, _, err := NewIdempotencyService(repo).Begin(context.Background(), 1, "k", "fp")
+
+		assert.Equal(t, utils.IdempotencyKeyInFlight, err.(*utils.APIError).MessageCode)
+		assert.Equal(t, http.StatusTooEarly, statusOf(err))
+	})
+
+	t.Run("another request", func(t *testing.T) {
+		repo := stored(models.IdempotencyKey{Fingerprint: "other", ResponseStatus: http.StatusCreated})
+		_, _, err := NewIdempotencyService(repo).Begin(context.Background(), 1, "k", "fp")
+
+		assert.Equal(t, utils.IdempotencyKeyReused, err.(*utils.APIError).MessageCode)
+		assert.Equal(t, http.StatusConflict, statusOf(err))
+	})
+}
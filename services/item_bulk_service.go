@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/internal/money"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+
+	"github.com/gin-gonic/gin/binding"
+)
+
+// Bulk operations return a result for each entry in the same order as the input.
+// Entries are validated with the same "binding" rules as the single item endpoints.
+// In atomic mode, nothing is written when any entry is invalid or fails.
+
+func (s *ItemService) CreateBulk(ctx context.Context, inputs []dto.CreateItemInput, userId uint, atomic bool) []models.ItemBulkResult {
+	results := make([]models.ItemBulkResult, len(inputs))
+	var items []models.Item
+	var indexes []int
+	for i, input := range inputs {
+		if err := validateEntry(input); err != nil {
+			results[i].Err = err
+			continue
+		}
+		item := newItem(input, userId)
+		if err := checkPrice(item.Money()); err != nil {
+			results[i].Err = err
+			continue
+		}
+		s.moderate(ctx, &item)
+		items = append(items, item)
+		indexes = append(indexes, i)
+	}
+	if atomic && rollBackIfFailed(results) {
+		return results
+	}
+	if len(items) == 0 {
+		return results
+	}
+	// the valid entries are checked together, so exceeding the limits fails all of them
+	prices := make([]money.Money, len(items))
+	for j, item := range items {
+		prices[j] = item.Money()
+	}
+	var errs []error
+	// the limits are checked in the transaction of the creates, so that concurrent creates can't exceed them.
+	// Each entry is still created in its own savepoint unless atomic.
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.limiter.CheckCreate(ctx, userId, prices); err != nil {
+			return err
+		}
+		errs = s.repository.CreateBulk(ctx, items, atomic)
+		return nil
+	})
+	if err != nil {
+		for _, i := range indexes {
+			results[i].Err = err
+		}
+		return results
+	}
+	for j, i := range indexes {
+		results[i].ID = items[j].ID
+		results[i].Err = errs[j]
+		if errs[j] == nil {
+			results[i].Item = &items[j]
+		}
+	}
+	return results
+}
+
+// UpdateBulk changes only price and soldOut.
+// Like Update, each item is locked from reading it to writing it back, so that concurrent changes aren't lost.
+func (s *ItemService) UpdateBulk(ctx context.Context, entries []dto.BulkUpdateItemEntry, userId uint, atomic bool) []models.ItemBulkResult {
+	results := make([]models.ItemBulkResult, len(entries))
+	var indexes []int
+	for i, entry := range entries {
+		results[i].ID = entry.ID
+		if err := validateEntry(entry); err != nil {
+			results[i].Err = err
+			continue
+		}
+		indexes = append(indexes, i)
+	}
+	if atomic && rollBackIfFailed(results) {
+		return results
+	}
+
+	items := make([]*models.Item, len(indexes))
+	errs := s.withinBulkTx(ctx, len(indexes), atomic, func(ctx context.Context, j int) error {
+		entry := entries[indexes[j]]
+		item, err := s.repository.FindByIdForUpdate(ctx, entry.ID, userId)
+		if err != nil {
+			return err
+		}
+		var price *money.Money
+		if entry.Price != nil {
+			item.Price = *entry.Price
+			if err := checkPrice(item.Money()); err != nil {
+				return err
+			}
+			p := item.Money()
+			price = &p
+		}
+		relisted := false
+		if entry.SoldOut != nil {
+			relisted = item.SoldOut && !*entry.SoldOut
+			item.SoldOut = *entry.SoldOut
+		}
+		if err := s.limiter.CheckUpdate(ctx, userId, price, relisted); err != nil {
+			return err
+		}
+		if entry.Price != nil {
+			s.moderate(ctx, item)
+		}
+		items[j], err = s.repository.Update(ctx, *item)
+		return err
+	})
+	for j, i := range indexes {
+		results[i].Err = errs[j]
+		if errs[j] != nil {
+			continue
+		}
+		results[i].Item = items[j]
+	}
+	return results
+}
+
+// withinBulkTx runs op for each of n entries and returns the error of each entry.
+// In atomic mode, all entries run in one transaction, which stops at the first error and is rolled back.
+// Otherwise each entry runs in its own transaction, so that a failed entry doesn't affect the others.
+func (s *ItemService) withinBulkTx(ctx context.Context, n int, atomic bool, op func(ctx context.Context, i int) error) []error {
+	errs := make([]error, n)
+	if !atomic {
+		for i := range n {
+			errs[i] = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+				return op(ctx, i)
+			})
+		}
+		return errs
+	}
+
+	failed := -1
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		for i := range n {
+			if err := op(ctx, i); err != nil {
+				failed = i
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		return errs
+	}
+	for i := range errs {
+		switch {
+		case i == failed:
+			errs[i] = err
+		case failed == -1:
+			// commit failed
+			errs[i] = utils.NewDBError("Commit bulk operation failed", err)
+		default:
+			errs[i] = utils.NewBulkEntryRolledBackError(fmt.Sprintf("entry %d failed", failed), nil)
+		}
+	}
+	return errs
+}
+
+func (s *ItemService) DeleteBulk(ctx context.Context, itemIds []uint, userId uint, atomic bool) []models.ItemBulkResult {
+	results := make([]models.ItemBulkResult, len(itemIds))
+	errs := s.repository.DeleteBulk(ctx, itemIds, userId, atomic)
+	for i, itemId := range itemIds {
+		results[i] = models.ItemBulkResult{ID: itemId, Err: errs[i]}
+	}
+	return results
+}
+
+func validateEntry(entry any) error {
+	if err := binding.Validator.ValidateStruct(entry); err != nil {
+		return utils.NewBadRequestError("Input data is invalid", err)
+	}
+	return nil
+}
+
+// rollBackIfFailed marks the entries without an error as rolled back, when any entry has failed.
+func rollBackIfFailed(results []models.ItemBulkResult) bool {
+	failed := -1
+	for i, result := range results {
+		if result.Err != nil {
+			failed = i
+			break
+		}
+	}
+	if failed == -1 {
+		return false
+	}
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].Err = utils.NewBulkEntryRolledBackError(fmt.Sprintf("entry %d failed", failed), nil)
+		}
+	}
+	return true
+}
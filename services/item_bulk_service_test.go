@@ -0,0 +1,144 @@
+package services
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/internal/mocks"
+	"flea-market/internal/money"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func statusOf(err error) int {
+	if apiErr, ok := err.(*utils.APIError); ok {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+func TestItemService_CreateBulk(t *testing.T) {
+	inputs := []dto.CreateItemInput{
+		{Name: "lamp", Price: 100},
+		// too short name
+		{Name: "x", Price: 100},
+		{Name: "desk", Price: 200, Tags: []string{"wood"}},
+	}
+
+	t.Run("best effort", func(t *testing.T) {
+		var called []models.Item
+		repo := &mocks.MockItemRepository{
+			CreateBulkFunc: func(ctx context.Context, items []models.Item, atomic bool) []error {
+				assert.False(t, atomic)
+				called = items
+				for i := range items {
+					items[i].ID = uint(i + 1)
+				}
+				return []error{nil, utils.NewDBError("Create item failed", nil)}
+			},
+		}
+		results := NewItemService(repo, &mocks.MockTxManager{}, &mocks.MockItemLimiter{}, &mocks.MockModerator{}, money.DefaultRates()).CreateBulk(context.Background(), inputs, 1, false)
+
+		assert.Len(t, called, 2)
+		assert.Equal(t, uint(1), called[0].UserID)
+		assert.Equal(t, "lamp", results[0].Item.Name)
+		assert.Equal(t, http.StatusBadRequest, statusOf(results[1].Err))
+		assert.Equal(t, http.StatusInternalServerError, statusOf(results[2].Err))
+		assert.Nil(t, results[2].Item)
+	})
+
+	t.Run("atomic with an invalid entry", func(t *testing.T) {
+		repo := &mocks.MockItemRepository{
+			CreateBulkFunc: func(ctx context.Context, items []models.Item, atomic bool) []error {
+				t.Fatal("nothing must be written")
+				return nil
+			},
+		}
+		results := NewItemService(repo, &mocks.MockTxManager{}, &mocks.MockItemLimiter{}, &mocks.MockModerator{}, money.DefaultRates()).CreateBulk(context.Background(), inputs, 1, true)
+
+		assert.Equal(t, http.StatusFailedDependency, statusOf(results[0].Err))
+		assert.Equal(t, http.StatusBadRequest, statusOf(results[1].Err))
+		assert.Equal(t, http.StatusFailedDependency, statusOf(results[2].Err))
+	})
+}
+
+func TestItemService_UpdateBulk(t *testing.T) {
+	price := uint(50)
+	soldOut := true
+	entries := []dto.BulkUpdateItemEntry{
+		{ID: 1, Price: &price},
+		{ID: 2, SoldOut: &soldOut},
+		{ID: 3, Price: &price},
+	}
+
+	var updated []uint
+	repo := &mocks.MockItemRepository{
+		FindByIdForUpdateFunc: func(ctx context.Context, itemId uint, userId uint) (*models.Item, error) {
+			assert.Equal(t, itemId, ctx.Value(txKey{}), "locked in the transaction of the entry")
+			if itemId == 3 {
+				return nil, utils.NewNotFoundError("Not Found From DB", nil)
+			}
+			return &models.Item{Model: gorm.Model{ID: itemId}, Name: "item", Price: 100, UserID: userId}, nil
+		},
+		UpdateFunc: func(ctx context.Context, updateItem models.Item) (*models.Item, error) {
+			assert.Equal(t, updateItem.ID, ctx.Value(txKey{}), "written in the transaction of the entry")
+			updated = append(updated, updateItem.ID)
+			return &updateItem, nil
+		},
+	}
+	// each entry runs in its own transaction
+	var txs uint
+	txManager := &mocks.MockTxManager{
+		WithinTxFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+			txs++
+			return fn(context.WithValue(ctx, txKey{}, txs))
+		},
+	}
+
+	results := NewItemService(repo, txManager, &mocks.MockItemLimiter{}, &mocks.MockModerator{}, money.DefaultRates()).UpdateBulk(context.Background(), entries, 1, false)
+
+	assert.Equal(t, []uint{1, 2}, updated)
+	assert.Equal(t, uint(50), results[0].Item.Price)
+	assert.True(t, results[1].Item.SoldOut)
+	assert.Equal(t, uint(3), results[2].ID)
+	assert.Equal(t, http.StatusNotFound, statusOf(results[2].Err))
+}
+
+func TestItemService_UpdateBulk_Atomic(t *testing.T) {
+	price := uint(50)
+	entries := []dto.BulkUpdateItemEntry{
+		{ID: 1, Price: &price},
+		{ID: 2, Price: &price},
+	}
+	repo := &mocks.MockItemRepository{
+		FindByIdForUpdateFunc: func(ctx context.Context, itemId uint, userId uint) (*models.Item, error) {
+			assert.Equal(t, "tx", ctx.Value(txKey{}), "locked in the one transaction")
+			if itemId == 2 {
+				return nil, utils.NewNotFoundError("Not Found From DB", nil)
+			}
+			return &models.Item{Model: gorm.Model{ID: itemId}, Name: "item", Price: 100, UserID: userId}, nil
+		},
+		UpdateFunc: func(ctx context.Context, updateItem models.Item) (*models.Item, error) {
+			return &updateItem, nil
+		},
+	}
+	rolledBack := false
+	txManager := &mocks.MockTxManager{
+		WithinTxFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+			err := fn(context.WithValue(ctx, txKey{}, "tx"))
+			rolledBack = err != nil
+			return err
+		},
+	}
+
+	results := NewItemService(repo, txManager, &mocks.MockItemLimiter{}, &mocks.MockModerator{}, money.DefaultRates()).UpdateBulk(context.Background(), entries, 1, true)
+
+	assert.True(t, rolledBack)
+	assert.Nil(t, results[0].Item)
+	assert.Equal(t, http.StatusFailedDependency, statusOf(results[0].Err))
+	assert.Equal(t, http.StatusNotFound, statusOf(results[1].Err))
+}
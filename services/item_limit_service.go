@@ -0,0 +1,181 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"flea-market/dto"
+	"flea-market/internal/money"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"time"
+)
+
+type IItemLimitRepository interface {
+	CountUsage(ctx context.Context, userId uint, since time.Time) (*models.ItemUsage, error)
+	FindOverride(ctx context.Context, userId uint) (*models.UserLimitOverride, error)
+	SaveOverride(ctx context.Context, override models.UserLimitOverride) (*models.UserLimitOverride, error)
+	DeleteOverride(ctx context.Context, userId uint) error
+}
+
+// DefaultPlanLimits are used for the plans which aren't configured. MaxPrice is in money.DefaultCurrency.
+var DefaultPlanLimits = map[models.Plan]models.ItemLimits{
+	models.PlanFree: {MaxActiveListings: 100, MaxCreatesPerDay: 30, MaxPrice: 300000},
+	models.PlanPro:  {MaxActiveListings: 3000, MaxCreatesPerDay: 500, MaxPrice: 999999},
+}
+
+const itemLimitWindow = 24 * time.Hour
+
+type ItemLimitService struct {
+	repository     IItemLimitRepository
+	userRepository IUserRepository
+	txManager      ITxManager
+	plans          map[models.Plan]models.ItemLimits
+	rates          money.RateProvider
+	now            func() time.Time
+}
+
+func NewItemLimitService(repository IItemLimitRepository, userRepository IUserRepository, txManager ITxManager, plans map[models.Plan]models.ItemLimits, rates money.RateProvider) *ItemLimitService {
+	return &ItemLimitService{repository: repository, userRepository: userRepository, txManager: txManager, plans: plans, rates: rates, now: time.Now}
+}
+
+// Status returns the limits of the user and the usage. A user of an unknown plan gets the limits of the free plan.
+func (s *ItemLimitService) Status(ctx context.Context, userId uint) (*models.ItemLimitStatus, error) {
+	return s.status(ctx, userId, s.userRepository.FindById)
+}
+
+// lockedStatus locks the user until the transaction of ctx ends, before counting the usage.
+// A concurrent check of the same user waits for it, and then counts the items created by this transaction too.
+func (s *ItemLimitService) lockedStatus(ctx context.Context, userId uint) (*models.ItemLimitStatus, error) {
+	return s.status(ctx, userId, s.userRepository.FindByIdForUpdate)
+}
+
+func (s *ItemLimitService) status(ctx context.Context, userId uint, findUser func(ctx context.Context, userId uint) (*models.User, error)) (*models.ItemLimitStatus, error) {
+	user, err := findUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	limits, ok := s.plans[user.Plan]
+	if !ok {
+		limits = s.plans[models.PlanFree]
+	}
+	status := &models.ItemLimitStatus{Plan: user.Plan, Limits: limits}
+
+	override, err := s.repository.FindOverride(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if override != nil {
+		status.Limits = override.Apply(limits)
+		status.Overridden = true
+	}
+
+	usage, err := s.repository.CountUsage(ctx, userId, s.now().Add(-itemLimitWindow))
+	if err != nil {
+		return nil, err
+	}
+	status.Usage = *usage
+	return status, nil
+}
+
+// CheckCreate fails when creating items at prices would exceed the limits of the user:
+// 403 for the price and the active listings, and 429 for the creates in a day, which can be retried later.
+// It must be called in the transaction which creates the items, so that concurrent creates of the user can't exceed the limits.
+func (s *ItemLimitService) CheckCreate(ctx context.Context, userId uint, prices []money.Money) error {
+	status, err := s.lockedStatus(ctx, userId)
+	if err != nil {
+		return err
+	}
+	limits := status.Limits
+	if err := s.checkPrices(ctx, limits, prices); err != nil {
+		return err
+	}
+	count := uint(len(prices))
+	if err := checkActiveListings(status, count); err != nil {
+		return err
+	}
+	if status.Usage.CreatesPerDay+count > limits.MaxCreatesPerDay {
+		return utils.NewItemCreateRateLimitedError(fmt.Sprintf("%d items created in 24 hours and %d more are over the limit %d", status.Usage.CreatesPerDay, count, limits.MaxCreatesPerDay), errors.New("max creates per day exceeded"))
+	}
+	return nil
+}
+
+// CheckUpdate fails when an update of an item would exceed the limits of the user, like CheckCreate.
+// price is the new price, nil when it isn't changed, and relisted is whether the item is put back on sale.
+// An update never counts as a create. It must be called in the transaction of the update, too.
+func (s *ItemLimitService) CheckUpdate(ctx context.Context, userId uint, price *money.Money, relisted bool) error {
+	if price == nil && !relisted {
+		return nil
+	}
+	status, err := s.lockedStatus(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if price != nil {
+		if err := s.checkPrices(ctx, status.Limits, []money.Money{*price}); err != nil {
+			return err
+		}
+	}
+	if relisted {
+		return checkActiveListings(status, 1)
+	}
+	return nil
+}
+
+// Prices in the other currencies are converted into money.DefaultCurrency to compare with the max price.
+func (s *ItemLimitService) checkPrices(ctx context.Context, limits models.ItemLimits, prices []money.Money) error {
+	maxPrice := money.New(uint64(limits.MaxPrice), money.DefaultCurrency)
+	for _, price := range prices {
+		converted, err := money.Convert(ctx, s.rates, price, money.DefaultCurrency)
+		if err != nil {
+			return utils.NewUnknownError("can't convert the price", err)
+		}
+		if converted.Amount > maxPrice.Amount {
+			return utils.NewItemLimitExceededError(fmt.Sprintf("price %s is over the max price %s", price, maxPrice), errors.New("max price exceeded"))
+		}
+	}
+	return nil
+}
+
+func checkActiveListings(status *models.ItemLimitStatus, count uint) error {
+	if status.Usage.ActiveListings+count > status.Limits.MaxActiveListings {
+		return utils.NewItemLimitExceededError(fmt.Sprintf("%d active listings and %d more are over the limit %d", status.Usage.ActiveListings, count, status.Limits.MaxActiveListings), errors.New("max active listings exceeded"))
+	}
+	return nil
+}
+
+// UpdateOverride changes the plan and replaces the override of the user. Without any limit in input, the override is deleted.
+func (s *ItemLimitService) UpdateOverride(ctx context.Context, userId uint, input dto.UpdateUserLimitsInput) (*models.ItemLimitStatus, error) {
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if _, err := s.userRepository.FindById(ctx, userId); err != nil {
+			return err
+		}
+		if input.Plan != nil {
+			if err := s.userRepository.UpdatePlan(ctx, userId, *input.Plan); err != nil {
+				return err
+			}
+		}
+		if input.MaxActiveListings == nil && input.MaxCreatesPerDay == nil && input.MaxPrice == nil {
+			return s.repository.DeleteOverride(ctx, userId)
+		}
+		_, err := s.repository.SaveOverride(ctx, models.UserLimitOverride{
+			UserID:            userId,
+			MaxActiveListings: input.MaxActiveListings,
+			MaxCreatesPerDay:  input.MaxCreatesPerDay,
+			MaxPrice:          input.MaxPrice,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s.Status(ctx, userId)
+}
+
+// DeleteOverride resets the limits of the user to those of the plan.
+func (s *ItemLimitService) DeleteOverride(ctx context.Context, userId uint) error {
+	if _, err := s.userRepository.FindById(ctx, userId); err != nil {
+		return err
+	}
+	return s.repository.DeleteOverride(ctx, userId)
+}
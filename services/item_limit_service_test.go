@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"flea-market/internal/mocks"
+	"flea-market/internal/money"
+	"flea-market/models"
+	"flea-market/utils"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestItemLimitService(plan models.Plan, override *models.UserLimitOverride, usage models.ItemUsage) *ItemLimitService {
+	repo := &mocks.MockItemLimitRepository{
+		CountUsageFunc: func(ctx context.Context, userId uint, since time.Time) (*models.ItemUsage, error) {
+			return &usage, nil
+		},
+		FindOverrideFunc: func(ctx context.Context, userId uint) (*models.UserLimitOverride, error) {
+			return override, nil
+		},
+	}
+	users := &mocks.MockUserRepository{
+		FindByIdFunc: func(ctx context.Context, userId uint) (*models.User, error) {
+			return &models.User{Plan: plan}, nil
+		},
+		FindByIdForUpdateFunc: func(ctx context.Context, userId uint) (*models.User, error) {
+			return &models.User{Plan: plan}, nil
+		},
+	}
+	plans := map[models.Plan]models.ItemLimits{
+		models.PlanFree: {MaxActiveListings: 10, MaxCreatesPerDay: 5, MaxPrice: 1000},
+	}
+	return NewItemLimitService(repo, users, &mocks.MockTxManager{}, plans, money.DefaultRates())
+}
+
+func assertMessageCode(t *testing.T, code utils.MessageCode, err error) {
+	t.Helper()
+	apiErr, ok := err.(*utils.APIError)
+	if assert.True(t, ok, "not an APIError: %v", err) {
+		assert.Equal(t, code, apiErr.MessageCode)
+	}
+}
+
+func yen(amounts ...uint64) []money.Money {
+	prices := make([]money.Money, len(amounts))
+	for i, amount := range amounts {
+		prices[i] = money.New(amount, money.JPY)
+	}
+	return prices
+}
+
+func TestItemLimitService_CheckCreate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("within the limits", func(t *testing.T) {
+		s := newTestItemLimitService(models.PlanFree, nil, models.ItemUsage{ActiveListings: 8, CreatesPerDay: 3})
+		assert.NoError(t, s.CheckCreate(ctx, 1, yen(1000, 500)))
+	})
+
+	t.Run("price", func(t *testing.T) {
+		s := newTestItemLimitService(models.PlanFree, nil, models.ItemUsage{})
+		err := s.CheckCreate(ctx, 1, yen(500, 1001))
+		assertMessageCode(t, utils.ItemLimitExceeded, err)
+	})
+
+	t.Run("price in another currency is converted into yen", func(t *testing.T) {
+		s := newTestItemLimitService(models.PlanFree, nil, models.ItemUsage{})
+		// $5.00 is ¥746 and $15.00 is ¥2,239 at the default rate
+		assert.NoError(t, s.CheckCreate(ctx, 1, []money.Money{money.New(500, money.USD)}))
+		err := s.CheckCreate(ctx, 1, []money.Money{money.New(1500, money.USD)})
+		assertMessageCode(t, utils.ItemLimitExceeded, err)
+	})
+
+	t.Run("active listings", func(t *testing.T) {
+		s := newTestItemLimitService(models.PlanFree, nil, models.ItemUsage{ActiveListings: 9})
+		err := s.CheckCreate(ctx, 1, yen(100, 100))
+		assertMessageCode(t, utils.ItemLimitExceeded, err)
+	})
+
+	t.Run("creates per day are 429", func(t *testing.T) {
+		s := newTestItemLimitService(models.PlanFree, nil, models.ItemUsage{CreatesPerDay: 5})
+		err := s.CheckCreate(ctx, 1, yen(100))
+		assertMessageCode(t, utils.ItemCreateRateLimited, err)
+		assert.Equal(t, 429, err.(*utils.APIError).StatusCode)
+	})
+
+	t.Run("override replaces only the limits set", func(t *testing.T) {
+		maxPrice := uint(5000)
+		s := newTestItemLimitService(models.PlanFree, &models.UserLimitOverride{MaxPrice: &maxPrice}, models.ItemUsage{CreatesPerDay: 4})
+		assert.NoError(t, s.CheckCreate(ctx, 1, yen(5000)))
+		assertMessageCode(t, utils.ItemCreateRateLimited, s.CheckCreate(ctx, 1, yen(100, 100)))
+	})
+
+	t.Run("unknown plan gets the free limits", func(t *testing.T) {
+		s := newTestItemLimitService("enterprise", nil, models.ItemUsage{})
+		status, err := s.Status(ctx, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1000), status.Limits.MaxPrice)
+		assertMessageCode(t, utils.ItemLimitExceeded, s.CheckCreate(ctx, 1, yen(2000)))
+	})
+}
+
+func TestItemLimitService_CheckUpdate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("price", func(t *testing.T) {
+		s := newTestItemLimitService(models.PlanFree, nil, models.ItemUsage{})
+		assert.NoError(t, s.CheckUpdate(ctx, 1, &yen(1000)[0], false))
+		assertMessageCode(t, utils.ItemLimitExceeded, s.CheckUpdate(ctx, 1, &yen(1001)[0], false))
+	})
+
+	t.Run("relisting counts as an active listing", func(t *testing.T) {
+		s := newTestItemLimitService(models.PlanFree, nil, models.ItemUsage{ActiveListings: 10})
+		assertMessageCode(t, utils.ItemLimitExceeded, s.CheckUpdate(ctx, 1, nil, true))
+		assert.NoError(t, s.CheckUpdate(ctx, 1, nil, false))
+	})
+
+	t.Run("creates per day aren't counted", func(t *testing.T) {
+		s := newTestItemLimitService(models.PlanFree, nil, models.ItemUsage{ActiveListings: 9, CreatesPerDay: 5})
+		assert.NoError(t, s.CheckUpdate(ctx, 1, &yen(100)[0], true))
+	})
+}
@@ -2,28 +2,83 @@ package services
 
 import (
 	"context"
+	"errors"
 	"flea-market/dto"
+	"flea-market/internal/moderation"
+	"flea-market/internal/money"
 	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"strings"
+	"unicode"
 )
 
 type IItemRepository interface {
-	FindAll(ctx context.Context) (*[]models.Item, error)
+	FindAll(ctx context.Context, query dto.ItemQuery) (*[]models.Item, error)
 	FindById(ctx context.Context, itemId uint, userId uint) (*models.Item, error)
+	FindByIdForUpdate(ctx context.Context, itemId uint, userId uint) (*models.Item, error)
+	Search(ctx context.Context, tsQuery string, limit int) (*[]models.ItemSearchResult, error)
 	Create(ctx context.Context, newItem models.Item) (*models.Item, error)
 	Update(ctx context.Context, updateItem models.Item) (*models.Item, error)
 	Delete(ctx context.Context, itemId uint, userId uint) error
+	CreateBulk(ctx context.Context, items []models.Item, atomic bool) []error
+	DeleteBulk(ctx context.Context, itemIds []uint, userId uint, atomic bool) []error
+	FindByUserInBatches(ctx context.Context, userId uint, batchSize int, fn func(items []models.Item) error) error
+}
+
+// ITxManager makes repository calls in fn atomic.
+type ITxManager interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// IItemLimiter checks the plan limits of the user before items are created or updated.
+type IItemLimiter interface {
+	CheckCreate(ctx context.Context, userId uint, prices []money.Money) error
+	CheckUpdate(ctx context.Context, userId uint, price *money.Money, relisted bool) error
 }
 
 type ItemService struct {
 	repository IItemRepository
+	txManager  ITxManager
+	limiter    IItemLimiter
+	moderator  moderation.Moderator
+	rates      money.RateProvider
 }
 
-func NewItemService(repository IItemRepository) *ItemService {
-	return &ItemService{repository: repository}
+func NewItemService(repository IItemRepository, txManager ITxManager, limiter IItemLimiter, moderator moderation.Moderator, rates money.RateProvider) *ItemService {
+	return &ItemService{repository: repository, txManager: txManager, limiter: limiter, moderator: moderator, rates: rates}
 }
 
-func (s *ItemService) FindAll(ctx context.Context) (*[]models.Item, error) {
-	return s.repository.FindAll(ctx)
+// FindAll sets DisplayPrice of the items to the prices converted into query.Currency, when it's given.
+func (s *ItemService) FindAll(ctx context.Context, query dto.ItemQuery) (*[]models.Item, error) {
+	items, err := s.repository.FindAll(ctx, query)
+	if err != nil || query.Currency == "" {
+		return items, err
+	}
+	for i := range *items {
+		item := &(*items)[i]
+		price, err := money.Convert(ctx, s.rates, item.Money(), money.Currency(query.Currency))
+		if err != nil {
+			return nil, utils.NewUnknownError("can't convert the price", err)
+		}
+		item.DisplayPrice = &price
+	}
+	return items, nil
+}
+
+const defaultSearchLimit = 20
+
+func (s *ItemService) Search(ctx context.Context, query dto.ItemSearchQuery) (*[]models.ItemSearchResult, error) {
+	tsQuery := toPrefixTsQuery(query.Q)
+	if tsQuery == "" {
+		return nil, utils.NewBadRequestError("search words are empty", errors.New("no words in q"))
+	}
+
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	return s.repository.Search(ctx, tsQuery, limit)
 }
 
 func (s *ItemService) FindById(ctx context.Context, itemId uint, userId uint) (*models.Item, error) {
@@ -31,41 +86,154 @@ func (s *ItemService) FindById(ctx context.Context, itemId uint, userId uint) (*
 }
 
 func (s *ItemService) Create(ctx context.Context, createItemInput dto.CreateItemInput, userId uint) (*models.Item, error) {
-	newItem := models.Item{
-		Name:        createItemInput.Name,
-		Price:       createItemInput.Price,
-		Description: createItemInput.Description,
-		SoldOut:     false,
-		UserID:      userId,
+	item := newItem(createItemInput, userId)
+	if err := checkPrice(item.Money()); err != nil {
+		return nil, err
 	}
+	s.moderate(ctx, &item)
 
-	return s.repository.Create(ctx, newItem)
-
+	var created *models.Item
+	// the limits are checked in the transaction of the create, so that concurrent creates can't exceed them
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.limiter.CheckCreate(ctx, userId, []money.Money{item.Money()}); err != nil {
+			return err
+		}
+		var err error
+		created, err = s.repository.Create(ctx, item)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
 }
 
 func (s *ItemService) Update(ctx context.Context, itemId uint, updateItemInput dto.UpdateItemInput, userId uint) (*models.Item, error) {
-	targetItem, err := s.repository.FindById(ctx, itemId, userId)
+	var updatedItem *models.Item
+	// the item is locked, so that it doesn't change between reading and writing it
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		targetItem, err := s.repository.FindByIdForUpdate(ctx, itemId, userId)
+		if err != nil {
+			return err
+		}
+
+		if updateItemInput.Name != nil {
+			targetItem.Name = *updateItemInput.Name
+		}
+		if updateItemInput.Price != nil {
+			targetItem.Price = *updateItemInput.Price
+		}
+		if updateItemInput.Currency != nil {
+			targetItem.Currency = money.Currency(*updateItemInput.Currency)
+		}
+		if updateItemInput.Price != nil || updateItemInput.Currency != nil {
+			if err := checkPrice(targetItem.Money()); err != nil {
+				return err
+			}
+		}
+		if updateItemInput.Description != nil {
+			targetItem.Description = *updateItemInput.Description
+		}
+		if updateItemInput.CategoryID != nil {
+			targetItem.CategoryID = updateItemInput.CategoryID
+		}
+		if updateItemInput.Condition != nil {
+			targetItem.Condition = models.ItemCondition(*updateItemInput.Condition)
+		}
+		if updateItemInput.Tags != nil {
+			targetItem.Tags = toTags(*updateItemInput.Tags)
+		}
+		relisted := false
+		if updateItemInput.SoldOut != nil {
+			relisted = targetItem.SoldOut && !*updateItemInput.SoldOut
+			targetItem.SoldOut = *updateItemInput.SoldOut
+		}
+		var price *money.Money
+		if updateItemInput.Price != nil || updateItemInput.Currency != nil {
+			p := targetItem.Money()
+			price = &p
+		}
+		if err := s.limiter.CheckUpdate(ctx, userId, price, relisted); err != nil {
+			return err
+		}
+		if updateItemInput.Name != nil || updateItemInput.Price != nil || updateItemInput.Currency != nil || updateItemInput.Description != nil || updateItemInput.Tags != nil {
+			s.moderate(ctx, targetItem)
+		}
+
+		updatedItem, err = s.repository.Update(ctx, *targetItem)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	if updateItemInput.Name != nil {
-		targetItem.Name = *updateItemInput.Name
-	}
-	if updateItemInput.Price != nil {
-		targetItem.Price = *updateItemInput.Price
+	return updatedItem, nil
+
+}
+
+func (s *ItemService) Delete(ctx context.Context, itemId uint, userId uint) error {
+	return s.repository.Delete(ctx, itemId, userId)
+}
+
+// moderate sets the moderation status of item. A failing moderator doesn't fail the request, and the item waits for review instead.
+func (s *ItemService) moderate(ctx context.Context, item *models.Item) {
+	verdict, err := s.moderator.Moderate(ctx, *item)
+	if err != nil {
+		utils.Logger(utils.ModerationFailed, "", "", "", item.Name, err.Error())
+		verdict = moderation.Verdict{Status: models.ModerationPending, Reason: "moderation failed"}
 	}
-	if updateItemInput.Description != nil {
-		targetItem.Description = *updateItemInput.Description
+	// editing an item which an admin rejected or reports held must not approve it
+	if verdict.Status == models.ModerationApproved && item.ModerationStatus != "" && item.ModerationStatus != models.ModerationApproved {
+		verdict = moderation.Verdict{Status: models.ModerationPending, Reason: fmt.Sprintf("edited while %s", item.ModerationStatus)}
 	}
-	if updateItemInput.SoldOut != nil {
-		targetItem.SoldOut = *updateItemInput.SoldOut
+	item.ModerationStatus = verdict.Status
+	item.ModerationReason = verdict.Reason
+}
+
+// checkPrice fails with 400 when price is out of the limits of its currency.
+func checkPrice(price money.Money) error {
+	if err := price.Validate(); err != nil {
+		return utils.NewBadRequestError("Input data is invalid", err)
 	}
+	return nil
+}
 
-	return s.repository.Update(ctx, *targetItem)
+func newItem(input dto.CreateItemInput, userId uint) models.Item {
+	currency := money.DefaultCurrency
+	if input.Currency != "" {
+		currency = money.Currency(input.Currency)
+	}
+	return models.Item{
+		Name:        input.Name,
+		Price:       input.Price,
+		Currency:    currency,
+		Description: input.Description,
+		SoldOut:     false,
+		UserID:      userId,
+		CategoryID:  input.CategoryID,
+		Condition:   models.ItemCondition(input.Condition),
+		Tags:        toTags(input.Tags),
+	}
+}
 
+// ids are resolved by the repository
+func toTags(names []string) []models.Tag {
+	tags := make([]models.Tag, 0, len(names))
+	for _, name := range names {
+		tags = append(tags, models.Tag{Name: name})
+	}
+	return tags
 }
 
-func (s *ItemService) Delete(ctx context.Context, itemId uint, userId uint) error {
-	return s.repository.Delete(ctx, itemId, userId)
+// toPrefixTsQuery converts user input into "word1:* & word2:*", so that every word is prefix matched.
+// Characters other than letters and digits are treated as separators,
+// because they can be operators of to_tsquery and make the query invalid.
+func toPrefixTsQuery(input string) string {
+	words := strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = word + ":*"
+	}
+	return strings.Join(words, " & ")
 }
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"flea-market/dto"
+	"flea-market/internal/mocks"
+	"flea-market/internal/moderation"
+	"flea-market/internal/money"
+	"flea-market/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestToPrefixTsQuery(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "single word", input: "camera", want: "camera:*"},
+		{name: "multiple words", input: "Vintage  Camera", want: "vintage:* & camera:*"},
+		{name: "japanese", input: "カメラ 中古", want: "カメラ:* & 中古:*"},
+		{name: "tsquery operators are removed", input: "a & !b | (c) <-> d:*", want: "a:* & b:* & c:* & d:*"},
+		{name: "quotes are removed", input: `it's`, want: "it:* & s:*"},
+		{name: "no words", input: " &!| ", want: ""},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, toPrefixTsQuery(tc.input))
+		})
+	}
+}
+
+type txKey struct{}
+
+func TestItemService_Update_WithinTx(t *testing.T) {
+	soldOut := true
+	input := dto.UpdateItemInput{SoldOut: &soldOut}
+	newRepo := func() *mocks.MockItemRepository {
+		return &mocks.MockItemRepository{
+			FindByIdForUpdateFunc: func(ctx context.Context, itemId uint, userId uint) (*models.Item, error) {
+				assert.Equal(t, "tx", ctx.Value(txKey{}), "locked in the transaction")
+				return &models.Item{Name: "lamp", UserID: userId}, nil
+			},
+			UpdateFunc: func(ctx context.Context, updateItem models.Item) (*models.Item, error) {
+				assert.Equal(t, "tx", ctx.Value(txKey{}), "written in the transaction")
+				return &updateItem, nil
+			},
+		}
+	}
+
+	t.Run("committed", func(t *testing.T) {
+		txManager := &mocks.MockTxManager{
+			WithinTxFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+				return fn(context.WithValue(ctx, txKey{}, "tx"))
+			},
+		}
+
+		item, err := NewItemService(newRepo(), txManager, &mocks.MockItemLimiter{}, &mocks.MockModerator{}, money.DefaultRates()).Update(context.Background(), 1, input, 2)
+
+		assert.NoError(t, err)
+		assert.True(t, item.SoldOut)
+	})
+
+	t.Run("failed commit", func(t *testing.T) {
+		txManager := &mocks.MockTxManager{
+			WithinTxFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+				assert.NoError(t, fn(context.WithValue(ctx, txKey{}, "tx")))
+				return errors.New("commit failed")
+			},
+		}
+
+		item, err := NewItemService(newRepo(), txManager, &mocks.MockItemLimiter{}, &mocks.MockModerator{}, money.DefaultRates()).Update(context.Background(), 1, input, 2)
+
+		assert.EqualError(t, err, "commit failed")
+		assert.Nil(t, item)
+	})
+}
+
+func TestItemService_Create_WithinTx(t *testing.T) {
+	txManager := &mocks.MockTxManager{
+		WithinTxFunc: func(ctx context.Context, fn func(ctx context.Context) error) error {
+			return fn(context.WithValue(ctx, txKey{}, "tx"))
+		},
+	}
+	input := dto.CreateItemInput{Name: "lamp", Price: 100}
+
+	t.Run("checked and created in the same transaction", func(t *testing.T) {
+		limiter := &mocks.MockItemLimiter{
+			CheckCreateFunc: func(ctx context.Context, userId uint, prices []money.Money) error {
+				assert.Equal(t, "tx", ctx.Value(txKey{}), "checked in the transaction")
+				return nil
+			},
+		}
+		repo := &mocks.MockItemRepository{
+			CreateFunc: func(ctx context.Context, newItem models.Item) (*models.Item, error) {
+				assert.Equal(t, "tx", ctx.Value(txKey{}), "created in the transaction")
+				return &newItem, nil
+			},
+		}
+
+		item, err := NewItemService(repo, txManager, limiter, &mocks.MockModerator{}, money.DefaultRates()).Create(context.Background(), input, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "lamp", item.Name)
+	})
+
+	t.Run("over the limit isn't created", func(t *testing.T) {
+		limiter := &mocks.MockItemLimiter{
+			CheckCreateFunc: func(ctx context.Context, userId uint, prices []money.Money) error {
+				return errors.New("limit exceeded")
+			},
+		}
+		repo := &mocks.MockItemRepository{
+			CreateFunc: func(ctx context.Context, newItem models.Item) (*models.Item, error) {
+				t.Error("created over the limit")
+				return &newItem, nil
+			},
+		}
+
+		item, err := NewItemService(repo, txManager, limiter, &mocks.MockModerator{}, money.DefaultRates()).Create(context.Background(), input, 1)
+
+		assert.EqualError(t, err, "limit exceeded")
+		assert.Nil(t, item)
+	})
+}
+
+func TestItemService_Moderation(t *testing.T) {
+	verdicts := map[string]moderation.Verdict{
+		"counterfeit bag": {Status: models.ModerationRejected, Reason: "banned"},
+		"lamp":            moderation.Approved(),
+	}
+	moderator := &mocks.MockModerator{
+		ModerateFunc: func(ctx context.Context, item models.Item) (moderation.Verdict, error) {
+			verdict, ok := verdicts[item.Name]
+			if !ok {
+				return moderation.Verdict{}, errors.New("moderator is down")
+			}
+			return verdict, nil
+		},
+	}
+	stored := models.Item{Name: "counterfeit bag", ModerationStatus: models.ModerationRejected, ModerationReason: "banned"}
+	repo := &mocks.MockItemRepository{
+		CreateFunc: func(ctx context.Context, newItem models.Item) (*models.Item, error) {
+			return &newItem, nil
+		},
+		FindByIdForUpdateFunc: func(ctx context.Context, itemId uint, userId uint) (*models.Item, error) {
+			item := stored
+			return &item, nil
+		},
+		UpdateFunc: func(ctx context.Context, updateItem models.Item) (*models.Item, error) {
+			return &updateItem, nil
+		},
+	}
+	s := NewItemService(repo, &mocks.MockTxManager{}, &mocks.MockItemLimiter{}, moderator, money.DefaultRates())
+	ctx := context.Background()
+
+	t.Run("created with the verdict", func(t *testing.T) {
+		item, err := s.Create(ctx, dto.CreateItemInput{Name: "counterfeit bag", Price: 100}, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, models.ModerationRejected, item.ModerationStatus)
+		assert.Equal(t, "banned", item.ModerationReason)
+	})
+
+	t.Run("failing moderator holds the item", func(t *testing.T) {
+		item, err := s.Create(ctx, dto.CreateItemInput{Name: "radio", Price: 100}, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, models.ModerationPending, item.ModerationStatus)
+	})
+
+	t.Run("editing a rejected item doesn't approve it", func(t *testing.T) {
+		name := "lamp"
+		item, err := s.Update(ctx, 1, dto.UpdateItemInput{Name: &name}, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, models.ModerationPending, item.ModerationStatus)
+		assert.Equal(t, "edited while rejected", item.ModerationReason)
+	})
+
+	t.Run("changing only the condition isn't moderated", func(t *testing.T) {
+		condition := "good"
+		item, err := s.Update(ctx, 1, dto.UpdateItemInput{Condition: &condition}, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, models.ModerationRejected, item.ModerationStatus)
+	})
+}
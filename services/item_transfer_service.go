@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"flea-market/dto"
+	"flea-market/internal/money"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	exportBatchSize = 100
+	maxImportRows   = 1000
+)
+
+// CSV column of each field of dto.CreateItemInput, to report validation errors
+var importColumnOfField = map[string]string{
+	"Name":        "name",
+	"Price":       "price",
+	"Currency":    "currency",
+	"Description": "description",
+	"CategoryID":  "category_id",
+	"Condition":   "condition",
+	"Tags":        "tags",
+}
+
+// Export calls fn with the user's items batch by batch.
+func (s *ItemService) Export(ctx context.Context, userId uint, fn func(items []models.Item) error) error {
+	return s.repository.FindByUserInBatches(ctx, userId, exportBatchSize, fn)
+}
+
+// ImportCSV validates every row against the rules of dto.CreateItemInput and creates the items only when all rows are valid.
+// A malformed file is an error, while invalid rows are reported in dto.ItemImportReport.
+func (s *ItemService) ImportCSV(ctx context.Context, r io.Reader, userId uint, dryRun bool) (*dto.ItemImportReport, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, utils.NewBadRequestError("can't read CSV header", err)
+	}
+	columns := map[string]int{}
+	for i, column := range header {
+		columns[strings.ToLower(strings.TrimSpace(column))] = i
+	}
+	for _, required := range []string{"name", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, utils.NewBadRequestError(fmt.Sprintf("column %q is required", required), errors.New("missing column"))
+		}
+	}
+
+	report := &dto.ItemImportReport{DryRun: dryRun, Errors: []dto.ItemImportError{}}
+	var items []models.Item
+	var lines []int
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, utils.NewBadRequestError("can't read CSV", err)
+		}
+		report.Rows++
+		if report.Rows > maxImportRows {
+			return nil, utils.NewBadRequestError(fmt.Sprintf("more than %d rows", maxImportRows), errors.New("too many rows"))
+		}
+
+		line, _ := reader.FieldPos(0)
+		input, rowErrors := parseImportRow(record, columns, line)
+		if len(rowErrors) > 0 {
+			report.Errors = append(report.Errors, rowErrors...)
+			continue
+		}
+		item := newItem(input, userId)
+		s.moderate(ctx, &item)
+		items = append(items, item)
+		lines = append(lines, line)
+	}
+
+	if len(report.Errors) > 0 || len(items) == 0 {
+		return report, nil
+	}
+	// checked on a dry run too, so that a dry run which passes can be imported
+	prices := make([]money.Money, len(items))
+	for i, item := range items {
+		prices[i] = item.Money()
+	}
+	var errs []error
+	// the limits are checked in the transaction of the creates, so that concurrent imports can't exceed them
+	err = s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.limiter.CheckCreate(ctx, userId, prices); err != nil {
+			return err
+		}
+		if dryRun {
+			return nil
+		}
+		// categories are checked by the foreign key, so some rows can still fail here
+		errs = s.repository.CreateBulk(ctx, items, true)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if dryRun {
+		return report, nil
+	}
+
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		// the other rows are only rolled back
+		if apiErr, ok := err.(*utils.APIError); ok && apiErr.MessageCode == utils.BulkEntryRolledBack {
+			continue
+		}
+		report.Errors = append(report.Errors, dto.ItemImportError{Row: lines[i], Reason: importErrorReason(err)})
+	}
+	if len(report.Errors) == 0 {
+		report.Imported = len(items)
+	}
+	return report, nil
+}
+
+func parseImportRow(record []string, columns map[string]int, line int) (dto.CreateItemInput, []dto.ItemImportError) {
+	cell := func(column string) string {
+		i, ok := columns[column]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var input dto.CreateItemInput
+	var rowErrors []dto.ItemImportError
+	input.Name = cell("name")
+	input.Description = cell("description")
+	input.Condition = cell("condition")
+	input.Currency = strings.ToUpper(cell("currency"))
+	if price := cell("price"); price != "" {
+		value, err := strconv.ParseUint(price, 10, 32)
+		if err != nil {
+			rowErrors = append(rowErrors, dto.ItemImportError{Row: line, Field: "price", Reason: "integer"})
+		}
+		input.Price = uint(value)
+		// the limits depend on the currency, so the binding rules don't check them
+		if err == nil && value > 0 && (input.Currency == "" || money.IsSupported(input.Currency)) {
+			err := newItem(input, 0).Money().Validate()
+			switch {
+			case errors.Is(err, money.ErrBelowMin):
+				rowErrors = append(rowErrors, dto.ItemImportError{Row: line, Field: "price", Reason: "min"})
+			case errors.Is(err, money.ErrAboveMax):
+				rowErrors = append(rowErrors, dto.ItemImportError{Row: line, Field: "price", Reason: "max"})
+			}
+		}
+	}
+	if categoryId := cell("category_id"); categoryId != "" {
+		value, err := strconv.ParseUint(categoryId, 10, 32)
+		if err != nil {
+			rowErrors = append(rowErrors, dto.ItemImportError{Row: line, Field: "category_id", Reason: "integer"})
+		}
+		id := uint(value)
+		input.CategoryID = &id
+	}
+	if tags := cell("tags"); tags != "" {
+		input.Tags = strings.Split(tags, dto.ItemCSVTagSeparator)
+	}
+
+	if err := binding.Validator.ValidateStruct(input); err != nil {
+		var validationErrors validator.ValidationErrors
+		if !errors.As(err, &validationErrors) {
+			return input, append(rowErrors, dto.ItemImportError{Row: line, Reason: err.Error()})
+		}
+		for _, fieldError := range validationErrors {
+			field := importColumnOfField[strings.Split(fieldError.StructField(), "[")[0]]
+			// already reported as not an integer
+			if slices.ContainsFunc(rowErrors, func(e dto.ItemImportError) bool { return e.Field == field }) {
+				continue
+			}
+			rowErrors = append(rowErrors, dto.ItemImportError{Row: line, Field: field, Reason: fieldError.Tag()})
+		}
+	}
+	return input, rowErrors
+}
+
+func importErrorReason(err error) string {
+	if apiErr, ok := err.(*utils.APIError); ok {
+		return string(apiErr.MessageCode)
+	}
+	return string(utils.UnknownError)
+}
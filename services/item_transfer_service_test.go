@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/internal/mocks"
+	"flea-market/internal/money"
+	"flea-market/models"
+	"flea-market/utils"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemService_ImportCSV(t *testing.T) {
+	t.Run("creates all rows", func(t *testing.T) {
+		var created []models.Item
+		repo := &mocks.MockItemRepository{
+			CreateBulkFunc: func(ctx context.Context, items []models.Item, atomic bool) []error {
+				assert.True(t, atomic)
+				created = items
+				return make([]error, len(items))
+			},
+		}
+		file := "name,price,tags,sold_out\nlamp,100,light|desk,false\n\"desk, oak\",2000,,true\n"
+		report, err := NewItemService(repo, &mocks.MockTxManager{}, &mocks.MockItemLimiter{}, &mocks.MockModerator{}, money.DefaultRates()).ImportCSV(context.Background(), strings.NewReader(file), 1, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &dto.ItemImportReport{Rows: 2, Imported: 2, Errors: []dto.ItemImportError{}}, report)
+		assert.Len(t, created, 2)
+		assert.Equal(t, uint(1), created[0].UserID)
+		assert.Equal(t, []models.Tag{{Name: "light"}, {Name: "desk"}}, created[0].Tags)
+		assert.Equal(t, "desk, oak", created[1].Name)
+	})
+
+	t.Run("reports every invalid field without creating", func(t *testing.T) {
+		repo := &mocks.MockItemRepository{
+			CreateBulkFunc: func(ctx context.Context, items []models.Item, atomic bool) []error {
+				t.Fatal("nothing must be created")
+				return nil
+			},
+		}
+		file := "name,price,category_id,condition\n" +
+			"lamp,100,,\n" +
+			"x,abc,,\n" +
+			"desk,1000000,0,broken\n"
+		report, err := NewItemService(repo, &mocks.MockTxManager{}, &mocks.MockItemLimiter{}, &mocks.MockModerator{}, money.DefaultRates()).ImportCSV(context.Background(), strings.NewReader(file), 1, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, report.Rows)
+		assert.Equal(t, 0, report.Imported)
+		assert.Equal(t, []dto.ItemImportError{
+			{Row: 3, Field: "price", Reason: "integer"},
+			{Row: 3, Field: "name", Reason: "min"},
+			{Row: 4, Field: "price", Reason: "max"},
+			{Row: 4, Field: "category_id", Reason: "min"},
+			{Row: 4, Field: "condition", Reason: "oneof"},
+		}, report.Errors)
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		repo := &mocks.MockItemRepository{}
+		report, err := NewItemService(repo, &mocks.MockTxManager{}, &mocks.MockItemLimiter{}, &mocks.MockModerator{}, money.DefaultRates()).ImportCSV(context.Background(), strings.NewReader("name,price\nlamp,100\n"), 1, true)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &dto.ItemImportReport{DryRun: true, Rows: 1, Errors: []dto.ItemImportError{}}, report)
+	})
+
+	t.Run("reports rows failed in DB", func(t *testing.T) {
+		repo := &mocks.MockItemRepository{
+			CreateBulkFunc: func(ctx context.Context, items []models.Item, atomic bool) []error {
+				return []error{
+					utils.NewBulkEntryRolledBackError("entry 1 failed", nil),
+					utils.NewBadRequestError("category 9 not found", nil),
+				}
+			},
+		}
+		file := "name,price,category_id\nlamp,100,\ndesk,200,9\n"
+		report, err := NewItemService(repo, &mocks.MockTxManager{}, &mocks.MockItemLimiter{}, &mocks.MockModerator{}, money.DefaultRates()).ImportCSV(context.Background(), strings.NewReader(file), 1, false)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, report.Imported)
+		assert.Equal(t, []dto.ItemImportError{{Row: 3, Reason: string(utils.BadRequest)}}, report.Errors)
+	})
+
+	t.Run("missing column", func(t *testing.T) {
+		_, err := NewItemService(&mocks.MockItemRepository{}, &mocks.MockTxManager{}, &mocks.MockItemLimiter{}, &mocks.MockModerator{}, money.DefaultRates()).ImportCSV(context.Background(), strings.NewReader("name\nlamp\n"), 1, false)
+
+		assert.Equal(t, http.StatusBadRequest, statusOf(err))
+	})
+}
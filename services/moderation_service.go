@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"flea-market/dto"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+)
+
+type IModerationRepository interface {
+	CreateReport(ctx context.Context, report models.ItemReport) (*models.ItemReport, error)
+	CountOpenReports(ctx context.Context, itemId uint) (int64, error)
+	FindItem(ctx context.Context, itemId uint) (*models.Item, error)
+	UpdateStatus(ctx context.Context, item models.Item) (*models.Item, error)
+	ResolveReports(ctx context.Context, itemId uint) error
+	FindQueue(ctx context.Context, limit int) (*[]models.ModerationQueueEntry, error)
+}
+
+const (
+	// an approved item with this many open reports is hidden until an admin reviews it
+	reportThreshold        = 3
+	defaultModerationQueue = 50
+)
+
+type ModerationService struct {
+	repository IModerationRepository
+	txManager  ITxManager
+}
+
+func NewModerationService(repository IModerationRepository, txManager ITxManager) *ModerationService {
+	return &ModerationService{repository: repository, txManager: txManager}
+}
+
+// Report flags another user's item. Only approved items can be reported, because the others aren't shown to other users.
+func (s *ModerationService) Report(ctx context.Context, itemId uint, reporterId uint, input dto.ReportItemInput) (*models.ItemReport, error) {
+	var report *models.ItemReport
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		item, err := s.repository.FindItem(ctx, itemId)
+		if err != nil {
+			return err
+		}
+		if item.ModerationStatus != models.ModerationApproved {
+			return utils.NewNotFoundError(fmt.Sprintf("item %d is %s", itemId, item.ModerationStatus), nil)
+		}
+		if item.UserID == reporterId {
+			return utils.NewBadRequestError("can't report your own item", errors.New("reporter is the seller"))
+		}
+
+		report, err = s.repository.CreateReport(ctx, models.ItemReport{
+			ItemID:     itemId,
+			ReporterID: reporterId,
+			Reason:     models.ReportReason(input.Reason),
+			Comment:    input.Comment,
+		})
+		if err != nil {
+			return err
+		}
+
+		count, err := s.repository.CountOpenReports(ctx, itemId)
+		if err != nil {
+			return err
+		}
+		if count < reportThreshold {
+			return nil
+		}
+		item.ModerationStatus = models.ModerationPending
+		item.ModerationReason = fmt.Sprintf("reported by %d users", count)
+		_, err = s.repository.UpdateStatus(ctx, *item)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+func (s *ModerationService) FindQueue(ctx context.Context, query dto.ModerationQueueQuery) (*[]models.ModerationQueueEntry, error) {
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultModerationQueue
+	}
+	return s.repository.FindQueue(ctx, limit)
+}
+
+// Decide approves or rejects an item for admins, and resolves its open reports.
+func (s *ModerationService) Decide(ctx context.Context, itemId uint, input dto.ModerationDecisionInput) (*models.Item, error) {
+	var decided *models.Item
+	err := s.txManager.WithinTx(ctx, func(ctx context.Context) error {
+		item, err := s.repository.FindItem(ctx, itemId)
+		if err != nil {
+			return err
+		}
+		item.ModerationStatus = models.ModerationStatus(input.Status)
+		item.ModerationReason = input.Reason
+		if item.ModerationStatus == models.ModerationApproved {
+			item.ModerationReason = ""
+		}
+		if decided, err = s.repository.UpdateStatus(ctx, *item); err != nil {
+			return err
+		}
+		return s.repository.ResolveReports(ctx, itemId)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return decided, nil
+}
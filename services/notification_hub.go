@@ -0,0 +1,97 @@
+package services
+
+import (
+	"flea-market/models"
+	"sync"
+)
+
+// buffer size of each subscriber channel.
+// When a subscriber can't keep up, its channel is closed and the client is expected to
+// reconnect with Last-Event-ID, so nothing is lost because notifications are persisted.
+const subscriberBufferSize = 16
+
+// NotificationHub is an in-process pub/sub which fans out notifications to every
+// connection(browser tab, device) of the same user.
+type NotificationHub struct {
+	mu          sync.Mutex
+	subscribers map[uint]map[chan models.Notification]struct{}
+	closed      bool
+}
+
+func NewNotificationHub() *NotificationHub {
+	return &NotificationHub{subscribers: map[uint]map[chan models.Notification]struct{}{}}
+}
+
+// Subscribe registers a new connection of the user.
+// The returned channel is closed when the hub is closed or the subscriber is too slow,
+// and unsubscribe must be called when the connection ends.
+func (h *NotificationHub) Subscribe(userId uint) (<-chan models.Notification, func()) {
+	ch := make(chan models.Notification, subscriberBufferSize)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if h.subscribers[userId] == nil {
+		h.subscribers[userId] = map[chan models.Notification]struct{}{}
+	}
+	h.subscribers[userId][ch] = struct{}{}
+
+	unsubscribe := func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.remove(userId, ch)
+	}
+	return ch, unsubscribe
+}
+
+// Publish never blocks, so a slow client can't stall the request that created the notification.
+func (h *NotificationHub) Publish(notification models.Notification) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for ch := range h.subscribers[notification.UserID] {
+		select {
+		case ch <- notification:
+		default:
+			h.remove(notification.UserID, ch)
+		}
+	}
+}
+
+// Close disconnects every subscriber. It is called when the server shuts down,
+// otherwise the streaming connections never become idle and the shutdown waits until timeout.
+func (h *NotificationHub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return
+	}
+	h.closed = true
+	for userId, chs := range h.subscribers {
+		for ch := range chs {
+			h.remove(userId, ch)
+		}
+	}
+}
+
+// remove must be called while holding mu.
+func (h *NotificationHub) remove(userId uint, ch chan models.Notification) {
+	chs, ok := h.subscribers[userId]
+	if !ok {
+		return
+	}
+	if _, ok := chs[ch]; !ok {
+		return
+	}
+	delete(chs, ch)
+	close(ch)
+	if len(chs) == 0 {
+		delete(h.subscribers, userId)
+	}
+}
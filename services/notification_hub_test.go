@@ -0,0 +1,72 @@
+package services
+
+import (
+	"flea-market/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationHub_FanOutPerUser(t *testing.T) {
+	hub := NewNotificationHub()
+
+	tab1, unsubscribe1 := hub.Subscribe(1)
+	defer unsubscribe1()
+	tab2, unsubscribe2 := hub.Subscribe(1)
+	defer unsubscribe2()
+	other, unsubscribe3 := hub.Subscribe(2)
+	defer unsubscribe3()
+
+	hub.Publish(models.Notification{UserID: 1, Type: models.NotificationItemSold})
+
+	assert.Equal(t, models.NotificationItemSold, (<-tab1).Type)
+	assert.Equal(t, models.NotificationItemSold, (<-tab2).Type)
+	assert.Len(t, other, 0)
+}
+
+func TestNotificationHub_Unsubscribe(t *testing.T) {
+	hub := NewNotificationHub()
+
+	ch, unsubscribe := hub.Subscribe(1)
+	unsubscribe()
+	// calling twice must not panic by closing the channel again
+	unsubscribe()
+
+	hub.Publish(models.Notification{UserID: 1})
+
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+func TestNotificationHub_SlowSubscriberIsDisconnected(t *testing.T) {
+	hub := NewNotificationHub()
+
+	ch, unsubscribe := hub.Subscribe(1)
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBufferSize+1; i++ {
+		hub.Publish(models.Notification{UserID: 1})
+	}
+
+	received := 0
+	for range ch {
+		received++
+	}
+	assert.Equal(t, subscriberBufferSize, received)
+}
+
+func TestNotificationHub_Close(t *testing.T) {
+	hub := NewNotificationHub()
+
+	ch, unsubscribe := hub.Subscribe(1)
+	defer unsubscribe()
+
+	hub.Close()
+	_, ok := <-ch
+	assert.False(t, ok)
+
+	// subscribing after close returns a closed channel
+	afterClose, _ := hub.Subscribe(1)
+	_, ok = <-afterClose
+	assert.False(t, ok)
+}
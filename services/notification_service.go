@@ -0,0 +1,72 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"flea-market/models"
+	"flea-market/utils"
+	"time"
+)
+
+// A client which has been away longer, or connects without Last-Event-ID, gets only the recent notifications,
+// so that a reconnect doesn't replay the whole history.
+const (
+	missedNotificationWindow = 24 * time.Hour
+	maxMissedNotifications   = 100
+)
+
+type INotificationRepository interface {
+	Create(ctx context.Context, notification models.Notification) (*models.Notification, error)
+	FindAfter(ctx context.Context, userId uint, lastId uint, since time.Time, limit int) (*[]models.Notification, error)
+}
+
+// INotifier is used by other services to notify users.
+type INotifier interface {
+	Notify(ctx context.Context, userId uint, notificationType models.NotificationType, payload any) error
+}
+
+// IWebhookDispatcher delivers notifications to the webhooks of the user.
+type IWebhookDispatcher interface {
+	Dispatch(ctx context.Context, notification models.Notification) error
+}
+
+type NotificationService struct {
+	repository INotificationRepository
+	hub        *NotificationHub
+	webhooks   IWebhookDispatcher
+}
+
+func NewNotificationService(repository INotificationRepository, hub *NotificationHub, webhooks IWebhookDispatcher) *NotificationService {
+	return &NotificationService{repository: repository, hub: hub, webhooks: webhooks}
+}
+
+// Notify persists the notification first and then publishes it,
+// so that a client which is offline now can receive it later by Last-Event-ID.
+// The webhooks of the user are called asynchronously.
+func (s *NotificationService) Notify(ctx context.Context, userId uint, notificationType models.NotificationType, payload any) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return utils.NewUnknownError("json.Marshal notification payload failed", err)
+	}
+
+	notification, err := s.repository.Create(ctx, models.Notification{
+		UserID:  userId,
+		Type:    notificationType,
+		Payload: string(body),
+	})
+	if err != nil {
+		return err
+	}
+
+	s.hub.Publish(*notification)
+	return s.webhooks.Dispatch(ctx, *notification)
+}
+
+func (s *NotificationService) Subscribe(userId uint) (<-chan models.Notification, func()) {
+	return s.hub.Subscribe(userId)
+}
+
+func (s *NotificationService) FindMissed(ctx context.Context, userId uint, lastEventId uint) (*[]models.Notification, error) {
+	since := time.Now().Add(-missedNotificationWindow)
+	return s.repository.FindAfter(ctx, userId, lastEventId, since, maxMissedNotifications)
+}
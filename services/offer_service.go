@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"flea-market/dto"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"time"
+)
+
+const (
+	// how long a pending offer can be answered
+	offerTTL = 48 * time.Hour
+	// how long an accepted offer reserves the item for the buyer
+	reservationWindow = 24 * time.Hour
+)
+
+type IOfferRepository interface {
+	Create(ctx context.Context, offer models.Offer) (*models.Offer, error)
+	FindById(ctx context.Context, offerId uint) (*models.Offer, error)
+	FindByItem(ctx context.Context, itemId uint, userId uint) (*[]models.Offer, error)
+	UpdateStatus(ctx context.Context, offer models.Offer, status models.OfferStatus) (*models.Offer, error)
+	Counter(ctx context.Context, offer models.Offer, counterOffer models.Offer) (*models.Offer, error)
+	Accept(ctx context.Context, offer models.Offer, reservedUntil time.Time) (*models.Offer, error)
+}
+
+// IItemFinder finds another user's item.
+type IItemFinder interface {
+	FindPublicById(ctx context.Context, itemId uint) (*models.Item, error)
+}
+
+type OfferService struct {
+	repository IOfferRepository
+	itemFinder IItemFinder
+	notifier   INotifier
+}
+
+func NewOfferService(repository IOfferRepository, itemFinder IItemFinder, notifier INotifier) *OfferService {
+	return &OfferService{repository: repository, itemFinder: itemFinder, notifier: notifier}
+}
+
+func (s *OfferService) Create(ctx context.Context, itemId uint, input dto.CreateOfferInput, buyerId uint) (*models.Offer, error) {
+	item, err := s.itemFinder.FindPublicById(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+	if item.UserID == buyerId {
+		return nil, utils.NewBadRequestError("can't make an offer on your own item", errors.New("buyer is the seller"))
+	}
+	if item.SoldOut {
+		return nil, utils.NewItemSoldOutError(fmt.Sprintf("item %d is sold out", item.ID), nil)
+	}
+
+	offer, err := s.repository.Create(ctx, models.Offer{
+		ItemID:     item.ID,
+		BuyerID:    buyerId,
+		SellerID:   item.UserID,
+		ProposerID: buyerId,
+		Price:      input.Price,
+		Status:     models.OfferPending,
+		ExpiresAt:  time.Now().Add(offerTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyOfferReceived(ctx, offer)
+	return offer, nil
+}
+
+func (s *OfferService) FindByItem(ctx context.Context, itemId uint, userId uint) (*[]models.Offer, error) {
+	return s.repository.FindByItem(ctx, itemId, userId)
+}
+
+func (s *OfferService) Accept(ctx context.Context, offerId uint, userId uint) (*models.Offer, error) {
+	offer, err := s.findForResponder(ctx, offerId, userId, models.OfferAccepted)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.Accept(ctx, *offer, time.Now().Add(reservationWindow))
+}
+
+func (s *OfferService) Decline(ctx context.Context, offerId uint, userId uint) (*models.Offer, error) {
+	offer, err := s.findForResponder(ctx, offerId, userId, models.OfferDeclined)
+	if err != nil {
+		return nil, err
+	}
+	return s.repository.UpdateStatus(ctx, *offer, models.OfferDeclined)
+}
+
+func (s *OfferService) Counter(ctx context.Context, offerId uint, input dto.CounterOfferInput, userId uint) (*models.Offer, error) {
+	offer, err := s.findForResponder(ctx, offerId, userId, models.OfferCountered)
+	if err != nil {
+		return nil, err
+	}
+
+	counterOffer, err := s.repository.Counter(ctx, *offer, models.Offer{
+		ItemID:     offer.ItemID,
+		BuyerID:    offer.BuyerID,
+		SellerID:   offer.SellerID,
+		ProposerID: userId,
+		Price:      input.Price,
+		Status:     models.OfferPending,
+		ParentID:   &offer.ID,
+		ExpiresAt:  time.Now().Add(offerTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyOfferReceived(ctx, counterOffer)
+	return counterOffer, nil
+}
+
+// Withdraw is done by the proposer, not the responder.
+func (s *OfferService) Withdraw(ctx context.Context, offerId uint, userId uint) (*models.Offer, error) {
+	offer, err := s.findForParticipant(ctx, offerId, userId)
+	if err != nil {
+		return nil, err
+	}
+	if offer.ProposerID != userId {
+		return nil, utils.NewForbiddenError(fmt.Sprintf("only the proposer can withdraw offer %d", offer.ID), nil)
+	}
+	if err := checkTransition(offer, models.OfferWithdrawn); err != nil {
+		return nil, err
+	}
+	return s.repository.UpdateStatus(ctx, *offer, models.OfferWithdrawn)
+}
+
+// Other users' offers are reported as not found, same as items.
+func (s *OfferService) findForParticipant(ctx context.Context, offerId uint, userId uint) (*models.Offer, error) {
+	offer, err := s.repository.FindById(ctx, offerId)
+	if err != nil {
+		return nil, err
+	}
+	if !offer.IsParticipant(userId) {
+		return nil, utils.NewNotFoundError(fmt.Sprintf("offer %d not found", offerId), nil)
+	}
+	return offer, nil
+}
+
+func (s *OfferService) findForResponder(ctx context.Context, offerId uint, userId uint, status models.OfferStatus) (*models.Offer, error) {
+	offer, err := s.findForParticipant(ctx, offerId, userId)
+	if err != nil {
+		return nil, err
+	}
+	if offer.ResponderID() != userId {
+		return nil, utils.NewForbiddenError(fmt.Sprintf("user %d can't respond to offer %d", userId, offer.ID), nil)
+	}
+	if err := checkTransition(offer, status); err != nil {
+		return nil, err
+	}
+	return offer, nil
+}
+
+// Offers past ExpiresAt are treated as expired even before the sweeper updates them.
+func checkTransition(offer *models.Offer, status models.OfferStatus) error {
+	if !offer.Status.CanTransitionTo(status) {
+		return utils.NewOfferInvalidTransitionError(fmt.Sprintf("offer %d: %s -> %s", offer.ID, offer.Status, status), nil)
+	}
+	if offer.Status == models.OfferPending && !offer.ExpiresAt.After(time.Now()) {
+		return utils.NewOfferInvalidTransitionError(fmt.Sprintf("offer %d is expired", offer.ID), nil)
+	}
+	return nil
+}
+
+func (s *OfferService) notifyOfferReceived(ctx context.Context, offer *models.Offer) {
+	payload := map[string]any{"offerId": offer.ID, "itemId": offer.ItemID, "price": offer.Price}
+	if err := s.notifier.Notify(ctx, offer.ResponderID(), models.NotificationOfferReceived, payload); err != nil {
+		utils.Logger(utils.NotificationFailed, "", "", "", err.Error())
+	}
+}
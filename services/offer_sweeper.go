@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"flea-market/utils"
+	"fmt"
+	"time"
+)
+
+type IOfferExpirer interface {
+	ExpirePending(ctx context.Context, now time.Time) (int64, error)
+}
+
+// OfferSweeper expires pending offers periodically in the background.
+type OfferSweeper struct {
+	repository IOfferExpirer
+	interval   time.Duration
+}
+
+func NewOfferSweeper(repository IOfferExpirer, interval time.Duration) *OfferSweeper {
+	return &OfferSweeper{repository: repository, interval: interval}
+}
+
+// Run blocks until ctx is canceled.
+func (s *OfferSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.sweep(ctx)
+		}
+	}
+}
+
+func (s *OfferSweeper) sweep(ctx context.Context) {
+	count, err := s.repository.ExpirePending(ctx, time.Now())
+	if err != nil {
+		if apiErr, ok := err.(*utils.APIError); ok {
+			utils.Logger(apiErr.MessageCode, "", "", "", err.Error())
+			return
+		}
+		utils.Logger(utils.UnknownError, "", "", "", err.Error())
+		return
+	}
+	if count > 0 {
+		utils.Logger(utils.GenericMessage, "", "", "", fmt.Sprintf("Expired %d offers", count))
+	}
+}
@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"flea-market/models"
+	"flea-market/utils"
+)
+
+type IPurchaseRepository interface {
+	Purchase(ctx context.Context, itemId uint, buyerId uint) (*models.Purchase, error)
+}
+
+type PurchaseService struct {
+	repository IPurchaseRepository
+	itemFinder IItemFinder
+	notifier   INotifier
+}
+
+func NewPurchaseService(repository IPurchaseRepository, itemFinder IItemFinder, notifier INotifier) *PurchaseService {
+	return &PurchaseService{repository: repository, itemFinder: itemFinder, notifier: notifier}
+}
+
+func (s *PurchaseService) Purchase(ctx context.Context, itemId uint, buyerId uint) (*models.Purchase, error) {
+	item, err := s.itemFinder.FindPublicById(ctx, itemId)
+	if err != nil {
+		return nil, err
+	}
+	if item.UserID == buyerId {
+		return nil, utils.NewBadRequestError("can't purchase your own item", errors.New("buyer is the seller"))
+	}
+
+	purchase, err := s.repository.Purchase(ctx, itemId, buyerId)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]any{"itemId": item.ID, "name": item.Name, "price": purchase.Price}
+	if err := s.notifier.Notify(ctx, purchase.SellerID, models.NotificationItemSold, payload); err != nil {
+		utils.Logger(utils.NotificationFailed, "", "", "", err.Error())
+	}
+	return purchase, nil
+}
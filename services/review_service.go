@@ -0,0 +1,81 @@
+package services
+
+import (
+	"context"
+	"flea-market/dto"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"time"
+)
+
+// a review can be edited only for this period after it's written
+const reviewEditWindow = 7 * 24 * time.Hour
+
+type IReviewRepository interface {
+	Create(ctx context.Context, review models.Review) (*models.Review, error)
+	Update(ctx context.Context, reviewId uint, input dto.UpdateReviewInput) (*models.Review, error)
+	FindById(ctx context.Context, reviewId uint) (*models.Review, error)
+	FindByReviewee(ctx context.Context, userId uint) (*[]models.Review, error)
+}
+
+type IPurchaseFinder interface {
+	FindById(ctx context.Context, purchaseId uint) (*models.Purchase, error)
+}
+
+type ReviewService struct {
+	repository     IReviewRepository
+	purchaseFinder IPurchaseFinder
+}
+
+func NewReviewService(repository IReviewRepository, purchaseFinder IPurchaseFinder) *ReviewService {
+	return &ReviewService{repository: repository, purchaseFinder: purchaseFinder}
+}
+
+// Create is allowed only for the buyer and the seller of the purchase, and the other side is reviewed.
+func (s *ReviewService) Create(ctx context.Context, purchaseId uint, input dto.CreateReviewInput, userId uint) (*models.Review, error) {
+	purchase, err := s.purchaseFinder.FindById(ctx, purchaseId)
+	if err != nil {
+		return nil, err
+	}
+
+	review := models.Review{
+		PurchaseID: purchase.ID,
+		ReviewerID: userId,
+		Rating:     input.Rating,
+		Comment:    input.Comment,
+	}
+	switch userId {
+	case purchase.BuyerID:
+		review.RevieweeID = purchase.SellerID
+		review.ReviewerRole = models.ReviewerBuyer
+	case purchase.SellerID:
+		review.RevieweeID = purchase.BuyerID
+		review.ReviewerRole = models.ReviewerSeller
+	default:
+		// same as items, other users' purchases are reported as not found
+		return nil, utils.NewNotFoundError(fmt.Sprintf("purchase %d not found", purchaseId), nil)
+	}
+
+	return s.repository.Create(ctx, review)
+}
+
+func (s *ReviewService) Update(ctx context.Context, reviewId uint, input dto.UpdateReviewInput, userId uint) (*models.Review, error) {
+	review, err := s.repository.FindById(ctx, reviewId)
+	if err != nil {
+		return nil, err
+	}
+	if review.ReviewerID != userId {
+		return nil, utils.NewNotFoundError(fmt.Sprintf("review %d not found", reviewId), nil)
+	}
+	if time.Since(review.CreatedAt) > reviewEditWindow {
+		return nil, utils.NewReviewNotEditableError(fmt.Sprintf("review %d was written at %v", review.ID, review.CreatedAt), nil)
+	}
+
+	// the reviewer and the creation time never change, but the rating may have been changed since it was read
+	return s.repository.Update(ctx, review.ID, input)
+}
+
+func (s *ReviewService) FindByReviewee(ctx context.Context, userId uint) (*[]models.Review, error) {
+	return s.repository.FindByReviewee(ctx, userId)
+}
@@ -0,0 +1,24 @@
+package services
+
+import (
+	"context"
+	"flea-market/models"
+)
+
+type IUserRepository interface {
+	FindById(ctx context.Context, userId uint) (*models.User, error)
+	FindByIdForUpdate(ctx context.Context, userId uint) (*models.User, error)
+	UpdatePlan(ctx context.Context, userId uint, plan models.Plan) error
+}
+
+type UserService struct {
+	repository IUserRepository
+}
+
+func NewUserService(repository IUserRepository) *UserService {
+	return &UserService{repository: repository}
+}
+
+func (s *UserService) FindById(ctx context.Context, userId uint) (*models.User, error) {
+	return s.repository.FindById(ctx, userId)
+}
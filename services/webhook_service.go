@@ -0,0 +1,225 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flea-market/dto"
+	"flea-market/internal/jobs"
+	"flea-market/models"
+	"flea-market/utils"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+)
+
+const (
+	maxWebhooksPerUser   = 10
+	maxWebhookDeliveries = 100
+
+	WebhookEventHeader     = "X-Webhook-Event"
+	WebhookIdHeader        = "X-Webhook-Id"
+	WebhookTimestampHeader = "X-Webhook-Timestamp"
+	WebhookSignatureHeader = "X-Webhook-Signature"
+)
+
+type IWebhookRepository interface {
+	Create(ctx context.Context, webhook models.Webhook) (*models.Webhook, error)
+	FindByUser(ctx context.Context, userId uint) (*[]models.Webhook, error)
+	FindById(ctx context.Context, webhookId uint, userId uint) (*models.Webhook, error)
+	Delete(ctx context.Context, webhookId uint, userId uint) error
+	CreateDelivery(ctx context.Context, delivery models.WebhookDelivery) (*models.WebhookDelivery, error)
+	FindDeliveries(ctx context.Context, webhookId uint, limit int) (*[]models.WebhookDelivery, error)
+}
+
+// WebhookDelivery is the payload of DeliverWebhookJob.
+type WebhookDelivery struct {
+	WebhookID uint                    `json:"webhookId"`
+	UserID    uint                    `json:"userId"`
+	EventID   string                  `json:"eventId"`
+	Event     models.NotificationType `json:"event"`
+	Data      json.RawMessage         `json:"data"`
+	CreatedAt time.Time               `json:"createdAt"`
+}
+
+// DeliverWebhookJob delivers an event to a webhook. A response other than 2xx is retried with the backoff of jobs.
+var DeliverWebhookJob = jobs.Type[WebhookDelivery]{Kind: "deliver_webhook", MaxAttempts: 8, Timeout: 30 * time.Second}
+
+type WebhookService struct {
+	repository IWebhookRepository
+	client     *resty.Client
+	queue      jobs.IEnqueuer
+}
+
+func NewWebhookService(repository IWebhookRepository, client *resty.Client, queue jobs.IEnqueuer) *WebhookService {
+	return &WebhookService{repository: repository, client: client, queue: queue}
+}
+
+func (s *WebhookService) Create(ctx context.Context, input dto.CreateWebhookInput, userId uint) (*models.Webhook, error) {
+	webhooks, err := s.repository.FindByUser(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if len(*webhooks) >= maxWebhooksPerUser {
+		return nil, utils.NewBadRequestError(fmt.Sprintf("a user can have up to %d webhooks", maxWebhooksPerUser), errors.New("too many webhooks"))
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, utils.NewUnknownError("generate webhook secret failed", err)
+	}
+	events := make([]models.NotificationType, 0, len(input.Events))
+	for _, event := range input.Events {
+		events = append(events, models.NotificationType(event))
+	}
+	return s.repository.Create(ctx, models.Webhook{
+		UserID: userId,
+		URL:    input.URL,
+		Events: events,
+		Secret: "whsec_" + hex.EncodeToString(secret),
+	})
+}
+
+func (s *WebhookService) FindByUser(ctx context.Context, userId uint) (*[]models.Webhook, error) {
+	return s.repository.FindByUser(ctx, userId)
+}
+
+func (s *WebhookService) Delete(ctx context.Context, webhookId uint, userId uint) error {
+	return s.repository.Delete(ctx, webhookId, userId)
+}
+
+func (s *WebhookService) FindDeliveries(ctx context.Context, webhookId uint, userId uint) (*[]models.WebhookDelivery, error) {
+	if _, err := s.repository.FindById(ctx, webhookId, userId); err != nil {
+		return nil, err
+	}
+	return s.repository.FindDeliveries(ctx, webhookId, maxWebhookDeliveries)
+}
+
+// Test delivers a ping event once and returns its log, whether the webhook responded 2xx or not.
+func (s *WebhookService) Test(ctx context.Context, webhookId uint, userId uint) (*models.WebhookDelivery, error) {
+	webhook, err := s.repository.FindById(ctx, webhookId, userId)
+	if err != nil {
+		return nil, err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, utils.NewUnknownError("generate webhook event id failed", err)
+	}
+	data, _ := json.Marshal(map[string]any{"webhookId": webhook.ID})
+	return s.deliver(ctx, *webhook, WebhookDelivery{
+		WebhookID: webhook.ID,
+		UserID:    userId,
+		EventID:   "evt_test_" + hex.EncodeToString(id),
+		Event:     models.WebhookTestEvent,
+		Data:      data,
+		CreatedAt: time.Now(),
+	}, 1)
+}
+
+// Dispatch queues the delivery of the notification to every webhook of the user subscribing to it.
+func (s *WebhookService) Dispatch(ctx context.Context, notification models.Notification) error {
+	webhooks, err := s.repository.FindByUser(ctx, notification.UserID)
+	if err != nil {
+		return err
+	}
+	for _, webhook := range *webhooks {
+		if !webhook.Subscribes(notification.Type) {
+			continue
+		}
+		_, _, err := jobs.Enqueue(ctx, s.queue, DeliverWebhookJob, WebhookDelivery{
+			WebhookID: webhook.ID,
+			UserID:    webhook.UserID,
+			EventID:   fmt.Sprintf("evt_%d", notification.ID),
+			Event:     notification.Type,
+			Data:      json.RawMessage(notification.Payload),
+			CreatedAt: notification.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Deliver is the handler of DeliverWebhookJob. It fails unless the webhook responds 2xx, so that the job is retried.
+func (s *WebhookService) Deliver(ctx context.Context, delivery WebhookDelivery) error {
+	webhook, err := s.repository.FindById(ctx, delivery.WebhookID, delivery.UserID)
+	if err != nil {
+		if apiErr, ok := err.(*utils.APIError); ok && apiErr.MessageCode == utils.NotFound {
+			// deleted after the event was queued
+			return nil
+		}
+		return err
+	}
+
+	log, err := s.deliver(ctx, *webhook, delivery, jobs.Attempt(ctx))
+	if err != nil {
+		return err
+	}
+	if !log.Succeeded() {
+		if log.Error != "" {
+			return errors.New(log.Error)
+		}
+		return fmt.Errorf("webhook %d responded %d", webhook.ID, log.StatusCode)
+	}
+	return nil
+}
+
+// deliver POSTs the event signed with the secret of webhook, and saves the result as a delivery log.
+// A failure of the request is recorded in the log, not returned.
+// The response body isn't read, so that a webhook can't be used to read other servers through the log.
+func (s *WebhookService) deliver(ctx context.Context, webhook models.Webhook, delivery WebhookDelivery, attempt int) (*models.WebhookDelivery, error) {
+	body, err := json.Marshal(dto.WebhookEvent{
+		ID:        delivery.EventID,
+		Event:     string(delivery.Event),
+		CreatedAt: delivery.CreatedAt,
+		Data:      delivery.Data,
+	})
+	if err != nil {
+		return nil, utils.NewUnknownError("marshal webhook event failed", err)
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	log := models.WebhookDelivery{
+		WebhookID: webhook.ID,
+		EventID:   delivery.EventID,
+		Event:     delivery.Event,
+		Attempt:   attempt,
+	}
+	start := time.Now()
+	resp, err := s.client.R().
+		SetContext(ctx).
+		SetHeader("Content-Type", "application/json").
+		SetHeader(WebhookEventHeader, string(delivery.Event)).
+		SetHeader(WebhookIdHeader, delivery.EventID).
+		SetHeader(WebhookTimestampHeader, timestamp).
+		SetHeader(WebhookSignatureHeader, SignWebhook(webhook.Secret, timestamp, body)).
+		SetBody(body).
+		SetDoNotParseResponse(true).
+		Post(webhook.URL)
+	log.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		log.Error = err.Error()
+	} else {
+		log.StatusCode = resp.StatusCode()
+		_ = resp.RawBody().Close()
+	}
+
+	// the result is saved even when the request is canceled
+	return s.repository.CreateDelivery(context.WithoutCancel(ctx), log)
+}
+
+// SignWebhook returns the X-Webhook-Signature of body: "sha256=" and the hex of HMAC-SHA256 of "{timestamp}.{body}".
+// Receivers should compute it with the secret, and reject old timestamps to prevent replays.
+func SignWebhook(secret string, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"flea-market/dto"
+	"flea-market/internal/mocks"
+	"flea-market/models"
+	"flea-market/utils"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestWebhookService(webhook *models.Webhook, deliveries *[]models.WebhookDelivery, queue *mocks.MockJobRepository) *WebhookService {
+	repo := &mocks.MockWebhookRepository{
+		FindByUserFunc: func(ctx context.Context, userId uint) (*[]models.Webhook, error) {
+			return &[]models.Webhook{*webhook}, nil
+		},
+		FindByIdFunc: func(ctx context.Context, webhookId uint, userId uint) (*models.Webhook, error) {
+			if webhookId != webhook.ID || userId != webhook.UserID {
+				return nil, utils.NewNotFoundError("webhook not found", nil)
+			}
+			return webhook, nil
+		},
+		CreateDeliveryFunc: func(ctx context.Context, delivery models.WebhookDelivery) (*models.WebhookDelivery, error) {
+			*deliveries = append(*deliveries, delivery)
+			return &delivery, nil
+		},
+	}
+	return NewWebhookService(repo, resty.New(), queue)
+}
+
+func TestWebhookService_Deliver(t *testing.T) {
+	payload := WebhookDelivery{
+		WebhookID: 1, UserID: 2, EventID: "evt_5", Event: models.NotificationItemSold,
+		Data: json.RawMessage(`{"itemId":3}`), CreatedAt: time.Now(),
+	}
+
+	t.Run("signed delivery", func(t *testing.T) {
+		var header http.Header
+		var body []byte
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header
+			body, _ = io.ReadAll(r.Body)
+			_, _ = w.Write([]byte("ok"))
+		}))
+		defer server.Close()
+		webhook := &models.Webhook{UserID: 2, URL: server.URL, Secret: "whsec_test"}
+		webhook.ID = 1
+		var deliveries []models.WebhookDelivery
+
+		err := newTestWebhookService(webhook, &deliveries, nil).Deliver(context.Background(), payload)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "item_sold", header.Get(WebhookEventHeader))
+		assert.Equal(t, "evt_5", header.Get(WebhookIdHeader))
+		timestamp := header.Get(WebhookTimestampHeader)
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		assert.NoError(t, err)
+		assert.WithinDuration(t, time.Now(), time.Unix(unix, 0), 5*time.Second)
+		assert.Equal(t, SignWebhook("whsec_test", timestamp, body), header.Get(WebhookSignatureHeader))
+
+		var event dto.WebhookEvent
+		assert.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, "evt_5", event.ID)
+		assert.JSONEq(t, `{"itemId":3}`, string(event.Data))
+
+		assert.Len(t, deliveries, 1)
+		assert.Equal(t, http.StatusOK, deliveries[0].StatusCode)
+		assert.True(t, deliveries[0].Succeeded())
+	})
+
+	t.Run("error status is retried", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+		webhook := &models.Webhook{UserID: 2, URL: server.URL, Secret: "whsec_test"}
+		webhook.ID = 1
+		var deliveries []models.WebhookDelivery
+
+		err := newTestWebhookService(webhook, &deliveries, nil).Deliver(context.Background(), payload)
+
+		assert.ErrorContains(t, err, "responded 500")
+		assert.Len(t, deliveries, 1)
+		assert.False(t, deliveries[0].Succeeded())
+	})
+
+	t.Run("deleted webhook is skipped", func(t *testing.T) {
+		webhook := &models.Webhook{UserID: 2}
+		webhook.ID = 9
+		var deliveries []models.WebhookDelivery
+
+		err := newTestWebhookService(webhook, &deliveries, nil).Deliver(context.Background(), payload)
+
+		assert.NoError(t, err)
+		assert.Empty(t, deliveries)
+	})
+}
+
+func TestWebhookService_Test(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	// a closed server refuses the connection
+	server.Close()
+	webhook := &models.Webhook{UserID: 2, URL: server.URL, Secret: "whsec_test"}
+	webhook.ID = 1
+	var deliveries []models.WebhookDelivery
+
+	delivery, err := newTestWebhookService(webhook, &deliveries, nil).Test(context.Background(), 1, 2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, models.WebhookTestEvent, delivery.Event)
+	assert.Equal(t, 1, delivery.Attempt)
+	assert.Equal(t, 0, delivery.StatusCode)
+	assert.NotEmpty(t, delivery.Error)
+}
+
+func TestWebhookService_Dispatch(t *testing.T) {
+	webhook := &models.Webhook{UserID: 2, Events: []models.NotificationType{models.NotificationItemSold}}
+	webhook.ID = 1
+	var enqueued []models.Job
+	queue := &mocks.MockJobRepository{
+		EnqueueFunc: func(ctx context.Context, job models.Job) (*models.Job, bool, error) {
+			enqueued = append(enqueued, job)
+			return &job, true, nil
+		},
+	}
+	service := newTestWebhookService(webhook, &[]models.WebhookDelivery{}, queue)
+
+	sold := models.Notification{UserID: 2, Type: models.NotificationItemSold, Payload: `{"itemId":3}`}
+	sold.ID = 5
+	assert.NoError(t, service.Dispatch(context.Background(), sold))
+	offered := models.Notification{UserID: 2, Type: models.NotificationOfferReceived, Payload: `{}`}
+	assert.NoError(t, service.Dispatch(context.Background(), offered))
+
+	assert.Len(t, enqueued, 1)
+	assert.Equal(t, DeliverWebhookJob.Kind, enqueued[0].Kind)
+	var payload WebhookDelivery
+	assert.NoError(t, json.Unmarshal([]byte(enqueued[0].Payload), &payload))
+	assert.Equal(t, uint(1), payload.WebhookID)
+	assert.Equal(t, "evt_5", payload.EventID)
+	assert.JSONEq(t, `{"itemId":3}`, string(payload.Data))
+}
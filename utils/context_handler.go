@@ -65,20 +65,18 @@ func GetFromGoContext(ctx context.Context, key Keys) (value string, exists bool)
 }
 
 // when getting data from context with "user" key, use this.
+// The user is set by AuthMiddleware, so it doesn't exist for the routes without authentication.
 func GetUserDataFromContext(ctx context.Context) (value *models.User, exists bool) {
-	value = ctx.Value(string(ContextUser)).(*models.User)
-	if value != nil {
-		exists = true
-	} else {
-		exists = false
-	}
+	value, _ = ctx.Value(ContextUser).(*models.User)
+	exists = value != nil
 	return
 }
 
+// The values are empty outside of a request, e.g. in job workers.
 func GetContextForLogger(ctx context.Context) (methodPath, reqID, clientIP string) {
-	methodPath = ctx.Value(ContextMethodPath).(string)
-	reqID = ctx.Value(ContextReqID).(string)
-	clientIP = ctx.Value(ContextIP).(string)
+	methodPath, _ = ctx.Value(ContextMethodPath).(string)
+	reqID, _ = ctx.Value(ContextReqID).(string)
+	clientIP, _ = ctx.Value(ContextIP).(string)
 
 	return methodPath, reqID, clientIP
 }
@@ -29,7 +29,7 @@ func NewBadRequestError(detail string, err error) *APIError {
 	return &APIError{
 		StatusCode:  http.StatusBadRequest,
 		MessageCode: BadRequest,
-		Message:     Messages[BadRequest],
+		Message:     LogMessage(BadRequest),
 		Detail:      detail,
 		Err:         err,
 	}
@@ -39,7 +39,7 @@ func NewNotFoundError(detail string, err error) *APIError {
 	return &APIError{
 		StatusCode:  http.StatusNotFound,
 		MessageCode: NotFound,
-		Message:     Messages[NotFound],
+		Message:     LogMessage(NotFound),
 		Detail:      detail,
 		Err:         err,
 	}
@@ -49,7 +49,17 @@ func NewUnauthorized(detail string, err error) *APIError {
 	return &APIError{
 		StatusCode:  http.StatusUnauthorized,
 		MessageCode: UnAuthorized,
-		Message:     Messages[UnAuthorized],
+		Message:     LogMessage(UnAuthorized),
+		Detail:      detail,
+		Err:         err,
+	}
+}
+
+func NewForbiddenError(detail string, err error) *APIError {
+	return &APIError{
+		StatusCode:  http.StatusForbidden,
+		MessageCode: Forbidden,
+		Message:     LogMessage(Forbidden),
 		Detail:      detail,
 		Err:         err,
 	}
@@ -59,7 +69,7 @@ func NewDBError(detail string, err error) *APIError {
 	return &APIError{
 		StatusCode:  http.StatusInternalServerError,
 		MessageCode: DBError,
-		Message:     Messages[DBError],
+		Message:     LogMessage(DBError),
 		Detail:      detail,
 		Err:         err,
 	}
@@ -69,7 +79,7 @@ func NewExternalAPIConnectionError(detail string, err error) *APIError {
 	return &APIError{
 		StatusCode:  http.StatusInternalServerError,
 		MessageCode: ExternalAPIConnectionError,
-		Message:     Messages[ExternalAPIConnectionError],
+		Message:     LogMessage(ExternalAPIConnectionError),
 		Detail:      detail,
 		Err:         err,
 	}
@@ -79,7 +89,127 @@ func NewDuplicateKeyError(detail string, err error) *APIError {
 	return &APIError{
 		StatusCode:  http.StatusConflict,
 		MessageCode: DuplicateKeyError,
-		Message:     Messages[DuplicateKeyError],
+		Message:     LogMessage(DuplicateKeyError),
+		Detail:      detail,
+		Err:         err,
+	}
+}
+
+func NewOfferInvalidTransitionError(detail string, err error) *APIError {
+	return &APIError{
+		StatusCode:  http.StatusConflict,
+		MessageCode: OfferInvalidTransition,
+		Message:     LogMessage(OfferInvalidTransition),
+		Detail:      detail,
+		Err:         err,
+	}
+}
+
+func NewItemReservedError(detail string, err error) *APIError {
+	return &APIError{
+		StatusCode:  http.StatusConflict,
+		MessageCode: ItemReserved,
+		Message:     LogMessage(ItemReserved),
+		Detail:      detail,
+		Err:         err,
+	}
+}
+
+func NewItemSoldOutError(detail string, err error) *APIError {
+	return &APIError{
+		StatusCode:  http.StatusConflict,
+		MessageCode: ItemSoldOut,
+		Message:     LogMessage(ItemSoldOut),
+		Detail:      detail,
+		Err:         err,
+	}
+}
+
+func NewReviewNotEditableError(detail string, err error) *APIError {
+	return &APIError{
+		StatusCode:  http.StatusForbidden,
+		MessageCode: ReviewNotEditable,
+		Message:     LogMessage(ReviewNotEditable),
+		Detail:      detail,
+		Err:         err,
+	}
+}
+
+func NewBulkEntryRolledBackError(detail string, err error) *APIError {
+	return &APIError{
+		StatusCode:  http.StatusFailedDependency,
+		MessageCode: BulkEntryRolledBack,
+		Message:     LogMessage(BulkEntryRolledBack),
+		Detail:      detail,
+		Err:         err,
+	}
+}
+
+func NewIdempotencyKeyReusedError(detail string, err error) *APIError {
+	return &APIError{
+		StatusCode:  http.StatusConflict,
+		MessageCode: IdempotencyKeyReused,
+		Message:     LogMessage(IdempotencyKeyReused),
+		Detail:      detail,
+		Err:         err,
+	}
+}
+
+func NewIdempotencyKeyInFlightError(detail string, err error) *APIError {
+	return &APIError{
+		StatusCode:  http.StatusTooEarly,
+		MessageCode: IdempotencyKeyInFlight,
+		Message:     LogMessage(IdempotencyKeyInFlight),
+		Detail:      detail,
+		Err:         err,
+	}
+}
+
+func NewItemLimitExceededError(detail string, err error) *APIError {
+	return &APIError{
+		StatusCode:  http.StatusForbidden,
+		MessageCode: ItemLimitExceeded,
+		Message:     LogMessage(ItemLimitExceeded),
+		Detail:      detail,
+		Err:         err,
+	}
+}
+
+func NewItemCreateRateLimitedError(detail string, err error) *APIError {
+	return &APIError{
+		StatusCode:  http.StatusTooManyRequests,
+		MessageCode: ItemCreateRateLimited,
+		Message:     LogMessage(ItemCreateRateLimited),
+		Detail:      detail,
+		Err:         err,
+	}
+}
+
+func NewPasswordLengthInvalidError(detail string, err error) *APIError {
+	return &APIError{
+		StatusCode:  http.StatusBadRequest,
+		MessageCode: PasswordLengthInvalid,
+		Message:     LogMessage(PasswordLengthInvalid),
+		Detail:      detail,
+		Err:         err,
+	}
+}
+
+func NewPasswordBreachedError(detail string, err error) *APIError {
+	return &APIError{
+		StatusCode:  http.StatusBadRequest,
+		MessageCode: PasswordBreached,
+		Message:     LogMessage(PasswordBreached),
+		Detail:      detail,
+		Err:         err,
+	}
+}
+
+func NewPasswordSameAsEmailError(detail string, err error) *APIError {
+	return &APIError{
+		StatusCode:  http.StatusBadRequest,
+		MessageCode: PasswordSameAsEmail,
+		Message:     LogMessage(PasswordSameAsEmail),
 		Detail:      detail,
 		Err:         err,
 	}
@@ -89,7 +219,7 @@ func NewExternalAPIReturnsError(detail string, err error) *APIError {
 	return &APIError{
 		StatusCode:  http.StatusInternalServerError,
 		MessageCode: ExternalAPIReturnsError,
-		Message:     Messages[ExternalAPIReturnsError],
+		Message:     LogMessage(ExternalAPIReturnsError),
 		Detail:      detail,
 		Err:         err,
 	}
@@ -99,7 +229,7 @@ func NewUnknownError(detail string, err error) *APIError {
 	return &APIError{
 		StatusCode:  http.StatusInternalServerError,
 		MessageCode: UnknownError,
-		Message:     Messages[UnknownError],
+		Message:     LogMessage(UnknownError),
 		Detail:      detail,
 		Err:         err,
 	}
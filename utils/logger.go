@@ -29,7 +29,7 @@ func Logger(messageId MessageCode, methodPath, reqID, clientIP string, msg ...an
 		logLevel = "INFO"
 	}
 
-	message := fmt.Sprintf(Messages[messageId], msg...)
+	message := fmt.Sprintf(LogMessage(messageId), msg...)
 
 	// At "error_handler.go", error.Error() is called, so except for panic, basically string will be passed.
 	for _, v := range msg {
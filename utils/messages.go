@@ -1,5 +1,7 @@
 package utils
 
+import "flea-market/internal/i18n"
+
 type MessageCode string
 
 const (
@@ -7,41 +9,70 @@ const (
 	RequestEnd              MessageCode = "I001-00002"
 	ExternalAPIRequestStart MessageCode = "I001-00003"
 	ExternalAPIRequestEnd   MessageCode = "I001-00004"
+	NotificationStreamStart MessageCode = "I001-00005"
+	NotificationStreamEnd   MessageCode = "I001-00006"
+	IdempotentReplay        MessageCode = "I001-00007"
+	OutboxEventPublished    MessageCode = "I001-00008"
+	DBReplicaRecovered      MessageCode = "I001-00009"
 
 	BadRequest     MessageCode = "I001-00010"
 	NotFound       MessageCode = "I001-00011"
 	UnAuthorized   MessageCode = "I001-00012"
+	Forbidden      MessageCode = "I001-00013"
 	GenericMessage MessageCode = "I001-00020"
 
 	DuplicateKeyError       MessageCode = "W001-00001"
 	ExternalAPIReturnsError MessageCode = "W001-00010"
+	NotificationFailed      MessageCode = "W001-00020"
+	OfferInvalidTransition  MessageCode = "W001-00030"
+	ItemReserved            MessageCode = "W001-00031"
+	ItemSoldOut             MessageCode = "W001-00032"
+	ReviewNotEditable       MessageCode = "W001-00040"
+	DeprecatedRouteCalled   MessageCode = "W001-00050"
+	BulkEntryRolledBack     MessageCode = "W001-00060"
+	ItemExportAborted       MessageCode = "W001-00070"
+	IdempotencyKeyReused    MessageCode = "W001-00080"
+	IdempotencyKeyInFlight  MessageCode = "W001-00081"
+	JobFailed               MessageCode = "W001-00090"
+	OutboxPublishFailed     MessageCode = "W001-00100"
+	DBReplicaUnavailable    MessageCode = "W001-00110"
+	DBConnectRetrying       MessageCode = "W001-00120"
+	ItemLimitExceeded       MessageCode = "W001-00130"
+	ItemCreateRateLimited   MessageCode = "W001-00131"
+	ModerationFailed        MessageCode = "W001-00140"
+	PasswordLengthInvalid   MessageCode = "W001-00150"
+	PasswordBreached        MessageCode = "W001-00151"
+	PasswordSameAsEmail     MessageCode = "W001-00152"
+	PasswordRehashFailed    MessageCode = "W001-00153"
+	JWTSecretKeyFallback    MessageCode = "W001-00160"
 
 	DBError                    MessageCode = "E001-00001"
 	ExternalAPIConnectionError MessageCode = "E001-00002"
+	JobDead                    MessageCode = "E001-00003"
 
 	UnknownError MessageCode = "E001-00010"
 
 	PanicThrownError MessageCode = "E001-00099"
 )
 
-var Messages = map[MessageCode]string{
-	RequestStart:            "リクエスト開始",
-	RequestEnd:              "リクエスト終了 status code:%v",
-	ExternalAPIRequestStart: "外部APIリクエスト開始  Request:%v",
-	ExternalAPIRequestEnd:   "外部APIリクエスト終了 %v",
-
-	BadRequest:   "Bad request",
-	NotFound:     "Not Found",
-	UnAuthorized: "UnAuthorized",
-
-	GenericMessage: "%v",
+// The messages of the codes are in the catalogs of internal/i18n.
+// Logs are written in the locale of operators, which is set by SetLogLocale, and error responses in the locale of each client.
+var logLocale = i18n.Ja
 
-	DuplicateKeyError:       "Duplicate key",
-	ExternalAPIReturnsError: "External API returns an error:%v",
+func SetLogLocale(locale i18n.Locale) {
+	logLocale = locale
+}
 
-	DBError:                    "DB error",
-	ExternalAPIConnectionError: "Connection failed Error:%v",
+// LogMessage returns the log template of code in the locale of operators.
+func LogMessage(code MessageCode) string {
+	return i18n.LogMessage(logLocale, string(code))
+}
 
-	UnknownError:     "UnknownError Error Detail:%v",
-	PanicThrownError: "Panic happened:%v",
+// APIMessage returns the message of code for clients. The codes which are only logged get the message of UnknownError.
+func APIMessage(locale i18n.Locale, code MessageCode) string {
+	if message, ok := i18n.APIMessage(locale, string(code)); ok {
+		return message
+	}
+	message, _ := i18n.APIMessage(locale, string(UnknownError))
+	return message
 }